@@ -13,6 +13,10 @@
 // a fetch function in a single batched RPC. Independent calls to Func.Invoke
 // get automatically combined into a single call to the user-supplied Func.Many,
 // resulting in only a single RPC with minimal changes to resolver code.
+//
+// A Func with Cache set also memoizes its results for the lifetime of the
+// batching context, so that the same arg loaded from different parts of a
+// query only results in a single call to Func.Many.
 package batch
 
 import (
@@ -55,6 +59,15 @@ type Func struct {
 	// MaxDuration, Many will be invoked even if some goroutines are still
 	// running. Defaults to DefaultMaxDuration.
 	MaxDuration time.Duration
+	// Cache optionally memoizes results for the lifetime of the batching
+	// context, like a DataLoader: once an arg has been loaded, invocations with
+	// an equal arg return the same result without calling Many again, and
+	// concurrent invocations with an equal arg share a single slot in the
+	// batch. Errors are not memoized. Use Prime and Clear to manage the cache.
+	Cache bool
+	// CacheKey optionally maps args to the keys used by Cache, for args that
+	// are not comparable. Defaults to the arg itself.
+	CacheKey func(arg interface{}) (key interface{})
 }
 
 // A batchGroup prepares and tracks a single batched invocation of a Func.
@@ -79,10 +92,30 @@ type funcShard struct {
 	shard interface{}
 }
 
+// A cacheEntry is the memoized result of a Func for an arg.
+type cacheEntry struct {
+	// doneCh is a 0-sized channel that is closed once result and err are set.
+	doneCh chan struct{}
+	result interface{}
+	err    error
+}
+
 // batchContext tracks context-specific batching information.
 type batchContext struct {
 	mu                 sync.Mutex
 	pendingBatchGroups map[funcShard]*batchGroup
+	// caches holds the memoized results of Funcs with Cache set, by cache key.
+	caches map[*Func]map[interface{}]*cacheEntry
+}
+
+// cache returns the memoized results of f. bctx.mu must be held.
+func (bctx *batchContext) cache(f *Func) map[interface{}]*cacheEntry {
+	cache, ok := bctx.caches[f]
+	if !ok {
+		cache = make(map[interface{}]*cacheEntry)
+		bctx.caches[f] = cache
+	}
+	return cache
 }
 
 // batchContextKey is a context.Value key used for type *batchContext.
@@ -96,6 +129,7 @@ func WithBatching(ctx context.Context) context.Context {
 
 	bctx := &batchContext{
 		pendingBatchGroups: make(map[funcShard]*batchGroup),
+		caches:             make(map[*Func]map[interface{}]*cacheEntry),
 	}
 	return context.WithValue(ctx, batchContextKey{}, bctx)
 }
@@ -124,14 +158,113 @@ func safeInvoke(
 	return f(ctx, args)
 }
 
-// Invoke arranges for the Func's Many to be called with arg as one of its
-// arguments, and returns the corresponding result.
-func (f *Func) Invoke(ctx context.Context, arg interface{}) (interface{}, error) {
+// batchContextFrom returns the batchContext of ctx.
+func batchContextFrom(ctx context.Context) *batchContext {
 	bctx, ok := ctx.Value(batchContextKey{}).(*batchContext)
 	if !ok {
 		panic("WithBatching must be called on the context before using Func")
 	}
+	return bctx
+}
+
+func (f *Func) cacheKey(arg interface{}) interface{} {
+	if f.CacheKey != nil {
+		return f.CacheKey(arg)
+	}
+	return arg
+}
+
+// Invoke arranges for the Func's Many to be called with arg as one of its
+// arguments, and returns the corresponding result.
+func (f *Func) Invoke(ctx context.Context, arg interface{}) (interface{}, error) {
+	bctx := batchContextFrom(ctx)
+	if !f.Cache {
+		return f.invoke(ctx, bctx, arg)
+	}
+
+	// Look up the memoized result for arg, or claim it by publishing a new
+	// entry.
+	key := f.cacheKey(arg)
+	bctx.mu.Lock()
+	cache := bctx.cache(f)
+	entry, existed := cache[key]
+	if !existed {
+		entry = &cacheEntry{
+			doneCh: make(chan struct{}, 0),
+		}
+		cache[key] = entry
+	}
+	bctx.mu.Unlock()
+
+	if existed {
+		concurrencylimiter.TemporarilyRelease(ctx, func() {
+			// Wait for the result.
+			<-entry.doneCh
+		})
+		return entry.result, entry.err
+	}
+
+	entry.result, entry.err = f.invoke(ctx, bctx, arg)
+	if entry.err != nil {
+		// Forget the error so that the next invocation tries again, unless
+		// the entry has been cleared already.
+		bctx.mu.Lock()
+		if cache[key] == entry {
+			delete(cache, key)
+		}
+		bctx.mu.Unlock()
+	}
+	// Make the result available.
+	close(entry.doneCh)
+	return entry.result, entry.err
+}
+
+// Prime memoizes result as the result for arg in the batching context of ctx,
+// unless arg has been loaded already. Prime does nothing if Cache is not set.
+func (f *Func) Prime(ctx context.Context, arg interface{}, result interface{}) {
+	bctx := batchContextFrom(ctx)
+	if !f.Cache {
+		return
+	}
+
+	key := f.cacheKey(arg)
+	bctx.mu.Lock()
+	defer bctx.mu.Unlock()
+	cache := bctx.cache(f)
+	if _, ok := cache[key]; ok {
+		return
+	}
+	entry := &cacheEntry{
+		doneCh: make(chan struct{}, 0),
+		result: result,
+	}
+	close(entry.doneCh)
+	cache[key] = entry
+}
+
+// Clear forgets the memoized result for arg in the batching context of ctx,
+// so that the next invocation with arg calls Many again.
+func (f *Func) Clear(ctx context.Context, arg interface{}) {
+	bctx := batchContextFrom(ctx)
+
+	key := f.cacheKey(arg)
+	bctx.mu.Lock()
+	defer bctx.mu.Unlock()
+	delete(bctx.cache(f), key)
+}
+
+// ClearAll forgets all memoized results in the batching context of ctx.
+func (f *Func) ClearAll(ctx context.Context) {
+	bctx := batchContextFrom(ctx)
+
+	bctx.mu.Lock()
+	defer bctx.mu.Unlock()
+	delete(bctx.caches, f)
+}
 
+// invoke adds arg to the pending batch of the Func, and returns the
+// corresponding result.
+func (f *Func) invoke(ctx context.Context, bctx *batchContext, arg interface{}) (interface{}, error) {
 	// Determine the current Func shard.
 	var shard interface{}
 	if f.Shard != nil {
@@ -315,3 +315,145 @@ func TestNoWithBatching(t *testing.T) {
 
 	assert.PanicsWithValue(t, "WithBatching must be called on the context before using Func", f)
 }
+
+// TestCache tests that a batch.Func with Cache memoizes results across
+// batches, and deduplicates args within a batch.
+func TestCache(t *testing.T) {
+	var mu sync.Mutex
+	var batches [][]interface{}
+	f := &batch.Func{
+		Many: func(ctx context.Context, args []interface{}) ([]interface{}, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			batches = append(batches, args)
+			return args, nil
+		},
+		Cache: true,
+	}
+
+	ctx := batch.WithBatching(context.Background())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if result, err := f.Invoke(ctx, i%2); err != nil || result != i%2 {
+				t.Error(err, i)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// A later batch only loads the new arg.
+	for _, arg := range []int{0, 1, 2} {
+		if result, err := f.Invoke(ctx, arg); err != nil || result != arg {
+			t.Error(err, arg)
+		}
+	}
+
+	var loaded []interface{}
+	for _, args := range batches {
+		loaded = append(loaded, args...)
+	}
+	assert.ElementsMatch(t, []interface{}{0, 1, 2}, loaded)
+
+	// A new batching context has a new cache.
+	before := len(batches)
+	if result, err := f.Invoke(batch.WithBatching(context.Background()), 0); err != nil || result != 0 {
+		t.Error(err)
+	}
+	assert.Len(t, batches, before+1)
+}
+
+// TestCachePrimeAndClear tests managing the cache of a batch.Func.
+func TestCachePrimeAndClear(t *testing.T) {
+	calls := 0
+	f := &batch.Func{
+		Many: func(ctx context.Context, args []interface{}) ([]interface{}, error) {
+			calls++
+			results := make([]interface{}, len(args))
+			for i, arg := range args {
+				results[i] = arg.(string) + "!"
+			}
+			return results, nil
+		},
+		Cache: true,
+	}
+
+	ctx := batch.WithBatching(context.Background())
+
+	f.Prime(ctx, "a", "primed")
+	result, err := f.Invoke(ctx, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, "primed", result)
+	assert.Equal(t, 0, calls)
+
+	// Priming does not replace a loaded result.
+	f.Prime(ctx, "a", "primed again")
+	result, _ = f.Invoke(ctx, "a")
+	assert.Equal(t, "primed", result)
+
+	f.Clear(ctx, "a")
+	result, _ = f.Invoke(ctx, "a")
+	assert.Equal(t, "a!", result)
+	assert.Equal(t, 1, calls)
+
+	f.Invoke(ctx, "b")
+	f.ClearAll(ctx)
+	f.Invoke(ctx, "a")
+	f.Invoke(ctx, "b")
+	assert.Equal(t, 4, calls)
+}
+
+// TestCacheError tests that errors are not memoized.
+func TestCacheError(t *testing.T) {
+	calls := 0
+	f := &batch.Func{
+		Many: func(ctx context.Context, args []interface{}) ([]interface{}, error) {
+			calls++
+			if calls == 1 {
+				return nil, errors.New("unavailable")
+			}
+			return args, nil
+		},
+		Cache: true,
+	}
+
+	ctx := batch.WithBatching(context.Background())
+
+	_, err := f.Invoke(ctx, 1)
+	assert.EqualError(t, err, "unavailable")
+	result, err := f.Invoke(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result)
+	assert.Equal(t, 2, calls)
+}
+
+// TestCacheKey tests memoizing args that are not comparable.
+func TestCacheKey(t *testing.T) {
+	calls := 0
+	f := &batch.Func{
+		Many: func(ctx context.Context, args []interface{}) ([]interface{}, error) {
+			calls++
+			results := make([]interface{}, len(args))
+			for i, arg := range args {
+				results[i] = strings.Join(arg.([]string), ",")
+			}
+			return results, nil
+		},
+		Cache: true,
+		CacheKey: func(arg interface{}) interface{} {
+			return strings.Join(arg.([]string), ",")
+		},
+	}
+
+	ctx := batch.WithBatching(context.Background())
+
+	for i := 0; i < 2; i++ {
+		result, err := f.Invoke(ctx, []string{"a", "b"})
+		assert.NoError(t, err)
+		assert.Equal(t, "a,b", result)
+	}
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,179 @@
+// Command thunder provides tools for working with Thunder GraphQL schemas.
+//
+// Usage:
+//
+//	thunder schema print FILE
+//	thunder schema diff [-fail-on=breaking|dangerous|never] OLD NEW
+//	thunder client generate -schema FILE -package NAME [-o FILE] OPERATIONS...
+//
+// Schema files are introspection query results if they end in .json, such as
+// the output of introspection.ComputeSchemaJSON, and SDL otherwise.
+//
+// "schema diff" prints the changes from OLD to NEW, each classified as
+// breaking, dangerous or safe, and exits with status 1 if any change is at
+// least as critical as -fail-on, so it can gate deploys in CI.
+//
+// "client generate" writes a typed Go client for the operations in the
+// OPERATIONS .graphql files to -o, or to standard output. See package
+// clientgen for the generated code.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/samsarahq/thunder/graphql"
+	"github.com/samsarahq/thunder/graphql/clientgen"
+	"github.com/samsarahq/thunder/graphql/schemadiff"
+	"github.com/samsarahq/thunder/graphql/sdl"
+)
+
+const usage = `usage:
+  thunder schema print FILE
+  thunder schema diff [-fail-on=breaking|dangerous|never] OLD NEW
+  thunder client generate -schema FILE -package NAME [-o FILE] OPERATIONS...
+`
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	if len(args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	}
+
+	var err error
+	var status int
+	switch args[0] + " " + args[1] {
+	case "schema print":
+		err = printSchema(args[2:])
+	case "schema diff":
+		status, err = diffSchemas(args[2:])
+	case "client generate":
+		err = generateClient(args[2:])
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "thunder: %s\n", err)
+		return 2
+	}
+	return status
+}
+
+func printSchema(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("schema print expects a single file")
+	}
+	schema, err := loadSchema(args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Print(sdl.Print(schema))
+	return nil
+}
+
+func diffSchemas(args []string) (int, error) {
+	flags := flag.NewFlagSet("schema diff", flag.ContinueOnError)
+	failOn := flags.String("fail-on", "breaking", "exit with status 1 if there are changes of this criticality or higher: breaking, dangerous or never")
+	if err := flags.Parse(args); err != nil {
+		return 0, err
+	}
+	if flags.NArg() != 2 {
+		return 0, fmt.Errorf("schema diff expects an old and a new file")
+	}
+
+	threshold := schemadiff.Breaking
+	switch *failOn {
+	case "breaking":
+	case "dangerous":
+		threshold = schemadiff.Dangerous
+	case "never":
+		threshold = schemadiff.Breaking + 1
+	default:
+		return 0, fmt.Errorf("unknown -fail-on %q", *failOn)
+	}
+
+	oldSchema, err := loadSchema(flags.Arg(0))
+	if err != nil {
+		return 0, err
+	}
+	newSchema, err := loadSchema(flags.Arg(1))
+	if err != nil {
+		return 0, err
+	}
+
+	changes := schemadiff.Diff(oldSchema, newSchema)
+	for _, change := range changes {
+		fmt.Println(change)
+	}
+	if len(changes) > 0 && schemadiff.MaxCriticality(changes) >= threshold {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func generateClient(args []string) error {
+	flags := flag.NewFlagSet("client generate", flag.ContinueOnError)
+	schemaPath := flags.String("schema", "", "the schema, as SDL or an introspection query result")
+	packageName := flags.String("package", "", "the package of the generated code")
+	output := flags.String("o", "", "the file to write the generated code to, instead of standard output")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *schemaPath == "" || *packageName == "" {
+		return fmt.Errorf("client generate expects -schema and -package")
+	}
+	if flags.NArg() == 0 {
+		return fmt.Errorf("client generate expects operation files")
+	}
+
+	schema, err := loadSchema(*schemaPath)
+	if err != nil {
+		return err
+	}
+	var sources []clientgen.Source
+	for _, path := range flags.Args() {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		sources = append(sources, clientgen.Source{Name: path, Body: string(data)})
+	}
+
+	code, err := clientgen.Generate(schema, *packageName, sources...)
+	if err != nil {
+		return err
+	}
+	if *output == "" {
+		_, err = os.Stdout.Write(code)
+		return err
+	}
+	return ioutil.WriteFile(*output, code, 0644)
+}
+
+// loadSchema reads an introspection query result if path ends in .json, and
+// SDL otherwise.
+func loadSchema(path string) (*graphql.Schema, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var schema *graphql.Schema
+	if filepath.Ext(path) == ".json" {
+		schema, err = sdl.ParseIntrospection(data)
+	} else {
+		schema, err = sdl.Parse(string(data))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+	return schema, nil
+}
@@ -47,7 +47,26 @@ type ExecutorClient interface {
 // The planner allows it to coordinate the subqueries being sent to the federated servers
 type Executor struct {
 	Executors map[string]ExecutorClient
-	syncer    *Syncer
+	// PartialResults makes Execute return partial results when a subquery
+	// fails, instead of failing the whole query. The fields the subquery would
+	// have resolved are null, and non-null fields null their nearest nullable
+	// parent. The errors of the failed subqueries are returned as a
+	// *graphql.PartialResultError alongside the result.
+	PartialResults bool
+	syncer         *Syncer
+}
+
+// subqueryErrors records the errors of failed subqueries when the executor
+// returns partial results.
+type subqueryErrors struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+func (e *subqueryErrors) record(err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.errs = append(e.errs, err)
 }
 
 // Syncer checks if there is a new schema available and then updates the planner as needed
@@ -327,7 +346,7 @@ func (pathTargets *pathSubqueryMetadata) extractKeys(node interface{}, path []Pa
 	return nil
 }
 
-func (e *Executor) execute(ctx context.Context, isRootPlan bool, p *Plan, keys []interface{}, metadata interface{}, planner *Planner) ([]interface{}, []interface{}, error) {
+func (e *Executor) execute(ctx context.Context, isRootPlan bool, p *Plan, keys []interface{}, metadata interface{}, planner *Planner, errs *subqueryErrors) ([]interface{}, []interface{}, error) {
 	var res []interface{}
 	optionalRespMetadata := make([]interface{}, 0)
 	// var optionalResponseArg interface{}
@@ -372,9 +391,25 @@ func (e *Executor) execute(ctx context.Context, isRootPlan bool, p *Plan, keys [
 
 		g.Go(func() error {
 			// Execute the subquery on the specified service
-			executionResults, subQueryRespMetadata, err := e.execute(ctx, p.Service == gatewayCoordinatorServiceName, subPlan, subPlanMetaData.keys, metadata, planner)
+			executionResults, subQueryRespMetadata, err := e.execute(ctx, p.Service == gatewayCoordinatorServiceName, subPlan, subPlanMetaData.keys, metadata, planner, errs)
 			if err != nil {
-				return oops.Wrapf(err, "executing sub plan: %v", err)
+				if errs == nil {
+					return oops.Wrapf(err, "executing sub plan: %v", err)
+				}
+
+				// Null out the fields of the failed subquery, leaving the rest of
+				// the result intact.
+				errs.record(oops.Wrapf(err, "executing sub plan"))
+				resMu.Lock()
+				defer resMu.Unlock()
+				for _, result := range subPlanMetaData.results {
+					for _, selection := range subPlan.SelectionSet.Selections {
+						if _, ok := result[selection.Alias]; !ok {
+							result[selection.Alias] = nil
+						}
+					}
+				}
+				return nil
 			}
 
 			// Acquire mutex lock before modifying results
@@ -441,7 +476,12 @@ func (e *Executor) Execute(ctx context.Context, query *graphql.Query, metadata i
 		return nil, nil, err
 	}
 
-	r, responseMetadata, err := e.execute(ctx, true, plan, nil, metadata, planner)
+	var errs *subqueryErrors
+	if e.PartialResults {
+		errs = &subqueryErrors{}
+	}
+
+	r, responseMetadata, err := e.execute(ctx, true, plan, nil, metadata, planner, errs)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -454,5 +494,110 @@ func (e *Executor) Execute(ctx context.Context, query *graphql.Query, metadata i
 	// So we expect only one item in this list
 	res := r[0]
 	deleteKey(res, federationField)
+
+	if errs != nil && len(errs.errs) > 0 {
+		res, err = planner.propagateNulls(query, res)
+		if err != nil {
+			return nil, nil, err
+		}
+		return res, responseMetadata, &graphql.PartialResultError{Errors: errs.errs}
+	}
 	return res, responseMetadata, nil
 }
+
+// propagateNulls replaces the objects of res that have a null non-null field
+// with null, up to their nearest nullable parent, as failed subqueries leave
+// their fields null regardless of their type.
+func (e *Planner) propagateNulls(query *graphql.Query, res interface{}) (interface{}, error) {
+	var schema graphql.Type
+	switch query.Kind {
+	case queryString:
+		schema = e.schema.Schema.Query
+	case mutationString:
+		schema = e.schema.Schema.Mutation
+	default:
+		return nil, fmt.Errorf("unknown query kind %s", query.Kind)
+	}
+
+	flattened, err := e.flattener.flatten(query.SelectionSet, schema)
+	if err != nil {
+		return nil, err
+	}
+	res, _ = propagateNullsInValue(res, schema, flattened)
+	return res, nil
+}
+
+// propagateNullsInValue returns value with null propagated from its non-null
+// fields, and whether the returned value is valid for typ.
+func propagateNullsInValue(value interface{}, typ graphql.Type, selectionSet *graphql.SelectionSet) (interface{}, bool) {
+	if nonNull, ok := typ.(*graphql.NonNull); ok {
+		value, _ = propagateNullsInValue(value, nonNull.Type, selectionSet)
+		return value, value != nil
+	}
+	if value == nil {
+		return nil, true
+	}
+
+	switch typ := typ.(type) {
+	case *graphql.List:
+		list, ok := value.([]interface{})
+		if !ok {
+			return value, true
+		}
+		for i, elem := range list {
+			elem, ok := propagateNullsInValue(elem, typ.Type, selectionSet)
+			if !ok {
+				return nil, true
+			}
+			list[i] = elem
+		}
+		return list, true
+
+	case *graphql.Object:
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return value, true
+		}
+		for _, selection := range selectionSet.Selections {
+			field, ok := typ.Fields[selection.Name]
+			if !ok {
+				continue
+			}
+			fieldValue, ok := obj[selection.Alias]
+			if !ok {
+				continue
+			}
+			fieldValue, ok = propagateNullsInValue(fieldValue, field.Type, selection.SelectionSet)
+			if !ok {
+				return nil, true
+			}
+			obj[selection.Alias] = fieldValue
+		}
+		return obj, true
+
+	case *graphql.Union:
+		return propagateNullsInPossibleTypes(value, typ.Types, selectionSet)
+
+	case *graphql.Interface:
+		return propagateNullsInPossibleTypes(value, typ.Types, selectionSet)
+
+	default:
+		return value, true
+	}
+}
+
+// propagateNullsInPossibleTypes propagates nulls in the value of a union or
+// interface, using the fragment on its concrete type.
+func propagateNullsInPossibleTypes(value interface{}, types map[string]*graphql.Object, selectionSet *graphql.SelectionSet) (interface{}, bool) {
+	obj, ok := value.(map[string]interface{})
+	if !ok {
+		return value, true
+	}
+	typeName, _ := obj["__typename"].(string)
+	for _, fragment := range selectionSet.Fragments {
+		if fragment.On == typeName {
+			return propagateNullsInValue(obj, types[typeName], fragment.SelectionSet)
+		}
+	}
+	return obj, true
+}
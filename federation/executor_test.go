@@ -1338,6 +1338,180 @@ func TestExecutorQueriesWithUnionTypes(t *testing.T) {
 	}
 }
 
+type Pet interface {
+	isPet()
+}
+
+type Dog struct {
+	Id   int64
+	Name string
+}
+
+func (*Dog) isPet() {}
+
+type Cat struct {
+	Id   int64
+	Name string
+}
+
+func (*Cat) isPet() {}
+
+func createExecutorWithInterfaces() (*Executor, error) {
+	/*
+		Schema: s1
+		Query {
+			Pet (interface) {
+				id: int64!
+				name: string!
+			}
+			Dog implements Pet { _federation: Dog }
+			Cat implements Pet { _federation: Cat }
+			pets: [Pet]
+		}
+
+		Schema: s2
+		Query {
+			Pet (interface) {
+				id: int64!
+				name: string!
+				favoriteToy: string!
+			}
+			Dog implements Pet {
+				favoriteToy: string!
+				_federation: Dog
+			}
+			bestPet: Pet
+		}
+	*/
+	s1 := schemabuilder.NewSchemaWithName("s1")
+	dog := s1.Object("Dog", Dog{}, schemabuilder.FetchObjectFromKeys(func(args struct{ Keys []*Dog }) []*Dog {
+		return args.Keys
+	}))
+	dog.Key("id")
+	cat := s1.Object("Cat", Cat{}, schemabuilder.FetchObjectFromKeys(func(args struct{ Keys []*Cat }) []*Cat {
+		return args.Keys
+	}))
+	cat.Key("id")
+	s1.Interface("Pet", (*Pet)(nil), Dog{}, Cat{})
+	s1.Query().FieldFunc("pets", func() []Pet {
+		return []Pet{&Dog{Id: 1, Name: "rex"}, &Cat{Id: 2, Name: "tom"}}
+	})
+
+	s2 := schemabuilder.NewSchemaWithName("s2")
+	dogWithToy := s2.Object("Dog", Dog{}, schemabuilder.FetchObjectFromKeys(func(args struct{ Keys []*Dog }) []*Dog {
+		return args.Keys
+	}))
+	dogWithToy.Key("id")
+	dogWithToy.FieldFunc("favoriteToy", func(dog *Dog) string {
+		return fmt.Sprintf("ball %d", dog.Id)
+	})
+	s2.Interface("Pet", (*Pet)(nil), Dog{})
+	s2.Query().FieldFunc("bestPet", func() Pet {
+		return &Dog{Id: 3, Name: "fido"}
+	})
+
+	ctx := context.Background()
+	execs, err := makeExecutors(map[string]*schemabuilder.Schema{
+		"s1": s1,
+		"s2": s2,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return NewExecutor(ctx, execs, &SchemaSyncerConfig{SchemaSyncer: NewIntrospectionSchemaSyncer(ctx, execs, nil)})
+}
+
+func TestExecutorQueriesWithInterfaceTypes(t *testing.T) {
+	e, err := createExecutorWithInterfaces()
+	require.NoError(t, err)
+	testCases := []struct {
+		Name   string
+		Query  string
+		Output string
+	}{
+		{
+			Name: "query interface fields",
+			Query: `
+			query Foo {
+				pets {
+					id
+					name
+				}
+			}`,
+			Output: `
+			{
+				"pets":[
+					{
+						"__key":1,
+						"__typename":"Dog",
+						"id":1,
+						"name":"rex"
+					},
+					{
+						"__key":2,
+						"__typename":"Cat",
+						"id":2,
+						"name":"tom"
+					}
+				]
+			}`,
+		},
+		{
+			Name: "query fields of possible types from another service",
+			Query: `
+			query Foo {
+				pets {
+					name
+					... on Dog {
+						favoriteToy
+					}
+				}
+				bestPet {
+					... on Pet {
+						name
+					}
+					... on Dog {
+						favoriteToy
+					}
+				}
+			}`,
+			Output: `
+			{
+				"pets":[
+					{
+						"__key":1,
+						"__typename":"Dog",
+						"name":"rex",
+						"favoriteToy":"ball 1"
+					},
+					{
+						"__key":2,
+						"__typename":"Cat",
+						"name":"tom"
+					}
+				],
+				"bestPet":{
+					"__key":3,
+					"__typename":"Dog",
+					"name":"fido",
+					"favoriteToy":"ball 3"
+				}
+			}`,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			ctx := context.Background()
+			runAndValidateQueryResults(t, ctx, e, testCase.Query, testCase.Output)
+		})
+	}
+
+	// The merged interface only has the fields of every possible type.
+	_, _, err = e.Execute(context.Background(), graphql.MustParse(`{ pets { favoriteToy } }`, map[string]interface{}{}), nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "favoriteToy")
+}
+
 func TestExecutorQueriesWithFragments(t *testing.T) {
 	e, _, _, _, err := createExecutorWithFederatedUser()
 	require.NoError(t, err)
@@ -1751,6 +1925,44 @@ func TestExecutorReturnsError(t *testing.T) {
 	runAndValidateQueryError(t, ctx, e, `{ fail }`, ``, "executing query: fail: uh oh")
 }
 
+func TestExecutorPartialResults(t *testing.T) {
+	s1 := schemabuilder.NewSchemaWithName("s1")
+	s1.Query().FieldFunc("ok", func(ctx context.Context) string {
+		return "ok"
+	})
+	s2 := schemabuilder.NewSchemaWithName("s2")
+	s2.Query().FieldFunc("fail", func(ctx context.Context) (*string, error) {
+		return nil, errors.New("uh oh")
+	})
+	s2.Query().FieldFunc("failNonNull", func(ctx context.Context) (string, error) {
+		return "", errors.New("uh oh")
+	})
+
+	ctx := context.Background()
+	execs, err := makeExecutors(map[string]*schemabuilder.Schema{
+		"s1": s1,
+		"s2": s2,
+	})
+	require.NoError(t, err)
+
+	e, err := NewExecutor(ctx, execs, &SchemaSyncerConfig{SchemaSyncer: NewIntrospectionSchemaSyncer(ctx, execs, nil)})
+	require.NoError(t, err)
+	e.PartialResults = true
+
+	res, _, err := e.Execute(ctx, graphql.MustParse(`{ ok fail }`, nil), nil)
+	partial, ok := err.(*graphql.PartialResultError)
+	require.True(t, ok, "expected partial result error, got %v", err)
+	require.Len(t, partial.Errors, 1)
+	assert.Contains(t, partial.Errors[0].Error(), "fail: uh oh")
+	assert.Equal(t, map[string]interface{}{"ok": "ok", "fail": nil}, res)
+
+	res, _, err = e.Execute(ctx, graphql.MustParse(`{ ok failNonNull }`, nil), nil)
+	partial, ok = err.(*graphql.PartialResultError)
+	require.True(t, ok, "expected partial result error, got %v", err)
+	require.Len(t, partial.Errors, 1)
+	assert.Nil(t, res)
+}
+
 func TestExpectedFederatedObject(t *testing.T) {
 	type User struct {
 		Id          int64
@@ -2394,3 +2606,27 @@ func TestBasicFederatedObjectFetchAllFields(t *testing.T) {
 		})
 	}
 }
+
+func TestServerComplexityLimits(t *testing.T) {
+	s := schemabuilder.NewSchemaWithName("s1")
+	type Item struct {
+		Name string
+	}
+	s.Query().FieldFunc("items", func(args struct{ First *int64 }) []*Item {
+		return []*Item{{Name: "a"}}
+	})
+	s.Object("Item", Item{})
+
+	srv, err := NewServer(s.MustBuild(), WithComplexityLimits(graphql.ComplexityLimits{MaxCost: 10}))
+	require.NoError(t, err)
+	client := &DirectExecutorClient{Client: srv}
+
+	ctx := context.Background()
+	resp, err := client.Execute(ctx, &QueryRequest{Query: graphql.MustParse(`{ items(first: 2) { name } }`, nil)})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"items": [{"name": "a"}]}`, string(resp.Result))
+
+	_, err = client.Execute(ctx, &QueryRequest{Query: graphql.MustParse(`{ items(first: 100) { name } }`, nil)})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "query has cost 101, which exceeds the maximum cost of 10")
+}
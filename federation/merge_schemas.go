@@ -58,7 +58,7 @@ func (t *introspectionTypeRef) String() string {
 		return "<nil>"
 	}
 	switch t.Kind {
-	case "SCALAR", "ENUM", "UNION", "OBJECT", "INPUT_OBJECT":
+	case "SCALAR", "ENUM", "UNION", "INTERFACE", "OBJECT", "INPUT_OBJECT":
 		return t.Name
 	case "NON_NULL":
 		return t.OfType.String() + "!"
@@ -102,14 +102,16 @@ type introspectiveDirective struct {
 }
 
 type introspectionType struct {
-	Name          string                    `json:"name"`
-	Kind          string                    `json:"kind"`
-	Fields        []introspectionField      `json:"fields"`
-	InputFields   []introspectionInputField `json:"inputFields"`
-	PossibleTypes []*introspectionTypeRef   `json:"possibleTypes"`
-	EnumValues    []introspectionEnumValue  `json:"enumValues"`
-	Description   string                    `json:"description"`
-	Interfaces    []*introspectionTypeRef   `json:"interfaces"`
+	Name           string                    `json:"name"`
+	Kind           string                    `json:"kind"`
+	Fields         []introspectionField      `json:"fields"`
+	InputFields    []introspectionInputField `json:"inputFields"`
+	IsOneOf        bool                      `json:"isOneOf,omitempty"`
+	SpecifiedByURL string                    `json:"specifiedByURL,omitempty"`
+	PossibleTypes  []*introspectionTypeRef   `json:"possibleTypes"`
+	EnumValues     []introspectionEnumValue  `json:"enumValues"`
+	Description    string                    `json:"description"`
+	Interfaces     []*introspectionTypeRef   `json:"interfaces"`
 }
 
 type introspectionSchema struct {
@@ -169,7 +171,7 @@ func mergeTypeRefs(a, b *introspectionTypeRef, isInput bool) (*introspectionType
 	}
 	switch a.Kind {
 	// Basic types must be identical.
-	case "SCALAR", "ENUM", "INPUT_OBJECT", "UNION", "OBJECT":
+	case "SCALAR", "ENUM", "INPUT_OBJECT", "UNION", "INTERFACE", "OBJECT":
 		if a.Name != b.Name {
 			return nil, errors.New("types must be identical")
 		}
@@ -364,6 +366,9 @@ func mergeTypes(a, b introspectionType, mode MergeMode) (*introspectionType, err
 			return nil, fmt.Errorf("merging input fields: %v", err)
 		}
 		merged.InputFields = inputFields
+		// The merged input object must be accepted by both services, so it
+		// is one-of if either is.
+		merged.IsOneOf = a.IsOneOf || b.IsOneOf
 
 	case "OBJECT":
 		fields, err := mergeFields(a.Fields, b.Fields, mode)
@@ -372,6 +377,12 @@ func mergeTypes(a, b introspectionType, mode MergeMode) (*introspectionType, err
 		}
 		merged.Fields = fields
 
+		interfaces, err := mergeInterfaces(a.Interfaces, b.Interfaces, mode)
+		if err != nil {
+			return nil, fmt.Errorf("merging interfaces: %v", err)
+		}
+		merged.Interfaces = interfaces
+
 	case "UNION":
 		possibleTypes, err := mergePossibleTypes(a.PossibleTypes, b.PossibleTypes, mode)
 		if err != nil {
@@ -380,6 +391,20 @@ func mergeTypes(a, b introspectionType, mode MergeMode) (*introspectionType, err
 		merged.PossibleTypes = possibleTypes
 
 	case "INTERFACE":
+		// Every possible type of the merged interface must have its fields,
+		// so it only has the fields of both interfaces.
+		fields, err := mergeFields(a.Fields, b.Fields, Intersection)
+		if err != nil {
+			return nil, fmt.Errorf("merging fields: %v", err)
+		}
+		merged.Fields = fields
+
+		possibleTypes, err := mergePossibleTypes(a.PossibleTypes, b.PossibleTypes, mode)
+		if err != nil {
+			return nil, fmt.Errorf("merging possible types: %v", err)
+		}
+		merged.PossibleTypes = possibleTypes
+
 		interfaces, err := mergeInterfaces(a.Interfaces, b.Interfaces, mode)
 		if err != nil {
 			return nil, fmt.Errorf("merging interfaces: %v", err)
@@ -394,6 +419,10 @@ func mergeTypes(a, b introspectionType, mode MergeMode) (*introspectionType, err
 		merged.EnumValues = enumValues
 
 	case "SCALAR":
+		merged.SpecifiedByURL = a.SpecifiedByURL
+		if merged.SpecifiedByURL == "" {
+			merged.SpecifiedByURL = b.SpecifiedByURL
+		}
 
 	default:
 		return nil, fmt.Errorf("unknown kind %s", a.Kind)
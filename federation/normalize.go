@@ -38,6 +38,15 @@ func CollectTypes(typ graphql.Type, types map[graphql.Type]string) error {
 			CollectTypes(obj, types)
 		}
 
+	case *graphql.Interface:
+		types[typ] = typ.Name
+		for _, field := range typ.Fields {
+			CollectTypes(field.Type, types)
+		}
+		for _, obj := range typ.Types {
+			CollectTypes(obj, types)
+		}
+
 	case *graphql.Enum:
 		types[typ] = typ.Type
 
@@ -102,6 +111,10 @@ func (f *flattener) applies(obj *graphql.Object, fragment *graphql.Fragment) (bo
 		// A union matches if the object is part of the union.
 		_, ok := typ.Types[obj.Name]
 		return ok, nil
+	case *graphql.Interface:
+		// An interface matches if the object implements it.
+		_, ok := typ.Types[obj.Name]
+		return ok, nil
 	default:
 		return false, fmt.Errorf("unknown fragment type %s", fragment.On)
 	}
@@ -264,40 +277,48 @@ func (f *flattener) flatten(selectionSet *graphql.SelectionSet, typ graphql.Type
 		}, nil
 
 	case *graphql.Union:
-		// To normalize a union query, consider all possible union types and
-		// build an inline fragment for each them by recursively normalize the
-		// query for the concrete object types.
+		return f.flattenPossibleTypes(selectionSet, typ.Types)
 
-		// Create a fragment for every possible type.
-		fragments := make([]*graphql.Fragment, 0, len(typ.Types))
-		for _, obj := range typ.Types {
-			plan, err := f.flatten(selectionSet, obj)
-			if err != nil {
-				return nil, err
-			}
+	case *graphql.Interface:
+		return f.flattenPossibleTypes(selectionSet, typ.Types)
 
-			// Don't bother if there are no selections. There will be no
-			// fragments.
-			if len(plan.Selections) > 0 {
-				fragments = append(fragments, &graphql.Fragment{
-					On:           obj.Name,
-					SelectionSet: plan,
-				})
-			}
-		}
+	default:
+		return nil, fmt.Errorf("bad typ %v", typ)
+	}
+}
 
-		// Sort fragments on name for deterministic ordering.
-		sort.Slice(fragments, func(a, b int) bool {
-			return fragments[a].On < fragments[b].On
-		})
+// flattenPossibleTypes normalizes a query on a union or interface.
+func (f *flattener) flattenPossibleTypes(selectionSet *graphql.SelectionSet, types map[string]*graphql.Object) (*graphql.SelectionSet, error) {
+	// To normalize a union or interface query, consider all possible types
+	// and build an inline fragment for each them by recursively normalize the
+	// query for the concrete object types.
 
-		return &graphql.SelectionSet{
-			Fragments: fragments,
-		}, nil
+	// Create a fragment for every possible type.
+	fragments := make([]*graphql.Fragment, 0, len(types))
+	for _, obj := range types {
+		plan, err := f.flatten(selectionSet, obj)
+		if err != nil {
+			return nil, err
+		}
 
-	default:
-		return nil, fmt.Errorf("bad typ %v", typ)
+		// Don't bother if there are no selections. There will be no
+		// fragments.
+		if len(plan.Selections) > 0 {
+			fragments = append(fragments, &graphql.Fragment{
+				On:           obj.Name,
+				SelectionSet: plan,
+			})
+		}
 	}
+
+	// Sort fragments on name for deterministic ordering.
+	sort.Slice(fragments, func(a, b int) bool {
+		return fragments[a].On < fragments[b].On
+	})
+
+	return &graphql.SelectionSet{
+		Fragments: fragments,
+	}, nil
 }
 
 // TODO: When adding types to a union, the normalizer might not know about all
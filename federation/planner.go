@@ -330,7 +330,9 @@ func (e *Planner) planObject(typ *graphql.Object, selectionSet *graphql.Selectio
 
 }
 
-func (e *Planner) planUnion(typ *graphql.Union, selectionSet *graphql.SelectionSet, service string) (*Plan, error) {
+// planUnion plans a query on a union or interface, whose possible types are
+// types.
+func (e *Planner) planUnion(name string, types map[string]*graphql.Object, selectionSet *graphql.SelectionSet, service string) (*Plan, error) {
 	plan := &Plan{
 		// TODO: only include __typename if needed for dispatching? ie. len(types) > 1 and len(fragments) > 0?
 		// TODO: ensure __typename doesn't conflict with another field?
@@ -365,9 +367,15 @@ func (e *Planner) planUnion(typ *graphql.Union, selectionSet *graphql.SelectionS
 		seenFragments[fragment.On] = struct{}{}
 
 		// All fragments must be on concrete types
-		typ, ok := typ.Types[fragment.On]
+		typ, ok := types[fragment.On]
 		if !ok {
-			return nil, fmt.Errorf("unexpected fragment on %s for typ %s", fragment.On, typ.Name)
+			return nil, fmt.Errorf("unexpected fragment on %s for typ %s", fragment.On, name)
+		}
+
+		// The service can only return the possible types it knows, and
+		// would reject fragments on the others.
+		if !e.serviceHasType(typ, service) {
+			continue
 		}
 
 		// Create a plan for all fragment types
@@ -392,6 +400,16 @@ func (e *Planner) planUnion(typ *graphql.Union, selectionSet *graphql.SelectionS
 	return plan, nil
 }
 
+// serviceHasType returns if service knows the object typ.
+func (e *Planner) serviceHasType(typ *graphql.Object, service string) bool {
+	for _, field := range typ.Fields {
+		if info, ok := e.schema.Fields[field]; ok && info.Services[service] {
+			return true
+		}
+	}
+	return false
+}
+
 func (e *Planner) plan(typIface graphql.Type, selectionSet *graphql.SelectionSet, service string) (*Plan, error) {
 	switch typ := typIface.(type) {
 	case *graphql.NonNull:
@@ -404,7 +422,10 @@ func (e *Planner) plan(typIface graphql.Type, selectionSet *graphql.SelectionSet
 		return e.planObject(typ, selectionSet, service)
 
 	case *graphql.Union:
-		return e.planUnion(typ, selectionSet, service)
+		return e.planUnion(typ.Name, typ.Types, selectionSet, service)
+
+	case *graphql.Interface:
+		return e.planUnion(typ.Name, typ.Types, selectionSet, service)
 
 	default:
 		return nil, fmt.Errorf("bad typ %v", typIface)
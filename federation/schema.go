@@ -246,7 +246,10 @@ func ConvertVersionedSchemas(schemas serviceSchemas) (*SchemaWithFederationInfo,
 	if err != nil {
 		return nil, err
 	}
-	for name := range types {
+	for name, typ := range types {
+		if _, ok := typ.(*graphql.Object); !ok {
+			continue
+		}
 		if err := validateFederatedObjects(serviceNames, serviceSchemasByName, name); err != nil {
 			return nil, oops.Wrapf(err, "Expected all services with object %s to be federated", name)
 		}
@@ -358,7 +361,7 @@ func lookupType(t *introspectionTypeRef, all map[string]graphql.Type) (*introspe
 		return nil, errors.New("malformed typeref")
 	}
 	switch t.Kind {
-	case "SCALAR", "OBJECT", "UNION", "INPUT_OBJECT", "ENUM":
+	case "SCALAR", "OBJECT", "UNION", "INTERFACE", "INPUT_OBJECT", "ENUM":
 		return t, nil
 	case "LIST":
 		return lookupType(t.OfType, all)
@@ -376,7 +379,7 @@ func lookupTypeRef(t *introspectionTypeRef, all map[string]graphql.Type) (graphq
 	}
 
 	switch t.Kind {
-	case "SCALAR", "OBJECT", "UNION", "INPUT_OBJECT", "ENUM":
+	case "SCALAR", "OBJECT", "UNION", "INTERFACE", "INPUT_OBJECT", "ENUM":
 		// TODO: enforce type?
 		typ, ok := all[t.Name]
 		if !ok {
@@ -433,6 +436,46 @@ func parseInputFields(source []introspectionInputField, all map[string]graphql.T
 	return fields, nil
 }
 
+// parseFields maps the fields of an object or interface to graphql fields
+func parseFields(typ introspectionType, all map[string]graphql.Type) (map[string]*graphql.Field, error) {
+	fields := make(map[string]*graphql.Field)
+	for _, field := range typ.Fields {
+		fieldTyp, err := lookupTypeRef(field.Type, all)
+		if err != nil {
+			return nil, fmt.Errorf("typ %s field %s has bad typ: %v",
+				typ.Name, field.Name, err)
+		}
+
+		parsed, err := parseInputFields(field.Args, all)
+		if err != nil {
+			return nil, fmt.Errorf("field %s input: %v", field.Name, err)
+		}
+
+		fields[field.Name] = &graphql.Field{
+			Args: parsed,
+			Type: fieldTyp,
+		}
+	}
+	return fields, nil
+}
+
+// parsePossibleTypes maps the possible types of a union or interface to
+// graphql objects
+func parsePossibleTypes(typ introspectionType, all map[string]graphql.Type) (map[string]*graphql.Object, error) {
+	types := make(map[string]*graphql.Object)
+	for _, other := range typ.PossibleTypes {
+		if other.Kind != "OBJECT" {
+			return nil, fmt.Errorf("typ %s has possible typ not OBJECT: %v", typ.Name, other)
+		}
+		obj, ok := all[other.Name].(*graphql.Object)
+		if !ok {
+			return nil, fmt.Errorf("typ %s possible typ %s does not refer to obj", typ.Name, other.Name)
+		}
+		types[obj.Name] = obj
+	}
+	return types, nil
+}
+
 // parseSchema takes the introspected schema, validates the types,
 // and maps every field to the graphql types
 func parseSchema(schema *IntrospectionQueryResult) (map[string]graphql.Type, error) {
@@ -451,12 +494,14 @@ func parseSchema(schema *IntrospectionQueryResult) (map[string]graphql.Type, err
 
 		case "INPUT_OBJECT":
 			all[typ.Name] = &graphql.InputObject{
-				Name: typ.Name,
+				Name:  typ.Name,
+				OneOf: typ.IsOneOf,
 			}
 
 		case "SCALAR":
 			all[typ.Name] = &graphql.Scalar{
-				Type: typ.Name,
+				Type:           typ.Name,
+				SpecifiedByURL: typ.SpecifiedByURL,
 			}
 
 		case "UNION":
@@ -464,6 +509,11 @@ func parseSchema(schema *IntrospectionQueryResult) (map[string]graphql.Type, err
 				Name: typ.Name,
 			}
 
+		case "INTERFACE":
+			all[typ.Name] = &graphql.Interface{
+				Name: typ.Name,
+			}
+
 		case "ENUM":
 			all[typ.Name] = &graphql.Enum{
 				Type: typ.Name,
@@ -478,26 +528,42 @@ func parseSchema(schema *IntrospectionQueryResult) (map[string]graphql.Type, err
 	for _, typ := range schema.Schema.Types {
 		switch typ.Kind {
 		case "OBJECT":
-			fields := make(map[string]*graphql.Field)
-			for _, field := range typ.Fields {
-				fieldTyp, err := lookupTypeRef(field.Type, all)
-				if err != nil {
-					return nil, fmt.Errorf("typ %s field %s has bad typ: %v",
-						typ.Name, field.Name, err)
-				}
+			fields, err := parseFields(typ, all)
+			if err != nil {
+				return nil, err
+			}
 
-				parsed, err := parseInputFields(field.Args, all)
-				if err != nil {
-					return nil, fmt.Errorf("field %s input: %v", field.Name, err)
-				}
+			obj := all[typ.Name].(*graphql.Object)
+			obj.Fields = fields
 
-				fields[field.Name] = &graphql.Field{
-					Args: parsed,
-					Type: fieldTyp,
+			if len(typ.Interfaces) > 0 {
+				obj.Interfaces = make(map[string]*graphql.Interface)
+				for _, other := range typ.Interfaces {
+					iface, ok := all[other.Name].(*graphql.Interface)
+					if !ok {
+						return nil, fmt.Errorf("typ %s interface %s does not refer to interface", typ.Name, other.Name)
+					}
+					obj.Interfaces[iface.Name] = iface
 				}
 			}
 
-			all[typ.Name].(*graphql.Object).Fields = fields
+		case "INTERFACE":
+			fields, err := parseFields(typ, all)
+			if err != nil {
+				return nil, err
+			}
+			// The federation field of each possible type is resolved by the
+			// gateway, not queried through the interface.
+			delete(fields, federationField)
+
+			types, err := parsePossibleTypes(typ, all)
+			if err != nil {
+				return nil, err
+			}
+
+			iface := all[typ.Name].(*graphql.Interface)
+			iface.Fields = fields
+			iface.Types = types
 
 		case "INPUT_OBJECT":
 			parsed, err := parseInputFields(typ.InputFields, all)
@@ -508,16 +574,9 @@ func parseSchema(schema *IntrospectionQueryResult) (map[string]graphql.Type, err
 			all[typ.Name].(*graphql.InputObject).InputFields = parsed
 
 		case "UNION":
-			types := make(map[string]*graphql.Object)
-			for _, other := range typ.PossibleTypes {
-				if other.Kind != "OBJECT" {
-					return nil, fmt.Errorf("typ %s has possible typ not OBJECT: %v", typ.Name, other)
-				}
-				typ, ok := all[other.Name].(*graphql.Object)
-				if !ok {
-					return nil, fmt.Errorf("typ %s possible typ %s does not refer to obj", typ.Name, other.Name)
-				}
-				types[typ.Name] = typ
+			types, err := parsePossibleTypes(typ, all)
+			if err != nil {
+				return nil, err
 			}
 
 			all[typ.Name].(*graphql.Union).Types = types
@@ -548,7 +607,4 @@ func parseSchema(schema *IntrospectionQueryResult) (map[string]graphql.Type, err
 
 // XXX: for types missing __federation, take intersection?
 
-// XXX: for (merged) unions, make sure we only send possible types
-// to each service
-
 // TODO: support descriptions in merging
@@ -353,6 +353,30 @@ func TestMergeUnionIntersection(t *testing.T) {
 	assertSchemaIntersectionEq(t, s1, s2, s3)
 }
 
+// TestMergeInterfaceUnion tests that merging interface types takes the union
+// of their possible types, and the fields of both interfaces.
+func TestMergeInterfaceUnion(t *testing.T) {
+	s1 := schemabuilder.NewSchema()
+	s1.Object("Dog", Dog{})
+	s1.Object("Cat", Cat{})
+	s1.Interface("Pet", (*Pet)(nil), Dog{}, Cat{})
+	s1.Query().FieldFunc("pets", func() []Pet { return nil })
+
+	s2 := schemabuilder.NewSchema()
+	s2.Object("Dog", Dog{}).FieldFunc("favoriteToy", func(dog *Dog) string { return "" })
+	s2.Interface("Pet", (*Pet)(nil), Dog{})
+	s2.Query().FieldFunc("bestPet", func() Pet { return nil })
+
+	s3 := schemabuilder.NewSchema()
+	s3.Object("Dog", Dog{}).FieldFunc("favoriteToy", func(dog *Dog) string { return "" })
+	s3.Object("Cat", Cat{})
+	s3.Interface("Pet", (*Pet)(nil), Dog{}, Cat{})
+	s3.Query().FieldFunc("pets", func() []Pet { return nil })
+	s3.Query().FieldFunc("bestPet", func() Pet { return nil })
+
+	assertSchemaUnionEq(t, s1, s2, s3)
+}
+
 // TestMergeEnumUnion tests that merging union types takes the union of their
 // values.
 func TestMergeEnumUnion(t *testing.T) {
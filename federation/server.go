@@ -57,21 +57,40 @@ func (c *DirectExecutorClient) Execute(ctx context.Context, request *QueryReques
 var _ thunderpb.ExecutorServer = &Server{}
 
 type Server struct {
-	schema        *graphql.Schema
-	localExecutor graphql.ExecutorRunner
+	schema           *graphql.Schema
+	localExecutor    graphql.ExecutorRunner
+	complexityLimits graphql.ComplexityLimits
 }
 
-func NewServer(schema *graphql.Schema) (*Server, error) {
+// ServerOption configures a Server.
+type ServerOption func(*Server)
+
+// WithComplexityLimits rejects subqueries that exceed limits.
+func WithComplexityLimits(limits graphql.ComplexityLimits) ServerOption {
+	return func(s *Server) {
+		s.complexityLimits = limits
+	}
+}
+
+func NewServer(schema *graphql.Schema, opts ...ServerOption) (*Server, error) {
 	introspection.AddIntrospectionToSchema(schema)
 	localExecutor := graphql.NewExecutor(graphql.NewImmediateGoroutineScheduler())
-	return &Server{
+	s := &Server{
 		schema:        schema,
 		localExecutor: localExecutor,
-	}, nil
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s, nil
 }
 
 // ExecuteRequest unmarshals the protobuf query and executes it on the server
 func ExecuteRequest(ctx context.Context, req *thunderpb.ExecuteRequest, gqlSchema *graphql.Schema, localExecutor graphql.ExecutorRunner) (*thunderpb.ExecuteResponse, error) {
+	return executeRequest(ctx, req, gqlSchema, localExecutor, graphql.ComplexityLimits{})
+}
+
+func executeRequest(ctx context.Context, req *thunderpb.ExecuteRequest, gqlSchema *graphql.Schema, localExecutor graphql.ExecutorRunner, limits graphql.ComplexityLimits) (*thunderpb.ExecuteResponse, error) {
 	query, err := UnmarshalQuery(req.Query)
 	if err != nil {
 		return nil, oops.Wrapf(err, "unmarshaling query")
@@ -87,7 +106,10 @@ func ExecuteRequest(ctx context.Context, req *thunderpb.ExecuteRequest, gqlSchem
 		return nil, fmt.Errorf("unknown kind %s", query.Kind)
 	}
 
-	if err := graphql.PrepareQuery(ctx, schema, query.SelectionSet); err != nil {
+	if err := graphql.PrepareQuery(ctx, schema, query.SelectionSet, gqlSchema.PrepareOptions()...); err != nil {
+		return nil, err
+	}
+	if err := graphql.CheckComplexity(schema, query.SelectionSet, limits); err != nil {
 		return nil, err
 	}
 
@@ -125,7 +147,7 @@ func ExecuteRequest(ctx context.Context, req *thunderpb.ExecuteRequest, gqlSchem
 }
 
 func (s *Server) Execute(ctx context.Context, req *thunderpb.ExecuteRequest) (*thunderpb.ExecuteResponse, error) {
-	return ExecuteRequest(ctx, req, s.schema, s.localExecutor)
+	return executeRequest(ctx, req, s.schema, s.localExecutor, s.complexityLimits)
 }
 
 // marshalPbSelections gets a selection set and marshals it into the protobuf format
@@ -102,22 +102,44 @@ type WorkScheduler interface {
 	Run(resolver UnitResolver, startingUnits ...*WorkUnit)
 }
 
-func NewExecutor(scheduler WorkScheduler) ExecutorRunner {
-	return &Executor{
+func NewExecutor(scheduler WorkScheduler, opts ...ExecutorOption) ExecutorRunner {
+	e := &Executor{
 		scheduler: scheduler,
 	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// ExecutorOption configures an Executor created by NewExecutor.
+type ExecutorOption func(*Executor)
+
+// WithPartialResults makes the Executor return partial results when fields
+// fail, as described by the GraphQL spec. A failing field is null in the
+// result, unless it is non-null, in which case its nearest nullable parent is
+// null instead. The errors of the failed fields are returned as a
+// *PartialResultError alongside the result.
+func WithPartialResults() ExecutorOption {
+	return func(e *Executor) {
+		e.partialResults = true
+	}
 }
 
 // BatchExecutor is a GraphQL executor.  Given a query it can run through the
 // execution of the request.
 type Executor struct {
-	scheduler WorkScheduler
+	scheduler      WorkScheduler
+	partialResults bool
+	tracers        []Tracer
 }
 
 // Execute executes a query by traversing the GraphQL query graph and resolving
 // or executing fields.  Any work that needs to be done is passed off to the
 // scheduler to handle managing concurrency of the request.
-// It must return a JSON marshallable response (or an error).
+// It must return a JSON marshallable response (or an error). If the Executor
+// returns partial results, a response may be returned along with a
+// *PartialResultError.
 func (e *Executor) Execute(ctx context.Context, typ Type, source interface{}, query *Query) (interface{}, error) {
 	queryObject, ok := typ.(*Object)
 	if !ok {
@@ -128,6 +150,9 @@ func (e *Executor) Execute(ctx context.Context, typ Type, source interface{}, qu
 	if err != nil {
 		return nil, err
 	}
+	for _, tracer := range e.tracers {
+		ctx = ContextWithTracer(ctx, tracer)
+	}
 	topLevelRespWriter := newTopLevelOutputNode(query.Name)
 	initialSelectionWorkUnits := make([]*WorkUnit, 0, len(topLevelSelections))
 	writers := make(map[string]*outputNode)
@@ -144,7 +169,7 @@ func (e *Executor) Execute(ctx context.Context, typ Type, source interface{}, qu
 			return nil, fmt.Errorf("invalid top-level selection %q", selection.Name)
 		}
 
-		writer := newOutputNode(topLevelRespWriter, selection.Alias)
+		writer := newOutputNode(topLevelRespWriter, selection.Alias, isNullable(field.Type))
 		writers[selection.Alias] = writer
 
 		initialSelectionWorkUnits = append(
@@ -160,12 +185,55 @@ func (e *Executor) Execute(ctx context.Context, typ Type, source interface{}, qu
 		)
 	}
 
-	e.scheduler.Run(executeWorkUnit, initialSelectionWorkUnits...)
+	// A failed field fails the whole mutation when the result is discarded or
+	// rolled back, so later fields need not run.
+	stopOnError := !e.partialResults || queryObject.Transaction != nil
+	execute := func(ctx context.Context) error {
+		for _, unit := range initialSelectionWorkUnits {
+			unit.Ctx = ctx
+		}
+		if query.Kind != "mutation" {
+			e.scheduler.Run(executeWorkUnit, initialSelectionWorkUnits...)
+			return topLevelRespWriter.errRecorder.firstErr()
+		}
+
+		// The fields of a mutation run serially, each along with its
+		// selections, as they may have side effects.
+		for _, unit := range initialSelectionWorkUnits {
+			e.scheduler.Run(executeWorkUnit, unit)
+			if stopOnError && topLevelRespWriter.errRecorder.firstErr() != nil {
+				break
+			}
+		}
+		return topLevelRespWriter.errRecorder.firstErr()
+	}
+
+	if queryObject.Transaction == nil {
+		execute(ctx)
+	} else if err := queryObject.Transaction(ctx, execute); err != nil {
+		// The mutation was rolled back, so none of its results hold.
+		errs := topLevelRespWriter.errRecorder.errs
+		if !e.partialResults || len(errs) == 0 {
+			return nil, err
+		}
+		return nil, &PartialResultError{Errors: errs}
+	}
+
+	if !e.partialResults {
+		if err := topLevelRespWriter.errRecorder.firstErr(); err != nil {
+			return nil, err
+		}
+		return outputNodeToJSON(writers), nil
+	}
 
-	if topLevelRespWriter.errRecorder.err != nil {
-		return nil, topLevelRespWriter.errRecorder.err
+	errs := topLevelRespWriter.errRecorder.errs
+	if len(errs) == 0 {
+		return outputNodeToJSON(writers), nil
+	}
+	if topLevelRespWriter.failed {
+		return nil, &PartialResultError{Errors: errs}
 	}
-	return outputNodeToJSON(writers), nil
+	return outputNodeToJSON(writers), &PartialResultError{Errors: errs}
 }
 
 // executeWorkUnit executes/resolves a work unit and checks the
@@ -188,7 +256,7 @@ func executeWorkUnit(unit *WorkUnit) []*WorkUnit {
 }
 
 func executeBatchWorkUnit(unit *WorkUnit) []*WorkUnit {
-	results, err := SafeExecuteBatchResolver(unit.Ctx, unit.field, unit.sources, unit.selection.Args, unit.selection.SelectionSet)
+	results, err := executeBatchResolver(unit)
 	if err != nil {
 		for _, dest := range unit.destinations {
 			dest.Fail(err)
@@ -207,6 +275,7 @@ func executeBatchWorkUnit(unit *WorkUnit) []*WorkUnit {
 
 func executeNonExpensiveWorkUnit(unit *WorkUnit) []*WorkUnit {
 	results := make([]interface{}, 0, len(unit.sources))
+	destinations := make([]*outputNode, 0, len(unit.destinations))
 	for idx, src := range unit.sources {
 		ctx := unit.Ctx
 
@@ -215,17 +284,18 @@ func executeNonExpensiveWorkUnit(unit *WorkUnit) []*WorkUnit {
 		if unit.objectName != "Mutation" {
 			ctx = context.WithValue(unit.Ctx, nonExpensive{}, struct{}{})
 		}
-		fieldResult, err := SafeExecuteResolver(ctx, unit.field, src, unit.selection.Args, unit.selection.SelectionSet)
+		fieldResult, err := executeResolver(ctx, unit, src, unit.destinations[idx])
 		if err != nil {
-			// Fail the unit and exit.
+			// Fail the source's destination, and keep resolving the others.
 			unit.destinations[idx].Fail(err)
-			return nil
+			continue
 		}
 		results = append(results, fieldResult)
+		destinations = append(destinations, unit.destinations[idx])
 	}
-	unitChildren, err := resolveBatch(unit.Ctx, results, unit.field.Type, unit.selection.SelectionSet, unit.destinations)
+	unitChildren, err := resolveBatch(unit.Ctx, results, unit.field.Type, unit.selection.SelectionSet, destinations)
 	if err != nil {
-		for _, dest := range unit.destinations {
+		for _, dest := range destinations {
 			dest.Fail(err)
 		}
 		return nil
@@ -238,11 +308,14 @@ func executeNonExpensiveWorkUnit(unit *WorkUnit) []*WorkUnit {
 // This function makes two assumptions:
 // - We assume that all the reactive cache will get cleared if there is an error.
 // - We assume that there is no "error-catching" mechanism that will stop an
-//   error from propagating all the way to the top of the request stack.
+//   error from propagating all the way to the top of the request stack. With
+//   partial results, callers must still treat a *PartialResultError as a
+//   failed computation so the cache is cleared.
 func executeNonBatchWorkUnitWithCaching(src interface{}, dest *outputNode, unit *WorkUnit) []*WorkUnit {
 	var workUnits []*WorkUnit
 	subDestRes, err := reactive.Cache(unit.Ctx, getWorkCacheKey(src, unit.field, unit.selection), func(ctx context.Context) (interface{}, error) {
-		subDest := newOutputNode(dest, "")
+		// subDest stands in for dest, so its failures propagate to dest.
+		subDest := newOutputNode(dest, "", false)
 		workUnits = executeNonBatchWorkUnit(ctx, src, subDest, unit)
 		return subDest.res, nil
 	})
@@ -270,7 +343,7 @@ func getWorkCacheKey(src interface{}, field *Field, selection *Selection) resolv
 
 // executeNonBatchWorkUnit resolves a non-batch field in our graphql response graph.
 func executeNonBatchWorkUnit(ctx context.Context, src interface{}, dest *outputNode, unit *WorkUnit) []*WorkUnit {
-	fieldResult, err := SafeExecuteResolver(ctx, unit.field, src, unit.selection.Args, unit.selection.SelectionSet)
+	fieldResult, err := executeResolver(ctx, unit, src, dest)
 	if err != nil {
 		dest.Fail(err)
 		return nil
@@ -283,6 +356,68 @@ func executeNonBatchWorkUnit(ctx context.Context, src interface{}, dest *outputN
 	return subFieldWorkUnits
 }
 
+// executeResolver runs the resolver of a work unit for the source of dest,
+// wrapped in the handlers of the directives of the field.
+func executeResolver(ctx context.Context, unit *WorkUnit, src interface{}, dest *outputNode) (interface{}, error) {
+	if directives := directiveHandlers(unit.field, unit.selection); len(directives) > 0 {
+		return runDirectiveHandlers(ctx, directives, func(ctx context.Context) (interface{}, error) {
+			return traceResolver(ctx, unit, src, dest)
+		})
+	}
+	return traceResolver(ctx, unit, src, dest)
+}
+
+// traceResolver runs the resolver of a work unit for the source of dest,
+// notifying the tracers in ctx.
+func traceResolver(ctx context.Context, unit *WorkUnit, src interface{}, dest *outputNode) (interface{}, error) {
+	tracers := tracersFromContext(ctx)
+	if len(tracers) == 0 {
+		return SafeExecuteResolver(ctx, unit.field, src, unit.selection.Args, unit.selection.SelectionSet)
+	}
+
+	trace := newFieldTrace(unit, []*outputNode{dest}, false)
+	ctx = startTrace(ctx, tracers, trace)
+	result, err := SafeExecuteResolver(ctx, unit.field, src, unit.selection.Args, unit.selection.SelectionSet)
+	finishTrace(ctx, tracers, trace, err)
+	return result, err
+}
+
+// executeBatchResolver runs the batch resolver of a work unit for all of its
+// sources, wrapped in the handlers of the directives of the field.
+func executeBatchResolver(unit *WorkUnit) ([]interface{}, error) {
+	directives := directiveHandlers(unit.field, unit.selection)
+	if len(directives) == 0 {
+		return traceBatchResolver(unit.Ctx, unit)
+	}
+
+	result, err := runDirectiveHandlers(unit.Ctx, directives, func(ctx context.Context) (interface{}, error) {
+		return traceBatchResolver(ctx, unit)
+	})
+	if err != nil {
+		return nil, err
+	}
+	results, ok := result.([]interface{})
+	if !ok || len(results) != len(unit.sources) {
+		return nil, fmt.Errorf("directive handlers of batch field %q must return a result for every source", unit.selection.Name)
+	}
+	return results, nil
+}
+
+// traceBatchResolver runs the batch resolver of a work unit for all of its
+// sources, notifying the tracers in ctx.
+func traceBatchResolver(ctx context.Context, unit *WorkUnit) ([]interface{}, error) {
+	tracers := tracersFromContext(ctx)
+	if len(tracers) == 0 {
+		return SafeExecuteBatchResolver(ctx, unit.field, unit.sources, unit.selection.Args, unit.selection.SelectionSet)
+	}
+
+	trace := newFieldTrace(unit, unit.destinations, true)
+	ctx = startTrace(ctx, tracers, trace)
+	results, err := SafeExecuteBatchResolver(ctx, unit.field, unit.sources, unit.selection.Args, unit.selection.SelectionSet)
+	finishTrace(ctx, tracers, trace, err)
+	return results, err
+}
+
 // resolveBatch traverses the provided sources and fills in result data and
 // returns new work units that are required to resolve the rest of the
 // query result.
@@ -299,6 +434,8 @@ func resolveBatch(ctx context.Context, sources []interface{}, typ Type, selectio
 		return resolveListBatch(ctx, sources, typ, selectionSet, destinations)
 	case *Union:
 		return resolveUnionBatch(ctx, sources, typ, selectionSet, destinations)
+	case *Interface:
+		return resolveInterfaceBatch(ctx, sources, typ, selectionSet, destinations)
 	case *Object:
 		return resolveObjectBatch(ctx, sources, typ, selectionSet, destinations)
 	case *NonNull:
@@ -360,7 +497,7 @@ func resolveListBatch(ctx context.Context, sources []interface{}, typ *List, sel
 		}
 		respList := make([]interface{}, slice.Len())
 		for i := 0; i < slice.Len(); i++ {
-			writer := newOutputNode(destinations[idx], strconv.Itoa(i))
+			writer := newOutputNode(destinations[idx], strconv.Itoa(i), isNullable(typ.Type))
 			respList[i] = writer
 			flattenedResps = append(flattenedResps, writer)
 			flattenedSources = append(flattenedSources, slice.Index(i).Interface())
@@ -405,7 +542,7 @@ func resolveUnionBatch(ctx context.Context, sources []interface{}, typ *Union, s
 	for srcType, sources := range sourcesByType {
 		gqlType := typ.Types[srcType]
 		for _, fragment := range selectionSet.Fragments {
-			if fragment.On != srcType {
+			if !matchesTypeCondition(gqlType, fragment.On) {
 				continue
 			}
 			units, err := resolveObjectBatch(ctx, sources, gqlType, fragment.SelectionSet, destinationsByType[srcType])
@@ -419,6 +556,45 @@ func resolveUnionBatch(ctx context.Context, sources []interface{}, typ *Union, s
 	return workUnits, nil
 }
 
+// Resolves the concrete type of every provided source of an Interface type and
+// resolves or creates work units to resolve the sub-objects, using the
+// selections that PrepareQuery computed for each possible type.
+func resolveInterfaceBatch(ctx context.Context, sources []interface{}, typ *Interface, selectionSet *SelectionSet, destinations []*outputNode) ([]*WorkUnit, error) {
+	if selectionSet.possibleTypes == nil {
+		return nil, fmt.Errorf("selections on interface %s were not prepared", typ.Name)
+	}
+
+	sourcesByType := make(map[string][]interface{}, len(typ.Types))
+	destinationsByType := make(map[string][]*outputNode, len(typ.Types))
+	for idx, src := range sources {
+		value := reflect.ValueOf(src)
+		if !value.IsValid() || (value.Kind() == reflect.Ptr && value.IsNil()) {
+			destinations[idx].Fill(nil)
+			continue
+		}
+
+		srcType, err := typ.ResolveType(src)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := typ.Types[srcType]; !ok {
+			return nil, fmt.Errorf("interface %s resolved to unknown type %s", typ.Name, srcType)
+		}
+		sourcesByType[srcType] = append(sourcesByType[srcType], src)
+		destinationsByType[srcType] = append(destinationsByType[srcType], destinations[idx])
+	}
+
+	var workUnits []*WorkUnit
+	for srcType, sources := range sourcesByType {
+		units, err := resolveObjectBatch(ctx, sources, typ.Types[srcType], selectionSet.possibleTypes[srcType], destinationsByType[srcType])
+		if err != nil {
+			return nil, err
+		}
+		workUnits = append(workUnits, units...)
+	}
+	return workUnits, nil
+}
+
 // Traverses the object selections and resolves or creates work units to resolve
 // all of the object fields for every source passed in.
 func resolveObjectBatch(ctx context.Context, sources []interface{}, typ *Object, selectionSet *SelectionSet, destinations []*outputNode) ([]*WorkUnit, error) {
@@ -483,14 +659,14 @@ func resolveObjectBatch(ctx context.Context, sources []interface{}, typ *Object,
 			continue
 		}
 
+		field := typ.Fields[selection.Name]
 		destForSelection := make([]*outputNode, 0, len(nonNilDestinations))
 		for idx, destMap := range nonNilDestinations {
-			filler := newOutputNode(originDestinations[idx], selection.Alias)
+			filler := newOutputNode(originDestinations[idx], selection.Alias, isNullable(field.Type))
 			destForSelection = append(destForSelection, filler)
 			destMap[selection.Alias] = filler
 		}
 
-		field := typ.Fields[selection.Name]
 		unit := &WorkUnit{
 			Ctx:          ctx,
 			field:        field,
@@ -536,7 +712,7 @@ func resolveObjectBatch(ctx context.Context, sources []interface{}, typ *Object,
 	if typ.KeyField != nil {
 		destForSelection := make([]*outputNode, 0, len(nonNilDestinations))
 		for idx, destMap := range nonNilDestinations {
-			filler := newOutputNode(originDestinations[idx], "__key")
+			filler := newOutputNode(originDestinations[idx], "__key", isNullable(typ.KeyField.Type))
 			destForSelection = append(destForSelection, filler)
 			destMap["__key"] = filler
 		}
@@ -442,3 +442,96 @@ func (q *counterGoroutineScheduler) runEnqueue(resolver graphql.UnitResolver, un
 		}(unit)
 	}
 }
+
+func TestPartialResults(t *testing.T) {
+	type Object struct {
+		Key string
+	}
+
+	builder := schemabuilder.NewSchema()
+	query := builder.Query()
+	query.FieldFunc("objects", func(ctx context.Context) []*Object {
+		return []*Object{{Key: "key1"}, {Key: "key2"}}
+	})
+	query.FieldFunc("broken", func(ctx context.Context) (string, error) {
+		return "", errors.New("broken")
+	})
+	obj := builder.Object("Object", Object{})
+	obj.FieldFunc("value", func(object *Object) (*Object, error) {
+		if object.Key == "key2" {
+			return nil, errors.New("bad value")
+		}
+		return object, nil
+	})
+	obj.FieldFunc("name", func(object *Object) (string, error) {
+		if object.Key == "key2" {
+			return "", errors.New("bad name")
+		}
+		return object.Key, nil
+	})
+	schema := builder.MustBuild()
+
+	tests := []struct {
+		name           string
+		query          string
+		wantResultJSON string
+		wantErrors     []string
+	}{
+		{
+			name: "nullable field is null",
+			query: `{
+				objects {
+					key
+					value { key }
+				}
+			}`,
+			wantResultJSON: `{"objects": [
+				{"key": "key1", "value": {"key": "key1"}},
+				{"key": "key2", "value": null}
+			]}`,
+			wantErrors: []string{"objects.1.value: bad value"},
+		},
+		{
+			name: "non-null field nulls its parent",
+			query: `{
+				objects {
+					key
+					name
+				}
+			}`,
+			wantResultJSON: `{"objects": [
+				{"key": "key1", "name": "key1"},
+				null
+			]}`,
+			wantErrors: []string{"objects.1.name: bad name"},
+		},
+		{
+			name: "non-null top-level field nulls the result",
+			query: `{
+				objects { key }
+				broken
+			}`,
+			wantResultJSON: `null`,
+			wantErrors:     []string{"broken: broken"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := graphql.MustParse(tt.query, nil)
+			require.NoError(t, graphql.PrepareQuery(context.Background(), schema.Query, q.SelectionSet))
+
+			e := graphql.NewExecutor(graphql.NewImmediateGoroutineScheduler(), graphql.WithPartialResults())
+			res, err := e.Execute(context.Background(), schema.Query, nil, q)
+
+			partial, ok := err.(*graphql.PartialResultError)
+			require.True(t, ok, "expected partial result error, got %v", err)
+			var gotErrors []string
+			for _, err := range partial.Errors {
+				gotErrors = append(gotErrors, err.Error())
+			}
+			assert.Equal(t, tt.wantErrors, gotErrors)
+			assert.Equal(t, internal.ParseJSON(tt.wantResultJSON), internal.AsJSON(res))
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package graphql
+
+import (
+	"fmt"
+	"time"
+)
+
+// CacheScope is who may cache a response.
+type CacheScope string
+
+const (
+	// CacheScopePublic responses may be cached by shared caches, such as CDNs.
+	CacheScopePublic CacheScope = "public"
+	// CacheScopePrivate responses may only be cached by the client, because
+	// they depend on who made the request.
+	CacheScopePrivate CacheScope = "private"
+)
+
+// CacheHint describes how long the value of a field may be cached, and by
+// whom. An empty Scope is public.
+type CacheHint struct {
+	MaxAge time.Duration
+	Scope  CacheScope
+}
+
+// ComputeCacheHint computes the cache hint of the result of a selection set
+// on typ, which is the shortest MaxAge of the hints of all selected fields,
+// and private if any of them is private. Scalar and enum fields without a
+// hint inherit the hint of their parent. A query that selects a top-level
+// field or a field of an object, interface or union type without a hint is
+// not cacheable, and ComputeCacheHint returns false.
+//
+// The selection set must have been validated by PrepareQuery.
+func ComputeCacheHint(typ Type, selectionSet *SelectionSet) (CacheHint, bool) {
+	rollup := &cacheHintRollup{}
+	rollup.add(typ, selectionSet, true)
+	if rollup.uncacheable || !rollup.found || rollup.hint.MaxAge <= 0 {
+		return CacheHint{}, false
+	}
+	return rollup.hint, true
+}
+
+// cacheHintRollup accumulates the cache hints of the fields of a query.
+type cacheHintRollup struct {
+	hint        CacheHint
+	found       bool
+	uncacheable bool
+}
+
+func (r *cacheHintRollup) merge(hint CacheHint) {
+	if !r.found || hint.MaxAge < r.hint.MaxAge {
+		r.hint.MaxAge = hint.MaxAge
+	}
+	if !r.found || hint.Scope == CacheScopePrivate {
+		r.hint.Scope = hint.Scope
+	}
+	if r.hint.Scope == "" {
+		r.hint.Scope = CacheScopePublic
+	}
+	r.found = true
+}
+
+func (r *cacheHintRollup) add(typ Type, selectionSet *SelectionSet, root bool) {
+	if r.uncacheable || selectionSet == nil {
+		return
+	}
+
+	switch typ := typ.(type) {
+	case *Scalar, *Enum:
+
+	case *Object:
+		for _, selection := range selectionSet.Selections {
+			if selection.Name == "__typename" {
+				continue
+			}
+			field, ok := typ.Fields[selection.Name]
+			if !ok {
+				r.uncacheable = true
+				return
+			}
+			if field.CacheHint != nil {
+				r.merge(*field.CacheHint)
+			} else if root || isCompositeType(field.Type) {
+				r.uncacheable = true
+				return
+			}
+			r.add(field.Type, selection.SelectionSet, false)
+		}
+		for _, fragment := range selectionSet.Fragments {
+			r.add(typ, fragment.SelectionSet, root)
+		}
+
+	case *Interface:
+		for _, obj := range typ.Types {
+			r.add(obj, selectionSetForType(selectionSet, obj), root)
+		}
+
+	case *Union:
+		for _, obj := range typ.Types {
+			typed := &SelectionSet{Selections: selectionSet.Selections}
+			for _, fragment := range selectionSet.Fragments {
+				if matchesTypeCondition(obj, fragment.On) {
+					typed.Fragments = append(typed.Fragments, fragment)
+				}
+			}
+			r.add(obj, typed, root)
+		}
+
+	case *List:
+		r.add(typ.Type, selectionSet, root)
+
+	case *NonNull:
+		r.add(typ.Type, selectionSet, root)
+
+	default:
+		panic("unknown type kind")
+	}
+}
+
+// isCompositeType returns if values of typ have fields.
+func isCompositeType(typ Type) bool {
+	switch typ := typ.(type) {
+	case *Object, *Interface, *Union:
+		return true
+	case *List:
+		return isCompositeType(typ.Type)
+	case *NonNull:
+		return isCompositeType(typ.Type)
+	default:
+		return false
+	}
+}
+
+// cacheControlHeader formats hint as a Cache-Control header.
+func cacheControlHeader(hint CacheHint) string {
+	return fmt.Sprintf("max-age=%d, %s", int64(hint.MaxAge/time.Second), hint.Scope)
+}
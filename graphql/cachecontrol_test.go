@@ -0,0 +1,137 @@
+package graphql_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/samsarahq/thunder/graphql"
+	"github.com/samsarahq/thunder/graphql/schemabuilder"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type cacheHintOrg struct {
+	Name string
+}
+
+func cacheHintSchema() *graphql.Schema {
+	schema := schemabuilder.NewSchema()
+
+	query := schema.Query()
+	query.FieldFunc("org", func() *cacheHintOrg {
+		return &cacheHintOrg{Name: "org"}
+	}, schemabuilder.CacheHint(time.Hour, graphql.CacheScopePublic))
+	query.FieldFunc("flags", func() []string {
+		return []string{"a"}
+	}, schemabuilder.CacheHint(time.Minute, graphql.CacheScopePublic))
+	query.FieldFunc("me", func() string {
+		return "me"
+	}, schemabuilder.CacheHint(2*time.Hour, graphql.CacheScopePrivate))
+	query.FieldFunc("now", func() string {
+		return "now"
+	})
+
+	org := schema.Object("org", cacheHintOrg{})
+	org.FieldFunc("owner", func() *cacheHintOrg {
+		return &cacheHintOrg{Name: "owner"}
+	})
+	org.FieldFunc("motd", func() string {
+		return "hello"
+	}, schemabuilder.CacheHint(30*time.Second, ""))
+
+	return schema.MustBuild()
+}
+
+func TestComputeCacheHint(t *testing.T) {
+	schema := cacheHintSchema()
+
+	for _, tc := range []struct {
+		name      string
+		query     string
+		expected  graphql.CacheHint
+		cacheable bool
+	}{
+		{
+			"single field",
+			`{ org { name } }`,
+			graphql.CacheHint{MaxAge: time.Hour, Scope: graphql.CacheScopePublic},
+			true,
+		},
+		{
+			"shortest max age",
+			`{ org { name } flags }`,
+			graphql.CacheHint{MaxAge: time.Minute, Scope: graphql.CacheScopePublic},
+			true,
+		},
+		{
+			"nested hint",
+			`{ org { motd } }`,
+			graphql.CacheHint{MaxAge: 30 * time.Second, Scope: graphql.CacheScopePublic},
+			true,
+		},
+		{
+			"private",
+			`{ org { name } me }`,
+			graphql.CacheHint{MaxAge: time.Hour, Scope: graphql.CacheScopePrivate},
+			true,
+		},
+		{
+			"root field without hint",
+			`{ org { name } now }`,
+			graphql.CacheHint{},
+			false,
+		},
+		{
+			"object field without hint",
+			`{ org { owner { name } } }`,
+			graphql.CacheHint{},
+			false,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			q := graphql.MustParse(tc.query, nil)
+			require.NoError(t, graphql.PrepareQuery(context.Background(), schema.Query, q.SelectionSet))
+			hint, ok := graphql.ComputeCacheHint(schema.Query, q.SelectionSet)
+			assert.Equal(t, tc.cacheable, ok)
+			assert.Equal(t, tc.expected, hint)
+		})
+	}
+}
+
+func TestHTTPCacheControl(t *testing.T) {
+	handler := graphql.HTTPHandler(cacheHintSchema())
+
+	for _, tc := range []struct {
+		name     string
+		method   string
+		query    string
+		expected string
+	}{
+		{"query", "GET", "{ org { name } flags }", "max-age=60, public"},
+		{"private", "GET", "{ me }", "max-age=7200, private"},
+		{"uncacheable query", "GET", "{ now }", ""},
+		{"error", "GET", "{ org { nme } }", ""},
+		// Responses to POST requests are not cached.
+		{"post", "POST", `{"query": "{ org { name } flags }"}`, ""},
+		{"mutation", "POST", `{"query": "mutation { __typename }"}`, ""},
+		{"batch", "POST", `[{"query": "{ org { name } }"}, {"query": "{ me }"}]`, ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var req *http.Request
+			if tc.method == "GET" {
+				req = httptest.NewRequest("GET", "/graphql?query="+url.QueryEscape(tc.query), nil)
+			} else {
+				req = httptest.NewRequest("POST", "/graphql", strings.NewReader(tc.query))
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, tc.expected, rr.Header().Get("Cache-Control"))
+		})
+	}
+}
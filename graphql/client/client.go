@@ -0,0 +1,72 @@
+// Package client executes GraphQL operations against Thunder servers, either
+// over HTTP with graphql.HTTPHandler or over the websocket protocol of
+// graphql.Handler.
+//
+// A Conn, as opened by Dial, multiplexes live queries over a single
+// websocket, keeps their results up to date by merging the diffs the server
+// sends, and reconnects and resubscribes whenever the websocket fails.
+//
+// The package is used by the Go clients generated by package clientgen, but
+// can be used on its own with hand-written queries:
+//
+//	var result struct {
+//	  User struct {
+//	    Name string `json:"name"`
+//	  } `json:"user"`
+//	}
+//	c := &client.HTTPClient{URL: "https://example.com/graphql"}
+//	err := c.Execute(ctx, &client.Request{
+//	  Query:     "query User($id: int64!) { user(id: $id) { name } }",
+//	  Variables: map[string]interface{}{"id": 1},
+//	}, &result)
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/samsarahq/thunder/graphql"
+)
+
+// A Request is a GraphQL operation to execute.
+type Request struct {
+	Query         string `json:"query"`
+	OperationName string `json:"operationName,omitempty"`
+	// Variables are marshaled to a JSON object, and may be a map or a struct.
+	Variables interface{} `json:"variables,omitempty"`
+}
+
+// An Executor executes queries and mutations. HTTPClient and Conn are
+// Executors.
+type Executor interface {
+	// Execute executes request and unmarshals the data of its response into
+	// result. If the response has both data and errors, as when some fields
+	// failed, Execute unmarshals the data and returns the errors as Errors.
+	Execute(ctx context.Context, request *Request, result interface{}) error
+}
+
+// Errors are the errors of a GraphQL response.
+type Errors []graphql.ResponseError
+
+func (e Errors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// decodeResult unmarshals the data of a response into result, and returns the
+// errors of the response.
+func decodeResult(data json.RawMessage, errs Errors, result interface{}) error {
+	if len(data) > 0 && string(data) != "null" && result != nil {
+		if err := json.Unmarshal(data, result); err != nil {
+			return err
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
@@ -0,0 +1,706 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/samsarahq/thunder/graphql"
+	"github.com/samsarahq/thunder/merge"
+)
+
+var (
+	// ErrClosed is returned by operations on a Conn that has been closed.
+	ErrClosed = errors.New("connection closed")
+	// ErrNotConnected is returned by mutations sent while a Conn is waiting
+	// to reconnect.
+	ErrNotConnected = errors.New("not connected")
+	// ErrConnectionLost is returned by mutations whose connection was lost
+	// before their result arrived. The mutation may or may not have run.
+	ErrConnectionLost = errors.New("connection lost")
+)
+
+const (
+	DefaultReconnectDelay    = 10 * time.Second
+	DefaultMinReconnectDelay = time.Second
+	DefaultConnectTimeout    = 30 * time.Second
+	DefaultPingInterval      = 30 * time.Second
+	DefaultPingTimeout       = 30 * time.Second
+)
+
+// A ConnectFunc opens a new socket to a graphql.Handler.
+type ConnectFunc func(ctx context.Context) (graphql.JSONSocket, error)
+
+// Conn is a connection to a graphql.Handler over Thunder's websocket
+// protocol. Besides executing queries and mutations, a Conn runs live
+// queries, whose results the server updates whenever they change.
+//
+// A Conn created by Dial or Connect reconnects whenever its socket fails, and
+// subscribes to all of its live queries again once reconnected. Mutations
+// sent while the socket is down fail with ErrNotConnected.
+type Conn struct {
+	// connect opens a new socket after the current one fails, or is nil if
+	// the Conn does not reconnect.
+	connect           ConnectFunc
+	reconnectDelay    time.Duration
+	minReconnectDelay time.Duration
+	connectTimeout    time.Duration
+	pingInterval      time.Duration
+	pingTimeout       time.Duration
+
+	// ctx is canceled once the Conn is closed.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// writeMu serializes writes to the socket. It is held while registering
+	// a subscription and sending it, so that a subscription is sent exactly
+	// once on every socket.
+	writeMu sync.Mutex
+
+	mu sync.Mutex
+	// socket is the open socket, or nil while waiting to reconnect.
+	socket        graphql.JSONSocket
+	nextID        int
+	subscriptions map[string]*Subscription
+	// err is the reason the connection closed, once it has.
+	err error
+}
+
+// A ConnOption configures a Conn.
+type ConnOption func(*Conn)
+
+// WithReconnectDelay sets the delay before reconnecting after a socket that
+// never received a message fails, or after a failed reconnection attempt.
+// Sockets that did receive a message are reconnected after the shorter delay
+// set with WithMinReconnectDelay.
+func WithReconnectDelay(d time.Duration) ConnOption {
+	return func(c *Conn) {
+		c.reconnectDelay = d
+	}
+}
+
+// WithMinReconnectDelay sets the delay before reconnecting after a socket
+// that received a message fails. The delay keeps a server that accepts
+// sockets and then immediately drops them from being redialed in a loop.
+func WithMinReconnectDelay(d time.Duration) ConnOption {
+	return func(c *Conn) {
+		c.minReconnectDelay = d
+	}
+}
+
+// WithConnectTimeout sets the timeout for opening a socket when
+// reconnecting.
+func WithConnectTimeout(d time.Duration) ConnOption {
+	return func(c *Conn) {
+		c.connectTimeout = d
+	}
+}
+
+// WithPing sets the interval between pings sent to the server, and the
+// timeout in which the server must answer them before the socket is
+// considered broken. An interval of 0 disables pings.
+func WithPing(interval, timeout time.Duration) ConnOption {
+	return func(c *Conn) {
+		c.pingInterval = interval
+		c.pingTimeout = timeout
+	}
+}
+
+type outEnvelope struct {
+	ID      string      `json:"id"`
+	Type    string      `json:"type"`
+	Message interface{} `json:"message,omitempty"`
+}
+
+type inEnvelope struct {
+	ID      string          `json:"id"`
+	Type    string          `json:"type"`
+	Message json.RawMessage `json:"message"`
+	Errors  Errors          `json:"errors"`
+}
+
+// Dial connects to the graphql.Handler at url, such as
+// "wss://example.com/graphql". header is sent with every websocket
+// handshake.
+func Dial(ctx context.Context, url string, header http.Header, opts ...ConnOption) (*Conn, error) {
+	return Connect(ctx, func(ctx context.Context) (graphql.JSONSocket, error) {
+		dialer := &websocket.Dialer{
+			NetDial: func(network, addr string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, network, addr)
+			},
+		}
+		if deadline, ok := ctx.Deadline(); ok {
+			dialer.HandshakeTimeout = time.Until(deadline)
+		}
+		socket, _, err := dialer.Dial(url, header)
+		if err != nil {
+			return nil, err
+		}
+		return socket, nil
+	}, opts...)
+}
+
+// Connect opens a socket with connect, and returns a Conn that runs Thunder's
+// websocket protocol over it. The Conn calls connect again to reconnect
+// whenever the socket fails.
+func Connect(ctx context.Context, connect ConnectFunc, opts ...ConnOption) (*Conn, error) {
+	socket, err := connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c := newConn(connect, opts)
+	go c.run(socket)
+	return c, nil
+}
+
+// NewConn runs Thunder's websocket protocol over an open socket. The Conn
+// closes when the socket fails.
+func NewConn(socket graphql.JSONSocket, opts ...ConnOption) *Conn {
+	c := newConn(nil, opts)
+	go c.run(socket)
+	return c
+}
+
+func newConn(connect ConnectFunc, opts []ConnOption) *Conn {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &Conn{
+		connect:           connect,
+		reconnectDelay:    DefaultReconnectDelay,
+		minReconnectDelay: DefaultMinReconnectDelay,
+		connectTimeout:    DefaultConnectTimeout,
+		pingInterval:      DefaultPingInterval,
+		pingTimeout:       DefaultPingTimeout,
+		ctx:               ctx,
+		cancel:            cancel,
+		subscriptions:     make(map[string]*Subscription),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Close closes the connection, ending all of its subscriptions.
+func (c *Conn) Close() error {
+	c.mu.Lock()
+	socket := c.socket
+	c.mu.Unlock()
+
+	c.closeWithError(ErrClosed)
+	if socket == nil {
+		return nil
+	}
+	return socket.Close()
+}
+
+func (c *Conn) closeWithError(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err != nil {
+		return
+	}
+	c.err = err
+	c.cancel()
+	for id, subscription := range c.subscriptions {
+		subscription.finish(err)
+		delete(c.subscriptions, id)
+	}
+}
+
+// run serves socket, and every socket that replaces it, until the Conn
+// closes.
+func (c *Conn) run(socket graphql.JSONSocket) {
+	for {
+		if !c.attach(socket) {
+			socket.Close()
+			return
+		}
+		hadMessage := c.serve(socket)
+		socket.Close()
+
+		if c.connect == nil {
+			c.closeWithError(ErrClosed)
+			return
+		}
+		c.detach()
+
+		if socket = c.reconnect(hadMessage); socket == nil {
+			return
+		}
+	}
+}
+
+// attach makes socket the Conn's socket, and subscribes to all live queries
+// on it. attach returns false if the Conn has closed.
+func (c *Conn) attach(socket graphql.JSONSocket) bool {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return false
+	}
+	c.socket = socket
+	subscriptions := make([]*Subscription, 0, len(c.subscriptions))
+	for _, subscription := range c.subscriptions {
+		subscriptions = append(subscriptions, subscription)
+	}
+	c.mu.Unlock()
+
+	for _, subscription := range subscriptions {
+		subscription.resubscribe()
+		if err := socket.WriteJSON(outEnvelope{ID: subscription.id, Type: "subscribe", Message: subscription.request}); err != nil {
+			// serve will notice the broken socket and reconnect.
+			break
+		}
+	}
+	return true
+}
+
+// detach forgets the failed socket, and fails all mutations that were
+// waiting on it.
+func (c *Conn) detach() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.socket = nil
+	for id, subscription := range c.subscriptions {
+		if subscription.mutation {
+			subscription.finish(ErrConnectionLost)
+			delete(c.subscriptions, id)
+		}
+	}
+}
+
+// reconnect opens a new socket, retrying until it succeeds or the Conn
+// closes. reconnect returns nil if the Conn closed.
+func (c *Conn) reconnect(hadMessage bool) graphql.JSONSocket {
+	delay := c.reconnectDelay
+	if hadMessage {
+		delay = c.minReconnectDelay
+	}
+	for {
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-c.ctx.Done():
+			timer.Stop()
+			return nil
+		}
+
+		ctx, cancel := context.WithTimeout(c.ctx, c.connectTimeout)
+		socket, err := c.connect(ctx)
+		cancel()
+		if err == nil {
+			return socket
+		}
+		delay = c.reconnectDelay
+	}
+}
+
+// serve reads messages from socket until it fails, and reports whether any
+// message arrived.
+func (c *Conn) serve(socket graphql.JSONSocket) bool {
+	pong := make(chan struct{}, 1)
+	stop := make(chan struct{})
+	defer close(stop)
+	if c.pingInterval > 0 {
+		go c.ping(socket, pong, stop)
+	}
+
+	hadMessage := false
+	for {
+		var envelope inEnvelope
+		if err := socket.ReadJSON(&envelope); err != nil {
+			return hadMessage
+		}
+		hadMessage = true
+
+		if envelope.Type == "echo" {
+			select {
+			case pong <- struct{}{}:
+			default:
+			}
+			continue
+		}
+		c.handle(&envelope)
+	}
+}
+
+// ping sends an echo message every pingInterval, and closes socket if the
+// server does not answer it within pingTimeout.
+func (c *Conn) ping(socket graphql.JSONSocket, pong <-chan struct{}, stop <-chan struct{}) {
+	for {
+		interval := time.NewTimer(c.pingInterval)
+		select {
+		case <-interval.C:
+		case <-stop:
+			interval.Stop()
+			return
+		}
+
+		c.writeMu.Lock()
+		err := socket.WriteJSON(outEnvelope{Type: "echo"})
+		c.writeMu.Unlock()
+		if err != nil {
+			socket.Close()
+			return
+		}
+
+		timeout := time.NewTimer(c.pingTimeout)
+		select {
+		case <-pong:
+			timeout.Stop()
+		case <-timeout.C:
+			socket.Close()
+			return
+		case <-stop:
+			timeout.Stop()
+			return
+		}
+	}
+}
+
+func (c *Conn) handle(envelope *inEnvelope) {
+	c.mu.Lock()
+	subscription, ok := c.subscriptions[envelope.ID]
+	switch envelope.Type {
+	case "result", "error", "complete":
+		delete(c.subscriptions, envelope.ID)
+	}
+	c.mu.Unlock()
+	if !ok {
+		// The subscription has been closed already.
+		return
+	}
+
+	switch envelope.Type {
+	case "update":
+		subscription.update(envelope.Message, envelope.Errors)
+	case "result":
+		subscription.update(envelope.Message, envelope.Errors)
+		subscription.finish(io.EOF)
+	case "error":
+		subscription.finish(envelope.Errors)
+	case "complete":
+		subscription.finish(io.EOF)
+	}
+}
+
+// write sends envelope on the open socket. Messages for a socket that has
+// failed are dropped, as the server forgets its subscriptions along with it.
+func (c *Conn) write(envelope outEnvelope) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	c.mu.Lock()
+	socket := c.socket
+	c.mu.Unlock()
+	if socket == nil {
+		return nil
+	}
+	return socket.WriteJSON(envelope)
+}
+
+// start registers a new subscription and sends its first message.
+func (c *Conn) start(messageType string, request *Request) (*Subscription, error) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	mutation := messageType == "mutate"
+
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return nil, c.err
+	}
+	socket := c.socket
+	if socket == nil && mutation {
+		c.mu.Unlock()
+		return nil, ErrNotConnected
+	}
+	id := strconv.Itoa(c.nextID)
+	c.nextID++
+	subscription := &Subscription{
+		conn:     c,
+		id:       id,
+		request:  request,
+		mutation: mutation,
+		updated:  make(chan struct{}, 1),
+		stop:     make(chan struct{}),
+	}
+	c.subscriptions[id] = subscription
+	c.mu.Unlock()
+
+	if socket == nil {
+		// The subscription is sent once the Conn reconnects.
+		return subscription, nil
+	}
+	if err := socket.WriteJSON(outEnvelope{ID: id, Type: messageType, Message: request}); err != nil {
+		if mutation || c.connect == nil {
+			c.remove(id)
+			return nil, err
+		}
+		// The subscription is sent again once the Conn reconnects.
+	}
+	return subscription, nil
+}
+
+func (c *Conn) remove(id string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.subscriptions[id]; !ok {
+		return false
+	}
+	delete(c.subscriptions, id)
+	return true
+}
+
+// Subscribe starts a live query, or a subscription operation. The server
+// sends a new result whenever the result of a live query changes, and one for
+// every event of a subscription operation.
+func (c *Conn) Subscribe(ctx context.Context, request *Request) (*Subscription, error) {
+	return c.start("subscribe", request)
+}
+
+// Execute implements Executor. Mutations are sent as mutations, and queries
+// are subscribed to until their first result arrives.
+func (c *Conn) Execute(ctx context.Context, request *Request, result interface{}) error {
+	kind, err := operationKind(request)
+	if err != nil {
+		return err
+	}
+
+	var subscription *Subscription
+	switch kind {
+	case "query":
+		subscription, err = c.start("subscribe", request)
+	case "mutation":
+		subscription, err = c.start("mutate", request)
+	default:
+		return fmt.Errorf("cannot execute a %s, use Subscribe instead", kind)
+	}
+	if err != nil {
+		return err
+	}
+	defer subscription.Close()
+	return subscription.Next(ctx, result)
+}
+
+// operationKind returns whether request is a query, mutation or
+// subscription.
+func operationKind(request *Request) (string, error) {
+	document, err := parser.Parse(parser.ParseParams{Source: request.Query})
+	if err != nil {
+		return "", err
+	}
+	for _, definition := range document.Definitions {
+		operation, ok := definition.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if request.OperationName == "" || (operation.Name != nil && operation.Name.Value == request.OperationName) {
+			return operation.Operation, nil
+		}
+	}
+	return "", fmt.Errorf("unknown operation %q", request.OperationName)
+}
+
+// A Subscription is a live query, subscription operation or mutation running
+// on a Conn.
+type Subscription struct {
+	conn     *Conn
+	id       string
+	request  *Request
+	mutation bool
+
+	mu sync.Mutex
+	// value is the current result, built by merging the diffs the server
+	// sends.
+	value interface{}
+	errs  Errors
+	// changed is set when value has changed since it was last returned by
+	// Next.
+	changed bool
+	// reset is set when the subscription has been sent again after
+	// reconnecting, and the next update starts over from an empty value.
+	reset bool
+	// err is the reason the subscription ended, once it has.
+	err     error
+	updated chan struct{}
+	// stop is closed when the subscription is closed, to stop sending on
+	// updates.
+	stop        chan struct{}
+	updatesOnce sync.Once
+	updates     chan Update
+}
+
+// An Update is a result of a Subscription, as sent by Updates.
+type Update struct {
+	// Data is the JSON data of the result.
+	Data json.RawMessage
+	// Errors are the errors of the result, if some fields failed.
+	Errors Errors
+}
+
+func (s *Subscription) update(message json.RawMessage, errs Errors) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var diff interface{}
+	if err := json.Unmarshal(message, &diff); err != nil {
+		s.fail(fmt.Errorf("bad update: %s", err))
+		return
+	}
+	if s.reset {
+		s.value, s.reset = nil, false
+	}
+	value, err := merge.Merge(s.value, diff)
+	if err != nil {
+		s.fail(fmt.Errorf("bad update: %s", err))
+		return
+	}
+	s.value, s.errs, s.changed = value, errs, true
+	s.notify()
+}
+
+func (s *Subscription) resubscribe() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reset = true
+}
+
+// finish ends the subscription with err.
+func (s *Subscription) finish(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fail(err)
+}
+
+// fail ends the subscription with err. s.mu must be held.
+func (s *Subscription) fail(err error) {
+	if s.err == nil {
+		s.err = err
+		if err == ErrClosed {
+			close(s.stop)
+		}
+		s.notify()
+	}
+}
+
+func (s *Subscription) notify() {
+	select {
+	case s.updated <- struct{}{}:
+	default:
+	}
+}
+
+// Next waits until the result of the subscription changes, and unmarshals
+// the new result into result. Results that arrive while Next is not being
+// called are coalesced, so Next always returns the latest result.
+//
+// If the result has errors, Next unmarshals it and returns the errors as
+// Errors. Once the subscription has ended, Next returns io.EOF if it
+// completed, the Errors that ended it, or ErrClosed if the Conn closed.
+func (s *Subscription) Next(ctx context.Context, result interface{}) error {
+	value, errs, err := s.wait(ctx)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return decodeResult(data, errs, result)
+}
+
+// wait waits until the result of the subscription changes and returns it, or
+// returns the reason the subscription ended.
+func (s *Subscription) wait(ctx context.Context) (interface{}, Errors, error) {
+	for {
+		s.mu.Lock()
+		if s.changed {
+			value, errs := s.value, s.errs
+			s.changed = false
+			s.mu.Unlock()
+			return value, errs, nil
+		}
+		if s.err != nil {
+			err := s.err
+			s.mu.Unlock()
+			return nil, nil, err
+		}
+		s.mu.Unlock()
+
+		select {
+		case <-s.updated:
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		}
+	}
+}
+
+// Updates returns a channel that receives the results of the subscription,
+// coalesced like those of Next. The channel is closed once the subscription
+// ends, after which Err reports why. Updates and Next must not be used
+// together on the same subscription.
+func (s *Subscription) Updates() <-chan Update {
+	s.updatesOnce.Do(func() {
+		s.updates = make(chan Update)
+		go s.sendUpdates()
+	})
+	return s.updates
+}
+
+func (s *Subscription) sendUpdates() {
+	defer close(s.updates)
+	for {
+		value, errs, err := s.wait(context.Background())
+		if err != nil {
+			return
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return
+		}
+		select {
+		case s.updates <- Update{Data: data, Errors: errs}:
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// Err returns the reason the subscription ended: nil if it completed or is
+// still running, the Errors that ended it, or ErrClosed if it or its Conn
+// was closed.
+func (s *Subscription) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err == io.EOF {
+		return nil
+	}
+	return s.err
+}
+
+// Close ends the subscription.
+func (s *Subscription) Close() error {
+	if !s.conn.remove(s.id) {
+		return nil
+	}
+	s.finish(ErrClosed)
+	return s.conn.write(outEnvelope{ID: s.id, Type: "unsubscribe"})
+}
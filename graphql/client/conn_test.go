@@ -0,0 +1,278 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/samsarahq/thunder/graphql"
+	"github.com/samsarahq/thunder/graphql/client"
+	"github.com/samsarahq/thunder/graphql/schemabuilder"
+	"github.com/samsarahq/thunder/reactive"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// counter is a live value served by the test schema.
+type counter struct {
+	mu       sync.Mutex
+	value    int64
+	resource *reactive.Resource
+}
+
+func (c *counter) set(value int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.value = value
+	c.resource.Strobe()
+}
+
+func (c *counter) schema() *graphql.Schema {
+	schema := schemabuilder.NewSchema()
+	schema.Query().FieldFunc("counter", func(ctx context.Context) int64 {
+		reactive.AddDependency(ctx, c.resource, nil)
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return c.value
+	})
+	schema.Mutation().FieldFunc("increment", func() int64 {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.value++
+		c.resource.Strobe()
+		return c.value
+	})
+	return schema.MustBuild()
+}
+
+// dialer opens sockets to a test server, and records them so tests can break
+// them.
+type dialer struct {
+	url string
+
+	mu      sync.Mutex
+	sockets []*websocket.Conn
+	// fail makes further dials fail, and is closed when the next one does.
+	fail chan struct{}
+}
+
+func (d *dialer) connect(ctx context.Context) (graphql.JSONSocket, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.fail != nil {
+		select {
+		case <-d.fail:
+		default:
+			close(d.fail)
+		}
+		return nil, errors.New("dial failed")
+	}
+	socket, _, err := websocket.DefaultDialer.Dial(d.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	d.sockets = append(d.sockets, socket)
+	return socket, nil
+}
+
+// breakSocket closes the last socket opened by d.
+func (d *dialer) breakSocket() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.sockets[len(d.sockets)-1].Close()
+}
+
+func (d *dialer) dials() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.sockets)
+}
+
+func newTestConn(t *testing.T, c *counter, opts ...client.ConnOption) (*client.Conn, *dialer, func()) {
+	server := httptest.NewServer(graphql.Handler(c.schema()))
+	d := &dialer{url: "ws" + strings.TrimPrefix(server.URL, "http")}
+	opts = append([]client.ConnOption{client.WithReconnectDelay(time.Hour), client.WithMinReconnectDelay(0)}, opts...)
+	conn, err := client.Connect(context.Background(), d.connect, opts...)
+	require.NoError(t, err)
+	return conn, d, func() {
+		conn.Close()
+		server.Close()
+	}
+}
+
+type counterResult struct {
+	Counter int64 `json:"counter"`
+}
+
+func TestConnReconnect(t *testing.T) {
+	c := &counter{resource: reactive.NewResource()}
+	conn, d, cleanup := newTestConn(t, c)
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	subscription, err := conn.Subscribe(ctx, &client.Request{Query: "{ counter }"})
+	require.NoError(t, err)
+	var result counterResult
+	require.NoError(t, subscription.Next(ctx, &result))
+	assert.Equal(t, int64(0), result.Counter)
+
+	// Since the socket received messages, the Conn reconnects after the
+	// minimum delay and subscribes to the live query again.
+	d.breakSocket()
+	c.set(5)
+	for result.Counter != 5 {
+		require.NoError(t, subscription.Next(ctx, &result))
+	}
+	assert.Equal(t, 2, d.dials())
+
+	// Updates on the new socket are merged into the new result.
+	var incremented struct {
+		Increment int64 `json:"increment"`
+	}
+	require.NoError(t, conn.Execute(ctx, &client.Request{Query: "mutation { increment }"}, &incremented))
+	assert.Equal(t, int64(6), incremented.Increment)
+	require.NoError(t, subscription.Next(ctx, &result))
+	assert.Equal(t, int64(6), result.Counter)
+}
+
+func TestConnMinReconnectDelay(t *testing.T) {
+	c := &counter{resource: reactive.NewResource()}
+	conn, d, cleanup := newTestConn(t, c, client.WithMinReconnectDelay(200*time.Millisecond))
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var result counterResult
+	require.NoError(t, conn.Execute(ctx, &client.Request{Query: "{ counter }"}, &result))
+
+	// Even though the socket received a message, the Conn waits before
+	// reconnecting.
+	d.breakSocket()
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 1, d.dials())
+
+	for d.dials() != 2 {
+		select {
+		case <-ctx.Done():
+			t.Fatal("did not reconnect")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestConnNotConnected(t *testing.T) {
+	c := &counter{resource: reactive.NewResource()}
+	conn, d, cleanup := newTestConn(t, c)
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var result counterResult
+	require.NoError(t, conn.Execute(ctx, &client.Request{Query: "{ counter }"}, &result))
+
+	failed := make(chan struct{})
+	d.mu.Lock()
+	d.fail = failed
+	d.mu.Unlock()
+	d.breakSocket()
+	<-failed
+
+	// Mutations fail while the Conn waits to reconnect, but live queries wait
+	// for the Conn to reconnect.
+	err := conn.Execute(ctx, &client.Request{Query: "mutation { increment }"}, nil)
+	assert.Equal(t, client.ErrNotConnected, err)
+
+	subscription, err := conn.Subscribe(ctx, &client.Request{Query: "{ counter }"})
+	require.NoError(t, err)
+	shortCtx, shortCancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer shortCancel()
+	assert.Equal(t, context.DeadlineExceeded, subscription.Next(shortCtx, &result))
+
+	require.NoError(t, conn.Close())
+	assert.Equal(t, client.ErrClosed, subscription.Next(ctx, &result))
+}
+
+func TestSubscriptionUpdates(t *testing.T) {
+	c := &counter{resource: reactive.NewResource()}
+	conn, _, cleanup := newTestConn(t, c)
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	subscription, err := conn.Subscribe(ctx, &client.Request{Query: "{ counter }"})
+	require.NoError(t, err)
+	updates := subscription.Updates()
+
+	for _, expected := range []string{`{"counter":0}`, `{"counter":1}`, `{"counter":2}`} {
+		select {
+		case update := <-updates:
+			assert.JSONEq(t, expected, string(update.Data))
+			assert.Nil(t, update.Errors)
+		case <-ctx.Done():
+			t.Fatal(ctx.Err())
+		}
+		if expected != `{"counter":2}` {
+			require.NoError(t, conn.Execute(ctx, &client.Request{Query: "mutation { increment }"}, nil))
+		}
+	}
+
+	require.NoError(t, subscription.Close())
+	for range updates {
+	}
+	assert.Equal(t, client.ErrClosed, subscription.Err())
+}
+
+// silentSocket is a graphql.JSONSocket to a server that never answers.
+type silentSocket struct {
+	written chan string
+	closed  chan struct{}
+	once    sync.Once
+}
+
+func (s *silentSocket) ReadJSON(value interface{}) error {
+	<-s.closed
+	return io.EOF
+}
+
+func (s *silentSocket) WriteJSON(value interface{}) error {
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	s.written <- string(bytes)
+	return nil
+}
+
+func (s *silentSocket) Close() error {
+	s.once.Do(func() { close(s.closed) })
+	return nil
+}
+
+func TestConnPingTimeout(t *testing.T) {
+	socket := &silentSocket{written: make(chan string, 10), closed: make(chan struct{})}
+	conn := client.NewConn(socket, client.WithPing(10*time.Millisecond, 10*time.Millisecond))
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	subscription, err := conn.Subscribe(ctx, &client.Request{Query: "{ counter }"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":"0","type":"subscribe","message":{"query":"{ counter }"}}`, <-socket.written)
+	assert.JSONEq(t, `{"id":"","type":"echo"}`, <-socket.written)
+
+	// The unanswered ping closes the socket, which closes the Conn.
+	assert.Equal(t, client.ErrClosed, subscription.Next(ctx, nil))
+}
@@ -0,0 +1,67 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+// HTTPClient executes queries and mutations by POSTing them to a
+// graphql.HTTPHandler.
+type HTTPClient struct {
+	URL string
+	// Client sends the requests, or http.DefaultClient if nil.
+	Client *http.Client
+	// Header is added to every request, such as for authentication.
+	Header http.Header
+}
+
+type httpResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors Errors          `json:"errors"`
+}
+
+// Execute implements Executor.
+func (c *HTTPClient) Execute(ctx context.Context, request *Request, result interface{}) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", c.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	for key, values := range c.Header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	httpClient := c.Client
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	responseBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s: %s", resp.Status, bytes.TrimSpace(responseBody))
+	}
+
+	var response httpResponse
+	if err := json.Unmarshal(responseBody, &response); err != nil {
+		return fmt.Errorf("bad response: %s", err)
+	}
+	return decodeResult(response.Data, response.Errors, result)
+}
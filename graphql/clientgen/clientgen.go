@@ -0,0 +1,746 @@
+// Package clientgen generates typed Go clients for the operations of a
+// Thunder schema.
+//
+// Given a schema, such as one loaded from introspection output with
+// sdl.ParseIntrospection, and documents of named operations, Generate emits
+// for every operation:
+//
+//   - a <Name>Document constant holding the operation and the fragments it
+//     uses,
+//   - a <Name>Variables struct, if the operation has variables,
+//   - a <Name>Result struct, with nested structs for its selections,
+//   - for queries and mutations, a <Name> function executing the operation
+//     with a client.Executor, such as a client.HTTPClient,
+//   - for queries and subscriptions, a Subscribe<Name> function running the
+//     operation as a live query or subscription on a client.Conn.
+//
+// Enums and input objects used by the operations are emitted as named types.
+// Thunder's scalars map to the Go types they are built from, and unknown
+// scalars to json.RawMessage.
+package clientgen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/samsarahq/thunder/graphql"
+	"github.com/samsarahq/thunder/graphql/sdl"
+)
+
+// A Source is a document of GraphQL operations and fragments.
+type Source struct {
+	// Name identifies the document in errors, such as its file name.
+	Name string
+	Body string
+}
+
+// scalarTypes maps the scalars of Thunder and of the GraphQL specification to
+// Go types.
+var scalarTypes = map[string]string{
+	"bool":    "bool",
+	"int":     "int",
+	"int8":    "int8",
+	"int16":   "int16",
+	"int32":   "int32",
+	"int64":   "int64",
+	"uint":    "uint",
+	"uint8":   "uint8",
+	"uint16":  "uint16",
+	"uint32":  "uint32",
+	"uint64":  "uint64",
+	"float32": "float32",
+	"float64": "float64",
+	"string":  "string",
+	"Time":    "time.Time",
+	"bytes":   "[]byte",
+	"Boolean": "bool",
+	"Int":     "int32",
+	"Float":   "float64",
+	"String":  "string",
+	"ID":      "string",
+}
+
+type operation struct {
+	definition *ast.OperationDefinition
+	source     *Source
+}
+
+type fragment struct {
+	definition *ast.FragmentDefinition
+	source     *Source
+}
+
+type generator struct {
+	schema    *graphql.Schema
+	types     map[string]graphql.Type
+	fragments map[string]*fragment
+
+	// names are the Go types declared so far.
+	names map[string]bool
+	// decls are the declarations of the types of the current operation.
+	decls bytes.Buffer
+	// namedTypes are the declarations of enums and input objects by Go name.
+	namedTypes map[string]string
+	imports    map[string]bool
+}
+
+// Generate returns the Go source of a client for the operations in sources,
+// in package packageName. Every operation must be named, and fragments may be
+// shared between sources.
+func Generate(schema *graphql.Schema, packageName string, sources ...Source) ([]byte, error) {
+	g := &generator{
+		schema:     schema,
+		types:      sdl.CollectTypes(schema),
+		fragments:  make(map[string]*fragment),
+		names:      make(map[string]bool),
+		namedTypes: make(map[string]string),
+		imports:    map[string]bool{"context": true, "github.com/samsarahq/thunder/graphql/client": true},
+	}
+
+	operations := make(map[string]*operation)
+	for i := range sources {
+		source := &sources[i]
+		document, err := parser.Parse(parser.ParseParams{Source: source.Body})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", source.Name, err)
+		}
+		for _, definition := range document.Definitions {
+			switch definition := definition.(type) {
+			case *ast.OperationDefinition:
+				if definition.Name == nil {
+					return nil, fmt.Errorf("%s: operations must be named", source.Name)
+				}
+				name := definition.Name.Value
+				if _, ok := operations[name]; ok {
+					return nil, fmt.Errorf("%s: duplicate operation %s", source.Name, name)
+				}
+				operations[name] = &operation{definition: definition, source: source}
+			case *ast.FragmentDefinition:
+				name := definition.Name.Value
+				if _, ok := g.fragments[name]; ok {
+					return nil, fmt.Errorf("%s: duplicate fragment %s", source.Name, name)
+				}
+				g.fragments[name] = &fragment{definition: definition, source: source}
+			default:
+				return nil, fmt.Errorf("%s: unsupported definition", source.Name)
+			}
+		}
+	}
+
+	names := make([]string, 0, len(operations))
+	for name := range operations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var body bytes.Buffer
+	for _, name := range names {
+		if err := g.operation(&body, name, operations[name]); err != nil {
+			return nil, fmt.Errorf("%s: operation %s: %s", operations[name].source.Name, name, err)
+		}
+	}
+
+	typeNames := make([]string, 0, len(g.namedTypes))
+	for name := range g.namedTypes {
+		typeNames = append(typeNames, name)
+	}
+	sort.Strings(typeNames)
+	for _, name := range typeNames {
+		body.WriteString(g.namedTypes[name])
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by thunder client generate. DO NOT EDIT.\n\npackage %s\n\nimport (\n", packageName)
+	imports := make([]string, 0, len(g.imports))
+	for path := range g.imports {
+		imports = append(imports, path)
+	}
+	sort.Strings(imports)
+	// Group the imports of the standard library before the others.
+	sort.SliceStable(imports, func(i, j int) bool {
+		return !strings.Contains(imports[i], ".") && strings.Contains(imports[j], ".")
+	})
+	for i, path := range imports {
+		if i > 0 && strings.Contains(path, ".") && !strings.Contains(imports[i-1], ".") {
+			out.WriteString("\n")
+		}
+		fmt.Fprintf(&out, "\t%q\n", path)
+	}
+	out.WriteString(")\n")
+	out.Write(body.Bytes())
+
+	formatted, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %s", err)
+	}
+	return formatted, nil
+}
+
+// declare reserves the Go type name name.
+func (g *generator) declare(name string) error {
+	if g.names[name] {
+		return fmt.Errorf("generated type %s is declared twice", name)
+	}
+	g.names[name] = true
+	return nil
+}
+
+func (g *generator) operation(w *bytes.Buffer, name string, op *operation) error {
+	definition := op.definition
+	var root graphql.Type
+	switch definition.Operation {
+	case "query":
+		root = g.schema.Query
+	case "mutation":
+		root = g.schema.Mutation
+	case "subscription":
+		root = g.schema.Subscription
+	}
+	if root == nil {
+		return fmt.Errorf("schema does not support %s operations", definition.Operation)
+	}
+
+	goName := exportedName(name)
+	g.decls.Reset()
+
+	document, err := g.document(op)
+	if err != nil {
+		return err
+	}
+	if err := g.declare(goName + "Document"); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "\n// %sDocument is the source of the %s %s.\nconst %sDocument = %s\n", goName, name, definition.Operation, goName, goString(document))
+
+	variablesType := ""
+	if len(definition.VariableDefinitions) > 0 {
+		variablesType = goName + "Variables"
+		if err := g.variables(variablesType, name, definition.VariableDefinitions); err != nil {
+			return err
+		}
+	}
+
+	resultType := goName + "Result"
+	if err := g.declare(resultType); err != nil {
+		return err
+	}
+	comment := fmt.Sprintf("%s is the result of the %s %s.", resultType, name, definition.Operation)
+	if err := g.structType(resultType, comment, root, []*ast.SelectionSet{definition.SelectionSet}); err != nil {
+		return err
+	}
+	w.Write(g.decls.Bytes())
+
+	variablesParam, variablesValue := "", "nil"
+	if variablesType != "" {
+		variablesParam, variablesValue = ", variables "+variablesType, "variables"
+	}
+	request := fmt.Sprintf("&client.Request{\n\t\tQuery: %sDocument,\n\t\tOperationName: %q,\n\t\tVariables: %s,\n\t}", goName, name, variablesValue)
+
+	if definition.Operation != "subscription" {
+		if err := g.declare(goName); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, `
+// %[1]s executes the %[2]s %[3]s. If some fields failed, it returns the
+// partial result along with client.Errors.
+func %[1]s(ctx context.Context, executor client.Executor%[4]s) (*%[5]s, error) {
+	var result %[5]s
+	if err := executor.Execute(ctx, %[6]s, &result); err != nil {
+		if _, ok := err.(client.Errors); ok {
+			return &result, err
+		}
+		return nil, err
+	}
+	return &result, nil
+}
+`, goName, name, definition.Operation, variablesParam, resultType, request)
+	}
+
+	if definition.Operation != "mutation" {
+		subscriptionType := goName + "Subscription"
+		if err := g.declare(subscriptionType); err != nil {
+			return err
+		}
+		if err := g.declare("Subscribe" + goName); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, `
+// %[1]s is a running %[2]s %[3]s.
+type %[1]s struct {
+	*client.Subscription
+}
+
+// Subscribe%[4]s runs the %[2]s %[3]s on conn, whose result is updated
+// as it changes.
+func Subscribe%[4]s(ctx context.Context, conn *client.Conn%[5]s) (*%[1]s, error) {
+	subscription, err := conn.Subscribe(ctx, %[6]s)
+	if err != nil {
+		return nil, err
+	}
+	return &%[1]s{Subscription: subscription}, nil
+}
+
+// Next waits for the next result of the %[3]s. If some fields failed, it
+// returns the partial result along with client.Errors.
+func (s *%[1]s) Next(ctx context.Context) (*%[7]s, error) {
+	var result %[7]s
+	if err := s.Subscription.Next(ctx, &result); err != nil {
+		if _, ok := err.(client.Errors); ok {
+			return &result, err
+		}
+		return nil, err
+	}
+	return &result, nil
+}
+`, subscriptionType, name, definition.Operation, goName, variablesParam, request, resultType)
+	}
+	return nil
+}
+
+// document returns the source of op, followed by the fragments it uses.
+func (g *generator) document(op *operation) (string, error) {
+	used := make(map[string]bool)
+	if err := g.collectFragments(op.definition.SelectionSet, used); err != nil {
+		return "", err
+	}
+	names := make([]string, 0, len(used))
+	for name := range used {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := []string{sourceOf(op.source, op.definition.Loc)}
+	for _, name := range names {
+		f := g.fragments[name]
+		parts = append(parts, sourceOf(f.source, f.definition.Loc))
+	}
+	return strings.Join(parts, "\n\n"), nil
+}
+
+func sourceOf(source *Source, loc *ast.Location) string {
+	return source.Body[loc.Start:loc.End]
+}
+
+func (g *generator) collectFragments(selectionSet *ast.SelectionSet, used map[string]bool) error {
+	if selectionSet == nil {
+		return nil
+	}
+	for _, selection := range selectionSet.Selections {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			if err := g.collectFragments(selection.SelectionSet, used); err != nil {
+				return err
+			}
+		case *ast.InlineFragment:
+			if err := g.collectFragments(selection.SelectionSet, used); err != nil {
+				return err
+			}
+		case *ast.FragmentSpread:
+			name := selection.Name.Value
+			if used[name] {
+				continue
+			}
+			f, ok := g.fragments[name]
+			if !ok {
+				return fmt.Errorf("unknown fragment %s", name)
+			}
+			used[name] = true
+			if err := g.collectFragments(f.definition.SelectionSet, used); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (g *generator) variables(name string, operationName string, definitions []*ast.VariableDefinition) error {
+	if err := g.declare(name); err != nil {
+		return err
+	}
+	var fields []string
+	for _, definition := range definitions {
+		variable := definition.Variable.Name.Value
+		typ, err := g.astType(definition.Type)
+		if err != nil {
+			return fmt.Errorf("variable $%s: %s", variable, err)
+		}
+		goType, err := g.inputType(typ, false)
+		if err != nil {
+			return fmt.Errorf("variable $%s: %s", variable, err)
+		}
+		fields = append(fields, structField(variable, goType, !isNonNull(typ)))
+	}
+	fmt.Fprintf(&g.decls, "\n// %s are the variables of the %s operation.\ntype %s struct {\n%s}\n", name, operationName, name, strings.Join(fields, ""))
+	return nil
+}
+
+// astType resolves a type reference of a variable definition.
+func (g *generator) astType(typ ast.Type) (graphql.Type, error) {
+	switch typ := typ.(type) {
+	case *ast.NonNull:
+		inner, err := g.astType(typ.Type)
+		if err != nil {
+			return nil, err
+		}
+		return &graphql.NonNull{Type: inner}, nil
+	case *ast.List:
+		inner, err := g.astType(typ.Type)
+		if err != nil {
+			return nil, err
+		}
+		return &graphql.List{Type: inner}, nil
+	case *ast.Named:
+		name := typ.Name.Value
+		if named, ok := g.types[name]; ok {
+			return named, nil
+		}
+		if _, ok := scalarTypes[name]; ok {
+			return &graphql.Scalar{Type: name}, nil
+		}
+		return nil, fmt.Errorf("unknown type %s", name)
+	default:
+		return nil, fmt.Errorf("unknown type %v", typ)
+	}
+}
+
+// inputType returns the Go type of an argument or variable of type typ.
+func (g *generator) inputType(typ graphql.Type, nonNull bool) (string, error) {
+	var base string
+	switch typ := typ.(type) {
+	case *graphql.NonNull:
+		return g.inputType(typ.Type, true)
+	case *graphql.List:
+		elem, err := g.inputType(typ.Type, false)
+		if err != nil {
+			return "", err
+		}
+		return "[]" + elem, nil
+	case *graphql.Scalar:
+		base = g.scalarType(typ.Type)
+	case *graphql.Enum:
+		base = g.enumType(typ)
+	case *graphql.InputObject:
+		name, err := g.inputObject(typ)
+		if err != nil {
+			return "", err
+		}
+		base = name
+	default:
+		return "", fmt.Errorf("%s is not an input type", typ)
+	}
+	return nullable(base, nonNull), nil
+}
+
+func (g *generator) inputObject(typ *graphql.InputObject) (string, error) {
+	name := exportedName(typ.Name)
+	if _, ok := g.namedTypes[name]; ok {
+		return name, nil
+	}
+	if err := g.declare(name); err != nil {
+		return "", err
+	}
+	// Reserve the name before generating fields, as input objects may
+	// reference themselves.
+	g.namedTypes[name] = ""
+
+	fieldNames := make([]string, 0, len(typ.InputFields))
+	for fieldName := range typ.InputFields {
+		fieldNames = append(fieldNames, fieldName)
+	}
+	sort.Strings(fieldNames)
+
+	var fields []string
+	for _, fieldName := range fieldNames {
+		fieldType := typ.InputFields[fieldName]
+		goType, err := g.inputType(fieldType, false)
+		if err != nil {
+			return "", fmt.Errorf("input field %s.%s: %s", typ.Name, fieldName, err)
+		}
+		fields = append(fields, structField(fieldName, goType, !isNonNull(fieldType)))
+	}
+	g.namedTypes[name] = fmt.Sprintf("\n// %s is the %s input object.\ntype %s struct {\n%s}\n", name, typ.Name, name, strings.Join(fields, ""))
+	return name, nil
+}
+
+func (g *generator) enumType(typ *graphql.Enum) string {
+	name := exportedName(typ.Type)
+	if _, ok := g.namedTypes[name]; ok {
+		return name
+	}
+	g.names[name] = true
+
+	values := append([]string(nil), typ.Values...)
+	sort.Strings(values)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n// %s is the %s enum.\ntype %s string\n\nconst (\n", name, typ.Type, name)
+	for _, value := range values {
+		fmt.Fprintf(&b, "\t%s%s %s = %q\n", name, exportedName(value), name, value)
+	}
+	b.WriteString(")\n")
+	g.namedTypes[name] = b.String()
+	return name
+}
+
+func (g *generator) scalarType(name string) string {
+	goType, ok := scalarTypes[name]
+	if !ok {
+		g.imports["encoding/json"] = true
+		return "json.RawMessage"
+	}
+	if goType == "time.Time" {
+		g.imports["time"] = true
+	}
+	return goType
+}
+
+// A selectedField is a field of a selection set, along with all selections
+// of the same response key, whose selection sets are merged.
+type selectedField struct {
+	key   string
+	field *graphql.Field
+	// optional is set if the field is only selected for some types, or is
+	// conditionally skipped, in which case it is missing from some results.
+	optional      bool
+	selectionSets []*ast.SelectionSet
+}
+
+// structType declares a struct named name for the selection sets on typ,
+// documented by comment if it is set.
+func (g *generator) structType(name string, comment string, typ graphql.Type, selectionSets []*ast.SelectionSet) error {
+	var fields []*selectedField
+	byKey := make(map[string]*selectedField)
+	for _, selectionSet := range selectionSets {
+		if err := g.collectFields(typ, selectionSet, false, make(map[string]bool), &fields, byKey); err != nil {
+			return err
+		}
+	}
+
+	// Nested structs are declared while generating fields, and moved after
+	// their parent below.
+	start := g.decls.Len()
+	var structFields []string
+	for _, field := range fields {
+		fieldType := field.field.Type
+		if nonNull, ok := fieldType.(*graphql.NonNull); ok && field.optional {
+			fieldType = nonNull.Type
+		}
+		goType, err := g.outputType(fieldType, false, name+exportedName(field.key), field.selectionSets)
+		if err != nil {
+			return fmt.Errorf("field %s: %s", field.key, err)
+		}
+		structFields = append(structFields, structField(field.key, goType, false))
+	}
+
+	nested := append([]byte(nil), g.decls.Bytes()[start:]...)
+	g.decls.Truncate(start)
+	g.decls.WriteString("\n")
+	if comment != "" {
+		fmt.Fprintf(&g.decls, "// %s\n", comment)
+	}
+	fmt.Fprintf(&g.decls, "type %s struct {\n%s}\n", name, strings.Join(structFields, ""))
+	g.decls.Write(nested)
+	return nil
+}
+
+// collectFields collects the fields selected by selectionSet on typ, merging
+// fields with the same response key.
+func (g *generator) collectFields(typ graphql.Type, selectionSet *ast.SelectionSet, optional bool, visiting map[string]bool, fields *[]*selectedField, byKey map[string]*selectedField) error {
+	for _, selection := range selectionSet.Selections {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			name := selection.Name.Value
+			key := name
+			if selection.Alias != nil {
+				key = selection.Alias.Value
+			}
+
+			field, err := g.field(typ, name)
+			if err != nil {
+				return err
+			}
+			fieldOptional := optional || hasConditionalDirective(selection.Directives)
+
+			existing, ok := byKey[key]
+			if !ok {
+				existing = &selectedField{key: key, field: field, optional: fieldOptional}
+				byKey[key] = existing
+				*fields = append(*fields, existing)
+			} else if existing.field.Type.String() != field.Type.String() {
+				return fmt.Errorf("conflicting types for %s", key)
+			}
+			existing.optional = existing.optional && fieldOptional
+			if selection.SelectionSet != nil {
+				existing.selectionSets = append(existing.selectionSets, selection.SelectionSet)
+			}
+
+		case *ast.InlineFragment:
+			fragmentType, fragmentOptional, err := g.typeCondition(typ, selection.TypeCondition)
+			if err != nil {
+				return err
+			}
+			fragmentOptional = optional || fragmentOptional || hasConditionalDirective(selection.Directives)
+			if err := g.collectFields(fragmentType, selection.SelectionSet, fragmentOptional, visiting, fields, byKey); err != nil {
+				return err
+			}
+
+		case *ast.FragmentSpread:
+			name := selection.Name.Value
+			f, ok := g.fragments[name]
+			if !ok {
+				return fmt.Errorf("unknown fragment %s", name)
+			}
+			if visiting[name] {
+				return fmt.Errorf("fragment %s spreads itself", name)
+			}
+			fragmentType, fragmentOptional, err := g.typeCondition(typ, f.definition.TypeCondition)
+			if err != nil {
+				return fmt.Errorf("fragment %s: %s", name, err)
+			}
+			fragmentOptional = optional || fragmentOptional || hasConditionalDirective(selection.Directives)
+			visiting[name] = true
+			if err := g.collectFields(fragmentType, f.definition.SelectionSet, fragmentOptional, visiting, fields, byKey); err != nil {
+				return fmt.Errorf("fragment %s: %s", name, err)
+			}
+			delete(visiting, name)
+		}
+	}
+	return nil
+}
+
+// typeCondition resolves the type condition of a fragment on typ, and returns
+// whether the fragment only applies to some of the possible types of typ.
+func (g *generator) typeCondition(typ graphql.Type, condition *ast.Named) (graphql.Type, bool, error) {
+	if condition == nil || condition.Name.Value == typ.String() {
+		return typ, false, nil
+	}
+	conditionType, ok := g.types[condition.Name.Value]
+	if !ok {
+		return nil, false, fmt.Errorf("unknown type %s", condition.Name.Value)
+	}
+	return conditionType, true, nil
+}
+
+var typenameField = &graphql.Field{Type: &graphql.NonNull{Type: &graphql.Scalar{Type: "string"}}}
+
+// field returns the field name of typ.
+func (g *generator) field(typ graphql.Type, name string) (*graphql.Field, error) {
+	if name == "__typename" {
+		return typenameField, nil
+	}
+
+	var fields map[string]*graphql.Field
+	switch typ := typ.(type) {
+	case *graphql.Object:
+		fields = typ.Fields
+	case *graphql.Interface:
+		fields = typ.Fields
+	}
+	field, ok := fields[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown field %s on %s", name, typ)
+	}
+	return field, nil
+}
+
+// outputType returns the Go type of a field of type typ. name is the name of
+// the struct declared for selections on objects.
+func (g *generator) outputType(typ graphql.Type, nonNull bool, name string, selectionSets []*ast.SelectionSet) (string, error) {
+	var base string
+	switch typ := typ.(type) {
+	case *graphql.NonNull:
+		return g.outputType(typ.Type, true, name, selectionSets)
+	case *graphql.List:
+		elem, err := g.outputType(typ.Type, false, name, selectionSets)
+		if err != nil {
+			return "", err
+		}
+		return "[]" + elem, nil
+	case *graphql.Scalar:
+		base = g.scalarType(typ.Type)
+	case *graphql.Enum:
+		base = g.enumType(typ)
+	case *graphql.Object, *graphql.Interface, *graphql.Union:
+		if len(selectionSets) == 0 {
+			return "", fmt.Errorf("%s must have a selection set", typ)
+		}
+		if err := g.declare(name); err != nil {
+			return "", err
+		}
+		if err := g.structType(name, "", typ, selectionSets); err != nil {
+			return "", err
+		}
+		base = name
+	default:
+		return "", fmt.Errorf("%s is not an output type", typ)
+	}
+	return nullable(base, nonNull), nil
+}
+
+// nullable returns the Go type for a nullable value of goType, which is a
+// pointer unless goType can already be nil.
+func nullable(goType string, nonNull bool) string {
+	if nonNull || strings.HasPrefix(goType, "[]") || goType == "json.RawMessage" {
+		return goType
+	}
+	return "*" + goType
+}
+
+func isNonNull(typ graphql.Type) bool {
+	_, ok := typ.(*graphql.NonNull)
+	return ok
+}
+
+func hasConditionalDirective(directives []*ast.Directive) bool {
+	for _, directive := range directives {
+		switch directive.Name.Value {
+		case graphql.SKIP, graphql.INCLUDE:
+			return true
+		}
+	}
+	return false
+}
+
+func structField(name, goType string, omitEmpty bool) string {
+	tag := name
+	if omitEmpty {
+		tag += ",omitempty"
+	}
+	return fmt.Sprintf("\t%s %s `json:%q`\n", exportedName(name), goType, tag)
+}
+
+// exportedName converts a GraphQL name, such as "user_InputObject" or
+// "createdAt", to an exported Go name, such as "UserInputObject" or
+// "CreatedAt".
+func exportedName(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if r == '_' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	if b.Len() == 0 {
+		return "X"
+	}
+	return b.String()
+}
+
+func goString(s string) string {
+	if !strings.Contains(s, "`") {
+		return "`" + s + "`"
+	}
+	return fmt.Sprintf("%q", s)
+}
@@ -0,0 +1,272 @@
+package clientgen_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/samsarahq/thunder/graphql"
+	"github.com/samsarahq/thunder/graphql/client"
+	"github.com/samsarahq/thunder/graphql/clientgen"
+	"github.com/samsarahq/thunder/graphql/clientgen/internal/testclient"
+	"github.com/samsarahq/thunder/graphql/schemabuilder"
+	"github.com/samsarahq/thunder/graphql/sdl"
+	"github.com/samsarahq/thunder/reactive"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Role int32
+
+type User struct {
+	Id        int64
+	Name      string
+	Email     *string
+	Role      Role
+	CreatedAt time.Time
+}
+
+type Group struct {
+	Title string
+}
+
+type SearchResult struct {
+	schemabuilder.Union
+
+	*User
+	*Group
+}
+
+type UserFilter struct {
+	Role *Role
+}
+
+var createdAt = time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// server is the backend of the schema used by the tests.
+type server struct {
+	mu    sync.Mutex
+	users []*User
+	// resource invalidates live queries of users when they change.
+	resource *reactive.Resource
+}
+
+func (s *server) schema() *schemabuilder.Schema {
+	schema := schemabuilder.NewSchema()
+	schema.Enum(Role(0), map[string]Role{"admin": 0, "member": 1})
+	schema.Object("User", User{})
+	schema.Object("Group", Group{})
+
+	query := schema.Query()
+	query.FieldFunc("user", func(args struct{ Id int64 }) *User {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for _, user := range s.users {
+			if user.Id == args.Id {
+				copy := *user
+				return &copy
+			}
+		}
+		return nil
+	})
+	query.FieldFunc("users", func(ctx context.Context, args struct{ Filter *UserFilter }) []*User {
+		reactive.AddDependency(ctx, s.resource, nil)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		var users []*User
+		for _, user := range s.users {
+			if args.Filter == nil || args.Filter.Role == nil || *args.Filter.Role == user.Role {
+				copy := *user
+				users = append(users, &copy)
+			}
+		}
+		return users
+	})
+	query.FieldFunc("search", func(args struct{ Query string }) []*SearchResult {
+		return []*SearchResult{
+			{User: &User{Name: args.Query}},
+			{Group: &Group{Title: args.Query + "s"}},
+		}
+	})
+
+	schema.Mutation().FieldFunc("rename", func(args struct {
+		Id   int64
+		Name string
+	}) *User {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for _, user := range s.users {
+			if user.Id == args.Id {
+				user.Name = args.Name
+				s.resource.Strobe()
+				copy := *user
+				return &copy
+			}
+		}
+		return nil
+	})
+	return schema
+}
+
+func newServer() *server {
+	email := "alice@example.com"
+	return &server{
+		users: []*User{
+			{Id: 1, Name: "alice", Email: &email, Role: 0, CreatedAt: createdAt},
+			{Id: 2, Name: "bob", Role: 1, CreatedAt: createdAt},
+		},
+		resource: reactive.NewResource(),
+	}
+}
+
+// TestGenerate checks that the schema and client in internal/testclient are
+// up to date. Run go generate in internal/testclient after changing them.
+func TestGenerate(t *testing.T) {
+	schema := newServer().schema().MustBuild()
+
+	expectedSchema, err := ioutil.ReadFile("internal/testclient/schema.graphql")
+	require.NoError(t, err)
+	assert.Equal(t, string(expectedSchema), sdl.Print(schema))
+
+	operations, err := ioutil.ReadFile("internal/testclient/operations.graphql")
+	require.NoError(t, err)
+	code, err := clientgen.Generate(sdl.MustParse(string(expectedSchema)), "testclient", clientgen.Source{
+		Name: "operations.graphql",
+		Body: string(operations),
+	})
+	require.NoError(t, err)
+
+	expectedCode, err := ioutil.ReadFile("internal/testclient/client.go")
+	require.NoError(t, err)
+	assert.Equal(t, string(expectedCode), string(code))
+}
+
+func TestGenerateErrors(t *testing.T) {
+	schema := newServer().schema().MustBuild()
+	for _, testCase := range []struct {
+		name   string
+		source string
+		err    string
+	}{
+		{
+			name:   "anonymous operation",
+			source: "{ users { id } }",
+			err:    "test.graphql: operations must be named",
+		},
+		{
+			name:   "unknown field",
+			source: "query Q { users { age } }",
+			err:    "test.graphql: operation Q: field users: unknown field age on User",
+		},
+		{
+			name:   "missing selection set",
+			source: "query Q { users }",
+			err:    "test.graphql: operation Q: field users: User must have a selection set",
+		},
+		{
+			name:   "unknown variable type",
+			source: "query Q($id: Long!) { user(id: $id) { id } }",
+			err:    "test.graphql: operation Q: variable $id: unknown type Long",
+		},
+		{
+			name:   "unknown fragment",
+			source: "query Q { users { ...F } }",
+			err:    "test.graphql: operation Q: unknown fragment F",
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := clientgen.Generate(schema, "test", clientgen.Source{Name: "test.graphql", Body: testCase.source})
+			assert.EqualError(t, err, testCase.err)
+		})
+	}
+}
+
+func TestHTTPClient(t *testing.T) {
+	server := httptest.NewServer(graphql.HTTPHandler(newServer().schema().MustBuild()))
+	defer server.Close()
+
+	ctx := context.Background()
+	executor := &client.HTTPClient{URL: server.URL}
+
+	user, err := testclient.GetUser(ctx, executor, testclient.GetUserVariables{Id: 1})
+	require.NoError(t, err)
+	email := "alice@example.com"
+	assert.Equal(t, &testclient.GetUserResult{
+		User: &testclient.GetUserResultUser{
+			Id:        1,
+			Name:      "alice",
+			Email:     &email,
+			Role:      testclient.RoleAdmin,
+			CreatedAt: createdAt,
+		},
+	}, user)
+
+	member := testclient.RoleMember
+	users, err := testclient.ListUsers(ctx, executor, testclient.ListUsersVariables{
+		Filter: &testclient.UserFilterInputObject{Role: &member},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []*testclient.ListUsersResultUsers{{Id: 2, Name: "bob", Role: testclient.RoleMember}}, users.Users)
+
+	search, err := testclient.Search(ctx, executor, testclient.SearchVariables{Query: "admin"})
+	require.NoError(t, err)
+	admin, admins := "admin", "admins"
+	assert.Equal(t, []*testclient.SearchResultSearch{
+		{Typename: "User", Name: &admin},
+		{Typename: "Group", Title: &admins},
+	}, search.Search)
+
+	renamed, err := testclient.RenameUser(ctx, executor, testclient.RenameUserVariables{Id: 2, Name: "robert"})
+	require.NoError(t, err)
+	assert.Equal(t, &testclient.RenameUserResultRename{Id: 2, Name: "robert"}, renamed.Rename)
+}
+
+func TestConn(t *testing.T) {
+	server := httptest.NewServer(graphql.Handler(newServer().schema().MustBuild()))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	conn, err := client.Dial(ctx, "ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	user, err := testclient.GetUser(ctx, conn, testclient.GetUserVariables{Id: 2})
+	require.NoError(t, err)
+	assert.Equal(t, "bob", user.User.Name)
+	assert.Nil(t, user.User.Email)
+
+	subscription, err := testclient.SubscribeListUsers(ctx, conn, testclient.ListUsersVariables{})
+	require.NoError(t, err)
+	users, err := subscription.Next(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []*testclient.ListUsersResultUsers{
+		{Id: 1, Name: "alice", Role: testclient.RoleAdmin},
+		{Id: 2, Name: "bob", Role: testclient.RoleMember},
+	}, users.Users)
+
+	// The server sends a diff once the mutation invalidates the live query,
+	// which is merged into the previous result.
+	renamed, err := testclient.RenameUser(ctx, conn, testclient.RenameUserVariables{Id: 1, Name: "alicia"})
+	require.NoError(t, err)
+	assert.Equal(t, "alicia", renamed.Rename.Name)
+
+	users, err = subscription.Next(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []*testclient.ListUsersResultUsers{
+		{Id: 1, Name: "alicia", Role: testclient.RoleAdmin},
+		{Id: 2, Name: "bob", Role: testclient.RoleMember},
+	}, users.Users)
+
+	require.NoError(t, subscription.Close())
+	_, err = subscription.Next(ctx)
+	assert.Equal(t, client.ErrClosed, err)
+
+	user, err = testclient.GetUser(ctx, conn, testclient.GetUserVariables{Id: 3})
+	require.NoError(t, err)
+	assert.Nil(t, user.User)
+}
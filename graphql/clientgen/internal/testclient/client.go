@@ -0,0 +1,298 @@
+// Code generated by thunder client generate. DO NOT EDIT.
+
+package testclient
+
+import (
+	"context"
+	"time"
+
+	"github.com/samsarahq/thunder/graphql/client"
+)
+
+// GetUserDocument is the source of the GetUser query.
+const GetUserDocument = `query GetUser($id: int64!) {
+  user(id: $id) {
+    ...UserFields
+  }
+}
+
+fragment UserFields on User {
+  id
+  name
+  email
+  role
+  createdAt
+}`
+
+// GetUserVariables are the variables of the GetUser operation.
+type GetUserVariables struct {
+	Id int64 `json:"id"`
+}
+
+// GetUserResult is the result of the GetUser query.
+type GetUserResult struct {
+	User *GetUserResultUser `json:"user"`
+}
+
+type GetUserResultUser struct {
+	Id        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Email     *string   `json:"email"`
+	Role      Role      `json:"role"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// GetUser executes the GetUser query. If some fields failed, it returns the
+// partial result along with client.Errors.
+func GetUser(ctx context.Context, executor client.Executor, variables GetUserVariables) (*GetUserResult, error) {
+	var result GetUserResult
+	if err := executor.Execute(ctx, &client.Request{
+		Query:         GetUserDocument,
+		OperationName: "GetUser",
+		Variables:     variables,
+	}, &result); err != nil {
+		if _, ok := err.(client.Errors); ok {
+			return &result, err
+		}
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetUserSubscription is a running GetUser query.
+type GetUserSubscription struct {
+	*client.Subscription
+}
+
+// SubscribeGetUser runs the GetUser query on conn, whose result is updated
+// as it changes.
+func SubscribeGetUser(ctx context.Context, conn *client.Conn, variables GetUserVariables) (*GetUserSubscription, error) {
+	subscription, err := conn.Subscribe(ctx, &client.Request{
+		Query:         GetUserDocument,
+		OperationName: "GetUser",
+		Variables:     variables,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &GetUserSubscription{Subscription: subscription}, nil
+}
+
+// Next waits for the next result of the query. If some fields failed, it
+// returns the partial result along with client.Errors.
+func (s *GetUserSubscription) Next(ctx context.Context) (*GetUserResult, error) {
+	var result GetUserResult
+	if err := s.Subscription.Next(ctx, &result); err != nil {
+		if _, ok := err.(client.Errors); ok {
+			return &result, err
+		}
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListUsersDocument is the source of the ListUsers query.
+const ListUsersDocument = `query ListUsers($filter: UserFilter_InputObject) {
+  users(filter: $filter) {
+    id
+    name
+    role
+  }
+}`
+
+// ListUsersVariables are the variables of the ListUsers operation.
+type ListUsersVariables struct {
+	Filter *UserFilterInputObject `json:"filter,omitempty"`
+}
+
+// ListUsersResult is the result of the ListUsers query.
+type ListUsersResult struct {
+	Users []*ListUsersResultUsers `json:"users"`
+}
+
+type ListUsersResultUsers struct {
+	Id   int64  `json:"id"`
+	Name string `json:"name"`
+	Role Role   `json:"role"`
+}
+
+// ListUsers executes the ListUsers query. If some fields failed, it returns the
+// partial result along with client.Errors.
+func ListUsers(ctx context.Context, executor client.Executor, variables ListUsersVariables) (*ListUsersResult, error) {
+	var result ListUsersResult
+	if err := executor.Execute(ctx, &client.Request{
+		Query:         ListUsersDocument,
+		OperationName: "ListUsers",
+		Variables:     variables,
+	}, &result); err != nil {
+		if _, ok := err.(client.Errors); ok {
+			return &result, err
+		}
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListUsersSubscription is a running ListUsers query.
+type ListUsersSubscription struct {
+	*client.Subscription
+}
+
+// SubscribeListUsers runs the ListUsers query on conn, whose result is updated
+// as it changes.
+func SubscribeListUsers(ctx context.Context, conn *client.Conn, variables ListUsersVariables) (*ListUsersSubscription, error) {
+	subscription, err := conn.Subscribe(ctx, &client.Request{
+		Query:         ListUsersDocument,
+		OperationName: "ListUsers",
+		Variables:     variables,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &ListUsersSubscription{Subscription: subscription}, nil
+}
+
+// Next waits for the next result of the query. If some fields failed, it
+// returns the partial result along with client.Errors.
+func (s *ListUsersSubscription) Next(ctx context.Context) (*ListUsersResult, error) {
+	var result ListUsersResult
+	if err := s.Subscription.Next(ctx, &result); err != nil {
+		if _, ok := err.(client.Errors); ok {
+			return &result, err
+		}
+		return nil, err
+	}
+	return &result, nil
+}
+
+// RenameUserDocument is the source of the RenameUser mutation.
+const RenameUserDocument = `mutation RenameUser($id: int64!, $name: string!) {
+  rename(id: $id, name: $name) {
+    id
+    name
+  }
+}`
+
+// RenameUserVariables are the variables of the RenameUser operation.
+type RenameUserVariables struct {
+	Id   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// RenameUserResult is the result of the RenameUser mutation.
+type RenameUserResult struct {
+	Rename *RenameUserResultRename `json:"rename"`
+}
+
+type RenameUserResultRename struct {
+	Id   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// RenameUser executes the RenameUser mutation. If some fields failed, it returns the
+// partial result along with client.Errors.
+func RenameUser(ctx context.Context, executor client.Executor, variables RenameUserVariables) (*RenameUserResult, error) {
+	var result RenameUserResult
+	if err := executor.Execute(ctx, &client.Request{
+		Query:         RenameUserDocument,
+		OperationName: "RenameUser",
+		Variables:     variables,
+	}, &result); err != nil {
+		if _, ok := err.(client.Errors); ok {
+			return &result, err
+		}
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SearchDocument is the source of the Search query.
+const SearchDocument = `query Search($query: string!) {
+  search(query: $query) {
+    __typename
+    ... on User {
+      name
+    }
+    ... on Group {
+      title
+    }
+  }
+}`
+
+// SearchVariables are the variables of the Search operation.
+type SearchVariables struct {
+	Query string `json:"query"`
+}
+
+// SearchResult is the result of the Search query.
+type SearchResult struct {
+	Search []*SearchResultSearch `json:"search"`
+}
+
+type SearchResultSearch struct {
+	Typename string  `json:"__typename"`
+	Name     *string `json:"name"`
+	Title    *string `json:"title"`
+}
+
+// Search executes the Search query. If some fields failed, it returns the
+// partial result along with client.Errors.
+func Search(ctx context.Context, executor client.Executor, variables SearchVariables) (*SearchResult, error) {
+	var result SearchResult
+	if err := executor.Execute(ctx, &client.Request{
+		Query:         SearchDocument,
+		OperationName: "Search",
+		Variables:     variables,
+	}, &result); err != nil {
+		if _, ok := err.(client.Errors); ok {
+			return &result, err
+		}
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SearchSubscription is a running Search query.
+type SearchSubscription struct {
+	*client.Subscription
+}
+
+// SubscribeSearch runs the Search query on conn, whose result is updated
+// as it changes.
+func SubscribeSearch(ctx context.Context, conn *client.Conn, variables SearchVariables) (*SearchSubscription, error) {
+	subscription, err := conn.Subscribe(ctx, &client.Request{
+		Query:         SearchDocument,
+		OperationName: "Search",
+		Variables:     variables,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &SearchSubscription{Subscription: subscription}, nil
+}
+
+// Next waits for the next result of the query. If some fields failed, it
+// returns the partial result along with client.Errors.
+func (s *SearchSubscription) Next(ctx context.Context) (*SearchResult, error) {
+	var result SearchResult
+	if err := s.Subscription.Next(ctx, &result); err != nil {
+		if _, ok := err.(client.Errors); ok {
+			return &result, err
+		}
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Role is the Role enum.
+type Role string
+
+const (
+	RoleAdmin  Role = "admin"
+	RoleMember Role = "member"
+)
+
+// UserFilterInputObject is the UserFilter_InputObject input object.
+type UserFilterInputObject struct {
+	Role *Role `json:"role,omitempty"`
+}
@@ -0,0 +1,5 @@
+// Package testclient is a client generated by clientgen for the schema in
+// clientgen's tests.
+package testclient
+
+//go:generate go run ../../../../cmd/thunder client generate -schema schema.graphql -package testclient -o client.go operations.graphql
@@ -0,0 +1,189 @@
+package graphql
+
+import "math"
+
+// ComplexityLimits bounds how expensive a query may be, so that deeply nested
+// or heavily aliased queries are rejected before they are executed. A zero
+// limit is not enforced.
+type ComplexityLimits struct {
+	// MaxDepth is the maximum nesting of fields in a query. Top-level fields
+	// have depth 1.
+	MaxDepth int
+
+	// MaxAliases is the maximum number of aliased fields in a query.
+	MaxAliases int
+
+	// MaxCost is the maximum cost of a query. Every field costs its Field.Cost,
+	// and the cost of the selections of a field with a "first" or "last"
+	// argument is multiplied by that argument.
+	MaxCost int
+}
+
+// QueryComplexity describes how expensive a query is.
+type QueryComplexity struct {
+	Depth   int
+	Aliases int
+	Cost    int
+}
+
+// ComputeComplexity computes the complexity of a selection set on typ. For
+// interfaces and unions, the most expensive possible type is counted.
+//
+// The selection set must have been validated by PrepareQuery.
+func ComputeComplexity(typ Type, selectionSet *SelectionSet) QueryComplexity {
+	switch typ := typ.(type) {
+	case *Scalar, *Enum:
+		return QueryComplexity{}
+
+	case *Object:
+		if selectionSet == nil {
+			return QueryComplexity{}
+		}
+		var complexity QueryComplexity
+		for _, selection := range selectionSet.Selections {
+			if selection.Alias != selection.Name {
+				complexity.Aliases = saturatingAdd(complexity.Aliases, 1)
+			}
+			if selection.Name == "__typename" {
+				complexity.Depth = maxInt(complexity.Depth, 1)
+				continue
+			}
+
+			field, ok := typ.Fields[selection.Name]
+			if !ok {
+				continue
+			}
+			cost := field.Cost
+			if cost == 0 {
+				cost = 1
+			}
+
+			child := ComputeComplexity(field.Type, selection.SelectionSet)
+			complexity.Depth = maxInt(complexity.Depth, child.Depth+1)
+			complexity.Aliases = saturatingAdd(complexity.Aliases, child.Aliases)
+			complexity.Cost = saturatingAdd(complexity.Cost, saturatingAdd(cost, saturatingMul(selectionMultiplier(selection), child.Cost)))
+		}
+		for _, fragment := range selectionSet.Fragments {
+			child := ComputeComplexity(typ, fragment.SelectionSet)
+			complexity.Depth = maxInt(complexity.Depth, child.Depth)
+			complexity.Aliases = saturatingAdd(complexity.Aliases, child.Aliases)
+			complexity.Cost = saturatingAdd(complexity.Cost, child.Cost)
+		}
+		return complexity
+
+	case *Interface:
+		if selectionSet == nil {
+			return QueryComplexity{}
+		}
+		var complexity QueryComplexity
+		for _, obj := range typ.Types {
+			complexity = maxComplexity(complexity, ComputeComplexity(obj, selectionSetForType(selectionSet, obj)))
+		}
+		return complexity
+
+	case *Union:
+		if selectionSet == nil {
+			return QueryComplexity{}
+		}
+		var complexity QueryComplexity
+		for _, obj := range typ.Types {
+			typed := &SelectionSet{Selections: selectionSet.Selections}
+			for _, fragment := range selectionSet.Fragments {
+				if matchesTypeCondition(obj, fragment.On) {
+					typed.Fragments = append(typed.Fragments, fragment)
+				}
+			}
+			complexity = maxComplexity(complexity, ComputeComplexity(obj, typed))
+		}
+		return complexity
+
+	case *List:
+		return ComputeComplexity(typ.Type, selectionSet)
+
+	case *NonNull:
+		return ComputeComplexity(typ.Type, selectionSet)
+
+	default:
+		panic("unknown type kind")
+	}
+}
+
+// CheckComplexity returns a ClientError if a selection set on typ exceeds any
+// of limits.
+//
+// The selection set must have been validated by PrepareQuery.
+func CheckComplexity(typ Type, selectionSet *SelectionSet, limits ComplexityLimits) error {
+	if limits == (ComplexityLimits{}) {
+		return nil
+	}
+
+	complexity := ComputeComplexity(typ, selectionSet)
+	if limits.MaxDepth > 0 && complexity.Depth > limits.MaxDepth {
+		return NewClientError("query has depth %d, which exceeds the maximum depth of %d", complexity.Depth, limits.MaxDepth)
+	}
+	if limits.MaxAliases > 0 && complexity.Aliases > limits.MaxAliases {
+		return NewClientError("query has %d aliases, which exceeds the maximum of %d", complexity.Aliases, limits.MaxAliases)
+	}
+	if limits.MaxCost > 0 && complexity.Cost > limits.MaxCost {
+		return NewClientError("query has cost %d, which exceeds the maximum cost of %d", complexity.Cost, limits.MaxCost)
+	}
+	return nil
+}
+
+// selectionMultiplier returns the number of items a selection asks for with
+// its "first" or "last" argument, or 1 if it has neither.
+func selectionMultiplier(selection *Selection) int {
+	multiplier := 1
+	for _, name := range []string{"first", "last"} {
+		var n int
+		switch value := selection.UnparsedArgs[name].(type) {
+		case float64:
+			if value >= math.MaxInt32 {
+				n = math.MaxInt32
+			} else {
+				n = int(value)
+			}
+		case int:
+			n = value
+		case int64:
+			if value >= math.MaxInt32 {
+				n = math.MaxInt32
+			} else {
+				n = int(value)
+			}
+		}
+		multiplier = maxInt(multiplier, n)
+	}
+	return multiplier
+}
+
+func maxComplexity(a, b QueryComplexity) QueryComplexity {
+	return QueryComplexity{
+		Depth:   maxInt(a.Depth, b.Depth),
+		Aliases: maxInt(a.Aliases, b.Aliases),
+		Cost:    maxInt(a.Cost, b.Cost),
+	}
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// saturatingAdd and saturatingMul add and multiply non-negative ints, capping
+// the result at math.MaxInt32 so large multipliers cannot overflow the cost.
+func saturatingAdd(a, b int) int {
+	if a > math.MaxInt32-b {
+		return math.MaxInt32
+	}
+	return a + b
+}
+
+func saturatingMul(a, b int) int {
+	if a != 0 && b > math.MaxInt32/a {
+		return math.MaxInt32
+	}
+	return a * b
+}
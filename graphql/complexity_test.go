@@ -0,0 +1,137 @@
+package graphql_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/samsarahq/thunder/graphql"
+	"github.com/samsarahq/thunder/graphql/schemabuilder"
+	"github.com/samsarahq/thunder/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type complexityUser struct {
+	Name string
+}
+
+func complexitySchema() *graphql.Schema {
+	schema := schemabuilder.NewSchema()
+
+	query := schema.Query()
+	query.FieldFunc("me", func() *complexityUser {
+		return &complexityUser{Name: "me"}
+	})
+
+	user := schema.Object("user", complexityUser{})
+	user.FieldFunc("friends", func(args struct{ First *int64 }) []*complexityUser {
+		return nil
+	})
+	user.FieldFunc("score", func() int64 {
+		return 0
+	}, schemabuilder.Cost(10))
+
+	return schema.MustBuild()
+}
+
+func TestComputeComplexity(t *testing.T) {
+	schema := complexitySchema()
+
+	for _, tc := range []struct {
+		name     string
+		query    string
+		expected graphql.QueryComplexity
+	}{
+		{
+			"single field",
+			`{ me { name } }`,
+			graphql.QueryComplexity{Depth: 2, Cost: 2},
+		},
+		{
+			"field cost",
+			`{ me { name score } }`,
+			graphql.QueryComplexity{Depth: 2, Cost: 12},
+		},
+		{
+			"first multiplies selections",
+			`{ me { friends(first: 5) { name score } } }`,
+			graphql.QueryComplexity{Depth: 3, Cost: 1 + 1 + 5*11},
+		},
+		{
+			"nested multipliers",
+			`{ me { friends(first: 5) { friends(first: 10) { name } } } }`,
+			graphql.QueryComplexity{Depth: 4, Cost: 1 + 1 + 5*(1+10*1)},
+		},
+		{
+			"aliases",
+			`{ a: me { name } b: me { name } me { n: name } }`,
+			graphql.QueryComplexity{Depth: 2, Aliases: 3, Cost: 6},
+		},
+		{
+			"fragments",
+			`{ me { ...F } } fragment F on user { name score }`,
+			graphql.QueryComplexity{Depth: 2, Cost: 12},
+		},
+	} {
+		query, err := graphql.Parse(tc.query, nil)
+		require.NoError(t, err, tc.name)
+		require.NoError(t, graphql.PrepareQuery(context.Background(), schema.Query, query.SelectionSet), tc.name)
+		assert.Equal(t, tc.expected, graphql.ComputeComplexity(schema.Query, query.SelectionSet), tc.name)
+	}
+}
+
+func TestCheckComplexity(t *testing.T) {
+	schema := complexitySchema()
+
+	query, err := graphql.Parse(`{ a: me { friends(first: 100) { friends(first: 100) { name } } } }`, nil)
+	require.NoError(t, err)
+	require.NoError(t, graphql.PrepareQuery(context.Background(), schema.Query, query.SelectionSet))
+
+	assert.NoError(t, graphql.CheckComplexity(schema.Query, query.SelectionSet, graphql.ComplexityLimits{}))
+	assert.NoError(t, graphql.CheckComplexity(schema.Query, query.SelectionSet, graphql.ComplexityLimits{MaxDepth: 4, MaxAliases: 1, MaxCost: 10102}))
+
+	err = graphql.CheckComplexity(schema.Query, query.SelectionSet, graphql.ComplexityLimits{MaxDepth: 3})
+	assert.EqualError(t, err, "query has depth 4, which exceeds the maximum depth of 3")
+	assert.Equal(t, "query has depth 4, which exceeds the maximum depth of 3", graphql.SanitizeError(err))
+
+	err = graphql.CheckComplexity(schema.Query, query.SelectionSet, graphql.ComplexityLimits{MaxAliases: 0, MaxCost: 10000})
+	assert.EqualError(t, err, "query has cost 10102, which exceeds the maximum cost of 10000")
+
+	query, err = graphql.Parse(`{ a: me { name } b: me { name } }`, nil)
+	require.NoError(t, err)
+	require.NoError(t, graphql.PrepareQuery(context.Background(), schema.Query, query.SelectionSet))
+	err = graphql.CheckComplexity(schema.Query, query.SelectionSet, graphql.ComplexityLimits{MaxAliases: 1})
+	assert.EqualError(t, err, "query has 2 aliases, which exceeds the maximum of 1")
+}
+
+func TestHTTPComplexityLimits(t *testing.T) {
+	handler := graphql.NewHTTPHandler(complexitySchema(), graphql.WithHTTPComplexityLimits(graphql.ComplexityLimits{MaxDepth: 2}))
+
+	for _, tc := range []struct {
+		body     string
+		expected string
+	}{
+		{
+			`{"query": "{ me { name } }"}`,
+			`{"data": {"me": {"name": "me"}}, "errors": null}`,
+		},
+		{
+			`{"query": "{ me { friends { name } } }"}`,
+			`{"data": null, "errors": [{"message": "query has depth 3, which exceeds the maximum depth of 2"}]}`,
+		},
+	} {
+		req, err := http.NewRequest("POST", "/graphql", strings.NewReader(tc.body))
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		if diff := pretty.Compare(internal.ParseJSON(rr.Body.String()), internal.ParseJSON(tc.expected)); diff != "" {
+			t.Errorf("expected response to match, but received %s", diff)
+		}
+	}
+}
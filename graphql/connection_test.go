@@ -885,6 +885,62 @@ func TestEmbeddedArgs(t *testing.T) {
 
 }
 
+type itemLookup struct {
+	schemabuilder.OneOf
+	Id     *int64
+	Number *int64
+}
+
+func TestPaginatedOneOfArgs(t *testing.T) {
+	schema := schemabuilder.NewSchema()
+	query := schema.Query()
+	item := schema.Object("item", Item{})
+	item.Key("id")
+	query.FieldFunc("items", func(args struct{ By itemLookup }) []Item {
+		items := []Item{{Id: 1, Number: 10}, {Id: 2, Number: 20}, {Id: 3, Number: 10}}
+		var matches []Item
+		for _, item := range items {
+			if (args.By.Id != nil && item.Id == *args.By.Id) || (args.By.Number != nil && item.Number == *args.By.Number) {
+				matches = append(matches, item)
+			}
+		}
+		return matches
+	}, schemabuilder.Paginated)
+	builtSchema := schema.MustBuild()
+
+	run := func(query string, variables map[string]interface{}) (interface{}, error) {
+		q, err := graphql.Parse(query, variables)
+		if err != nil {
+			return nil, err
+		}
+		if err := graphql.PrepareQuery(context.Background(), builtSchema.Query, q.SelectionSet); err != nil {
+			return nil, err
+		}
+		e := testgraphql.NewExecutorWrapper(t)
+		return e.Execute(context.Background(), builtSchema.Query, nil, q)
+	}
+
+	val, err := run(`{ items(by: {number: 10}, first: 1) { totalCount edges { node { id } } } }`, nil)
+	require.NoError(t, err)
+	assert.Equal(t, internal.ParseJSON(`{"items": {"totalCount": 2, "edges": [{"node": {"__key": 1, "id": 1}}]}}`), internal.AsJSON(val))
+
+	val, err = run(`query Q($by: itemLookup_InputObject) { items(by: $by) { totalCount } }`, map[string]interface{}{
+		"by": map[string]interface{}{"id": float64(2)},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, internal.ParseJSON(`{"items": {"totalCount": 1}}`), internal.AsJSON(val))
+
+	_, err = run(`{ items(by: {id: 1, number: 10}, first: 1) { totalCount } }`, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "exactly one field must be set")
+
+	_, err = run(`query Q($by: itemLookup_InputObject) { items(by: $by) { totalCount } }`, map[string]interface{}{
+		"by": map[string]interface{}{},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `exactly one field of one-of type "itemLookup_InputObject" must be set`)
+}
+
 func TestEmbeddedArgsWithFilter(t *testing.T) {
 	schema := schemabuilder.NewSchema()
 	type Inner struct {
@@ -1,6 +1,7 @@
 package graphql
 
 import (
+	"context"
 	"reflect"
 )
 
@@ -49,3 +50,133 @@ func parseIf(d *Directive) (bool, error) {
 
 	return args[IF].(bool), nil
 }
+
+// TYPE_AS_OPTIONAL is a client-side-only directive that the server accepts and
+// ignores.
+const TYPE_AS_OPTIONAL = "type_as_optional"
+
+// ONE_OF is the schema directive that marks one-of input objects, of which
+// exactly one field must be set.
+const ONE_OF = "oneOf"
+
+// SPECIFIED_BY is the schema directive that links a custom scalar to the
+// specification of its format.
+const SPECIFIED_BY = "specifiedBy"
+
+// A DirectiveLocation is a place in a query or a schema where a directive may
+// be used.
+type DirectiveLocation string
+
+const (
+	DirectiveLocationField           DirectiveLocation = "FIELD"
+	DirectiveLocationFragmentSpread  DirectiveLocation = "FRAGMENT_SPREAD"
+	DirectiveLocationInlineFragment  DirectiveLocation = "INLINE_FRAGMENT"
+	DirectiveLocationFieldDefinition DirectiveLocation = "FIELD_DEFINITION"
+)
+
+// A DirectiveDefinition declares a custom directive of a schema.
+type DirectiveDefinition struct {
+	Name        string
+	Description string
+	Locations   []DirectiveLocation
+	Args        map[string]Type
+
+	// ParseArguments parses the arguments of a use of the directive.
+	ParseArguments func(json interface{}) (interface{}, error)
+
+	// Handler, if set, wraps the resolution of every field the directive is
+	// used on, in a query or in the schema. Directives on fragments are only
+	// validated.
+	Handler DirectiveHandler
+}
+
+// DirectiveHandler wraps the resolution of a field a directive is used on.
+// args are the parsed arguments of the directive, and next resolves the field.
+// For a batch resolver, the result of next and of the handler is a
+// []interface{} holding the result for each source.
+type DirectiveHandler func(ctx context.Context, args interface{}, next DirectiveNextFunc) (interface{}, error)
+
+// DirectiveNextFunc resolves a field, running any further directive handlers.
+type DirectiveNextFunc func(ctx context.Context) (interface{}, error)
+
+// hasLocation returns whether d may be used at location.
+func (d *DirectiveDefinition) hasLocation(location DirectiveLocation) bool {
+	for _, l := range d.Locations {
+		if l == location {
+			return true
+		}
+	}
+	return false
+}
+
+// prepareDirectives validates the directives used at one of locations against
+// definitions, and parses their arguments.
+func prepareDirectives(directives []*Directive, definitions map[string]*DirectiveDefinition, locations ...DirectiveLocation) error {
+	for _, directive := range directives {
+		switch directive.Name {
+		case SKIP, INCLUDE:
+			continue
+		case TYPE_AS_OPTIONAL:
+			if locations[0] == DirectiveLocationField {
+				continue
+			}
+		}
+
+		definition, ok := definitions[directive.Name]
+		if !ok {
+			return NewClientError(`unknown directive "@%s"`, directive.Name)
+		}
+		allowed := false
+		for _, location := range locations {
+			if definition.hasLocation(location) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return NewClientError(`directive "@%s" may not be used on %s`, directive.Name, locations[0])
+		}
+
+		if definition.ParseArguments == nil {
+			if !isNilArgs(directive.Args) {
+				return NewClientError(`error parsing args for "@%s": no args expected`, directive.Name)
+			}
+		} else {
+			parsed, err := definition.ParseArguments(directive.Args)
+			if err != nil {
+				return NewClientError(`error parsing args for "@%s": %s`, directive.Name, err)
+			}
+			directive.ParsedArgs = parsed
+		}
+		directive.Definition = definition
+	}
+	return nil
+}
+
+// runDirectiveHandlers runs resolve wrapped in the handlers of directives.
+func runDirectiveHandlers(ctx context.Context, directives []*Directive, resolve DirectiveNextFunc) (interface{}, error) {
+	if len(directives) == 0 {
+		return resolve(ctx)
+	}
+	directive := directives[0]
+	return directive.Definition.Handler(ctx, directive.ParsedArgs, func(ctx context.Context) (interface{}, error) {
+		return runDirectiveHandlers(ctx, directives[1:], resolve)
+	})
+}
+
+// directiveHandlers returns the directives with handlers applied to a field
+// in the schema, followed by those used on the selection of the field.
+func directiveHandlers(field *Field, selection *Selection) []*Directive {
+	var directives []*Directive
+	for _, directive := range field.Directives {
+		if directive.Definition != nil && directive.Definition.Handler != nil {
+			directives = append(directives, directive)
+		}
+	}
+	for _, directive := range selection.Directives {
+		if directive.Definition != nil && directive.Definition.Handler != nil {
+			directives = append(directives, directive)
+		}
+	}
+	return directives
+}
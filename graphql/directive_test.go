@@ -3,12 +3,16 @@ package graphql_test
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 
+	"github.com/samsarahq/thunder/batch"
 	"github.com/samsarahq/thunder/graphql"
 	"github.com/samsarahq/thunder/graphql/schemabuilder"
+	"github.com/samsarahq/thunder/internal"
 	"github.com/samsarahq/thunder/internal/testgraphql"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func buildSchema() *graphql.Schema {
@@ -254,3 +258,144 @@ func TestDirectivesWithErrors(t *testing.T) {
 	assert.Equal(t, err.Error(), "expected type boolean, found type string in \"if\" argument")
 
 }
+
+type roleKey struct{}
+
+type authArgs struct {
+	Role string
+}
+
+type upperArgs struct {
+	Enabled *bool
+}
+
+func buildCustomDirectiveSchema() *graphql.Schema {
+	schema := schemabuilder.NewSchema()
+
+	auth := schema.Directive("auth", authArgs{}, graphql.DirectiveLocationFieldDefinition)
+	auth.Description = "Restricts a field to users with a role."
+	auth.Handler = func(ctx context.Context, args interface{}, next graphql.DirectiveNextFunc) (interface{}, error) {
+		if role, _ := ctx.Value(roleKey{}).(string); role != args.(authArgs).Role {
+			return nil, graphql.NewClientError("forbidden")
+		}
+		return next(ctx)
+	}
+
+	upper := schema.Directive("upper", upperArgs{}, graphql.DirectiveLocationField)
+	upper.Handler = func(ctx context.Context, args interface{}, next graphql.DirectiveNextFunc) (interface{}, error) {
+		result, err := next(ctx)
+		if err != nil || (args.(upperArgs).Enabled != nil && !*args.(upperArgs).Enabled) {
+			return result, err
+		}
+		switch result := result.(type) {
+		case string:
+			return strings.ToUpper(result), nil
+		case []interface{}:
+			for i := range result {
+				result[i] = strings.ToUpper(result[i].(string))
+			}
+			return result, nil
+		}
+		return result, nil
+	}
+
+	schema.Directive("tag", nil, graphql.DirectiveLocationInlineFragment, graphql.DirectiveLocationFragmentSpread)
+
+	query := schema.Query()
+	query.FieldFunc("items", func(ctx context.Context) ([]Item, error) {
+		return []Item{{Id: 1}, {Id: 2}}, nil
+	})
+	query.FieldFunc("secret", func() string {
+		return "hunter2"
+	}, schemabuilder.ApplyDirective("auth", authArgs{Role: "admin"}))
+
+	item := schema.Object("item", Item{})
+	item.FieldFunc("name", func(item Item) string {
+		return fmt.Sprintf("item %d", item.Id)
+	})
+	item.BatchFieldFunc("batchName", func(ctx context.Context, items map[batch.Index]Item) map[batch.Index]string {
+		names := make(map[batch.Index]string, len(items))
+		for idx, item := range items {
+			names[idx] = fmt.Sprintf("batch %d", item.Id)
+		}
+		return names
+	})
+
+	return schema.MustBuild()
+}
+
+func TestCustomDirectives(t *testing.T) {
+	schema := buildCustomDirectiveSchema()
+	e := graphql.NewExecutor(graphql.NewImmediateGoroutineScheduler())
+
+	run := func(ctx context.Context, query string) (interface{}, error) {
+		q, err := graphql.Parse(query, nil)
+		if err != nil {
+			return nil, err
+		}
+		if err := graphql.PrepareQuery(ctx, schema.Query, q.SelectionSet, graphql.WithDirectives(schema.Directives)); err != nil {
+			return nil, err
+		}
+		return e.Execute(ctx, schema.Query, nil, q)
+	}
+
+	_, err := run(context.Background(), `{ secret }`)
+	assert.EqualError(t, err, "forbidden")
+
+	res, err := run(context.WithValue(context.Background(), roleKey{}, "admin"), `{ secret }`)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"secret": "hunter2"}, internal.AsJSON(res))
+
+	res, err = run(context.Background(), `{ items { name @upper batchName @upper plain: name @upper(enabled: false) } }`)
+	require.NoError(t, err)
+	assert.Equal(t, internal.ParseJSON(`{"items": [
+		{"name": "ITEM 1", "batchName": "BATCH 1", "plain": "item 1"},
+		{"name": "ITEM 2", "batchName": "BATCH 2", "plain": "item 2"}
+	]}`), internal.AsJSON(res))
+
+	// Directives on any of several selections of a field apply once they
+	// are merged.
+	res, err = run(context.Background(), `{ items { name name @upper ... on item { name @upper } } }`)
+	require.NoError(t, err)
+	assert.Equal(t, internal.ParseJSON(`{"items": [{"name": "ITEM 1"}, {"name": "ITEM 2"}]}`), internal.AsJSON(res))
+
+	res, err = run(context.Background(), `{ ... on Query @tag { items { name } } }`)
+	require.NoError(t, err)
+	assert.Equal(t, internal.ParseJSON(`{"items": [{"name": "item 1"}, {"name": "item 2"}]}`), internal.AsJSON(res))
+
+	_, err = run(context.Background(), `{ items { name @type_as_optional } }`)
+	assert.NoError(t, err)
+
+	_, err = run(context.Background(), `{ items { name @unknown } }`)
+	assert.EqualError(t, err, `unknown directive "@unknown"`)
+
+	_, err = run(context.Background(), `{ items { name @tag } }`)
+	assert.EqualError(t, err, `directive "@tag" may not be used on FIELD`)
+
+	_, err = run(context.Background(), `{ items { name @upper(enabled: "yes") } }`)
+	assert.EqualError(t, err, `error parsing args for "@upper": enabled: not a bool`)
+
+	_, err = run(context.Background(), `{ ... on Query @tag(x: 1) { items { name } } }`)
+	assert.EqualError(t, err, `error parsing args for "@tag": no args expected`)
+}
+
+func TestApplyDirectiveErrors(t *testing.T) {
+	schema := schemabuilder.NewSchema()
+	schema.Directive("auth", authArgs{}, graphql.DirectiveLocationFieldDefinition)
+	schema.Directive("upper", nil, graphql.DirectiveLocationField)
+	query := schema.Query()
+	query.FieldFunc("wrongArgs", func() string { return "" }, schemabuilder.ApplyDirective("auth", upperArgs{}))
+	_, err := schema.Build()
+	assert.EqualError(t, err, "bad method wrongArgs on type schemabuilder.query: directive auth expects args of type graphql_test.authArgs, got graphql_test.upperArgs")
+
+	schema = schemabuilder.NewSchema()
+	schema.Directive("upper", nil, graphql.DirectiveLocationField)
+	schema.Query().FieldFunc("wrongLocation", func() string { return "" }, schemabuilder.ApplyDirective("upper", nil))
+	_, err = schema.Build()
+	assert.EqualError(t, err, "bad method wrongLocation on type schemabuilder.query: directive upper may not be applied to fields")
+
+	schema = schemabuilder.NewSchema()
+	schema.Query().FieldFunc("unknown", func() string { return "" }, schemabuilder.ApplyDirective("unknown", nil))
+	_, err = schema.Build()
+	assert.EqualError(t, err, "bad method unknown on type schemabuilder.query: unknown directive unknown")
+}
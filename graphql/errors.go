@@ -4,6 +4,9 @@ import (
 	"fmt"
 
 	"github.com/gorilla/websocket"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/location"
+	"github.com/graphql-go/graphql/language/parser"
 )
 
 type SanitizedError interface {
@@ -14,6 +17,7 @@ type SanitizedError interface {
 type SafeError struct {
 	inner   error
 	message string
+	code    string
 }
 
 type ClientError SafeError
@@ -43,6 +47,14 @@ func (e SafeError) Unwrap() error {
 	return e.inner
 }
 
+func (e SafeError) Extensions() map[string]interface{} {
+	return codeExtensions(e.code)
+}
+
+func (e ClientError) Extensions() map[string]interface{} {
+	return codeExtensions(e.code)
+}
+
 func NewClientError(format string, a ...interface{}) error {
 	return ClientError{message: fmt.Sprintf(format, a...)}
 }
@@ -57,6 +69,191 @@ func WrapAsSafeError(err error, format string, a ...interface{}) error {
 	return SafeError{inner: err, message: fmt.Sprintf(format, a...)}
 }
 
+// WithErrorCode attaches a code to a SafeError or ClientError, which is sent to
+// clients as the "code" extension of the error. Other errors are returned
+// unchanged, as their details are not sent to clients.
+func WithErrorCode(err error, code string) error {
+	switch e := err.(type) {
+	case SafeError:
+		e.code = code
+		return e
+	case ClientError:
+		e.code = code
+		return e
+	default:
+		return err
+	}
+}
+
+func codeExtensions(code string) map[string]interface{} {
+	if code == "" {
+		return nil
+	}
+	return map[string]interface{}{"code": code}
+}
+
+// ErrorExtensions is implemented by errors that add extensions, such as an
+// error code, to their ResponseError.
+type ErrorExtensions interface {
+	Extensions() map[string]interface{}
+}
+
+// ErrorLocation is a position in a query. Lines and columns start at 1.
+type ErrorLocation struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// ResponseError is an error as it is sent to clients, in the format described
+// by the GraphQL spec.
+type ResponseError struct {
+	Message    string                 `json:"message"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Locations  []ErrorLocation        `json:"locations,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// NewResponseError converts an error returned by Parse, PrepareQuery or an
+// executor into a ResponseError. The message is sanitized, and the path of
+// the field that failed is included when it is known. query and
+// operationName are those of the failed request, and are used to find the
+// locations of the failed field.
+func NewResponseError(err error, query string, operationName string) ResponseError {
+	var responsePath []interface{}
+	switch e := err.(type) {
+	case *pathError:
+		responsePath = e.responsePath
+	case *sanitizedPathError:
+		responsePath = e.responsePath
+	}
+
+	response := ResponseError{Message: SanitizeError(err)}
+	if len(responsePath) > 0 {
+		response.Path = responsePath
+		response.Locations = fieldLocations(query, operationName, responsePath)
+	}
+	if extensions, ok := ErrorCause(err).(ErrorExtensions); ok {
+		response.Extensions = extensions.Extensions()
+	}
+	return response
+}
+
+// PartialResultError is returned along with a result by an Executor created
+// with WithPartialResults when some fields failed. The failed fields are null
+// in the result, and Errors holds their errors in the order they happened.
+type PartialResultError struct {
+	Errors []error
+}
+
+func (e *PartialResultError) Error() string {
+	if len(e.Errors) == 1 {
+		return e.Errors[0].Error()
+	}
+	return fmt.Sprintf("%s (and %d more errors)", e.Errors[0].Error(), len(e.Errors)-1)
+}
+
+// NewResponseErrors is like NewResponseError, but converts every error of a
+// *PartialResultError.
+func NewResponseErrors(err error, query string, operationName string) []ResponseError {
+	partial, ok := err.(*PartialResultError)
+	if !ok {
+		return []ResponseError{NewResponseError(err, query, operationName)}
+	}
+
+	errs := make([]ResponseError, 0, len(partial.Errors))
+	for _, err := range partial.Errors {
+		errs = append(errs, NewResponseError(err, query, operationName))
+	}
+	return errs
+}
+
+// fieldLocations returns the locations in query of the fields written to
+// responsePath. Locations are only needed for errors, so they are found by
+// parsing the query again instead of being tracked during execution.
+func fieldLocations(query string, operationName string, responsePath []interface{}) []ErrorLocation {
+	document, err := parser.Parse(parser.ParseParams{Source: query})
+	if err != nil {
+		return nil
+	}
+
+	var operations []*ast.OperationDefinition
+	fragments := make(map[string]*ast.FragmentDefinition)
+	for _, definition := range document.Definitions {
+		switch definition := definition.(type) {
+		case *ast.OperationDefinition:
+			if operationName == "" || (definition.Name != nil && definition.Name.Value == operationName) {
+				operations = append(operations, definition)
+			}
+		case *ast.FragmentDefinition:
+			fragments[definition.Name.Value] = definition
+		}
+	}
+	if len(operations) != 1 {
+		return nil
+	}
+
+	selectionSets := []*ast.SelectionSet{operations[0].SelectionSet}
+	var fields []*ast.Field
+	for _, key := range responsePath {
+		key, ok := key.(string)
+		if !ok {
+			// List indices don't select fields.
+			continue
+		}
+		fields = nil
+		for _, selectionSet := range selectionSets {
+			fields = append(fields, fieldsWithResponseKey(selectionSet, key, fragments, make(map[string]bool))...)
+		}
+		selectionSets = nil
+		for _, field := range fields {
+			if field.SelectionSet != nil {
+				selectionSets = append(selectionSets, field.SelectionSet)
+			}
+		}
+	}
+
+	var locations []ErrorLocation
+	for _, field := range fields {
+		if field.Loc == nil {
+			continue
+		}
+		loc := location.GetLocation(field.Loc.Source, field.Loc.Start)
+		locations = append(locations, ErrorLocation{Line: loc.Line, Column: loc.Column})
+	}
+	return locations
+}
+
+// fieldsWithResponseKey returns the fields in selectionSet, including those in
+// fragments, whose alias or name is key.
+func fieldsWithResponseKey(selectionSet *ast.SelectionSet, key string, fragments map[string]*ast.FragmentDefinition, visited map[string]bool) []*ast.Field {
+	if selectionSet == nil {
+		return nil
+	}
+
+	var fields []*ast.Field
+	for _, selection := range selectionSet.Selections {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			responseKey := selection.Name.Value
+			if selection.Alias != nil {
+				responseKey = selection.Alias.Value
+			}
+			if responseKey == key {
+				fields = append(fields, selection)
+			}
+		case *ast.InlineFragment:
+			fields = append(fields, fieldsWithResponseKey(selection.SelectionSet, key, fragments, visited)...)
+		case *ast.FragmentSpread:
+			name := selection.Name.Value
+			if fragment, ok := fragments[name]; ok && !visited[name] {
+				visited[name] = true
+				fields = append(fields, fieldsWithResponseKey(fragment.SelectionSet, key, fragments, visited)...)
+			}
+		}
+	}
+	return fields
+}
+
 // SanitizeError returns a sanitized error message for an error.
 func SanitizeError(err error) string {
 	if sanitized, ok := err.(SanitizedError); ok {
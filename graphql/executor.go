@@ -9,20 +9,38 @@ import (
 )
 
 type pathError struct {
-	inner error
-	path  []string
+	inner        error
+	path         []string
+	responsePath []interface{}
 }
 
-func nestPathErrorMulti(path []string, err error) error {
-	// Don't nest SanitzedError's, as they are intended for human consumption.
-	if se, ok := err.(SanitizedError); ok {
-		return se
-	}
+// sanitizedPathError is a SanitizedError nested in a path. Unlike pathError,
+// its messages are those of the inner error, as they are intended for human
+// consumption.
+type sanitizedPathError struct {
+	inner        SanitizedError
+	path         []string
+	responsePath []interface{}
+}
 
-	if pe, ok := err.(*pathError); ok {
+func nestPathErrorMulti(path []string, err error) error {
+	switch e := err.(type) {
+	case *sanitizedPathError:
+		return &sanitizedPathError{
+			inner:        e.inner,
+			path:         append(e.path, path...),
+			responsePath: e.responsePath,
+		}
+	case SanitizedError:
+		return &sanitizedPathError{
+			inner: e,
+			path:  path,
+		}
+	case *pathError:
 		return &pathError{
-			inner: pe.inner,
-			path:  append(pe.path, path...),
+			inner:        e.inner,
+			path:         append(e.path, path...),
+			responsePath: e.responsePath,
 		}
 	}
 
@@ -33,31 +51,46 @@ func nestPathErrorMulti(path []string, err error) error {
 }
 
 func nestPathError(key string, err error) error {
-	// Don't nest SanitzedError's, as they are intended for human consumption.
-	if se, ok := err.(SanitizedError); ok {
-		return se
-	}
+	return nestPathErrorMulti([]string{key}, err)
+}
 
-	if pe, ok := err.(*pathError); ok {
-		return &pathError{
-			inner: pe.inner,
-			path:  append(pe.path, key),
+// setResponsePath records the response path of a failed field in err, unless
+// a deeper field already recorded its path.
+func setResponsePath(err error, responsePath []interface{}) {
+	switch e := err.(type) {
+	case *pathError:
+		if e.responsePath == nil {
+			e.responsePath = responsePath
+		}
+	case *sanitizedPathError:
+		if e.responsePath == nil {
+			e.responsePath = responsePath
 		}
-	}
-
-	return &pathError{
-		inner: err,
-		path:  []string{key},
 	}
 }
 
 func ErrorCause(err error) error {
-	if pe, ok := err.(*pathError); ok {
-		return pe.inner
+	switch e := err.(type) {
+	case *pathError:
+		return e.inner
+	case *sanitizedPathError:
+		return e.inner
 	}
 	return err
 }
 
+func (e *sanitizedPathError) Error() string {
+	return e.inner.Error()
+}
+
+func (e *sanitizedPathError) SanitizedError() string {
+	return e.inner.SanitizedError()
+}
+
+func (e *sanitizedPathError) Unwrap() error {
+	return e.inner
+}
+
 func (pe *pathError) Unwrap() error {
 	return pe.inner
 }
@@ -104,9 +137,77 @@ func unwrap(v interface{}) interface{} {
 	return i.Interface()
 }
 
+// PrepareOption configures PrepareQuery.
+type PrepareOption func(*prepareOptions)
+
+type prepareOptions struct {
+	directives      map[string]*DirectiveDefinition
+	strictVariables bool
+}
+
+// WithDirectives makes PrepareQuery validate the directives in the query
+// against the custom directives of a schema, and parse their arguments.
+// Without it, directives other than @skip and @include are ignored.
+func WithDirectives(directives map[string]*DirectiveDefinition) PrepareOption {
+	return func(o *prepareOptions) {
+		if directives == nil {
+			directives = make(map[string]*DirectiveDefinition)
+		}
+		o.directives = directives
+	}
+}
+
+// WithStrictVariables makes PrepareQuery check that every variable is
+// declared with the type of the arguments it is passed to, following the
+// GraphQL spec's rules: a nullable variable may only be passed to a non-null
+// argument if it has a default value. Without it, only the values of
+// variables are checked.
+func WithStrictVariables() PrepareOption {
+	return func(o *prepareOptions) {
+		o.strictVariables = true
+	}
+}
+
+// PrepareOptions returns the options to prepare queries against s with: its
+// directives, and strict variables if StrictVariables is set.
+func (s *Schema) PrepareOptions() []PrepareOption {
+	opts := []PrepareOption{WithDirectives(s.Directives)}
+	if s.StrictVariables {
+		opts = append(opts, WithStrictVariables())
+	}
+	return opts
+}
+
 // PrepareQuery checks that the given selectionSet matches the schema typ, and
 // parses the args in selectionSet
-func PrepareQuery(ctx context.Context, typ Type, selectionSet *SelectionSet) error {
+func PrepareQuery(ctx context.Context, typ Type, selectionSet *SelectionSet, opts ...PrepareOption) error {
+	var options prepareOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return prepareQuery(ctx, typ, selectionSet, &options)
+}
+
+// prepareDirectives validates the directives of selectionSet itself, not of
+// its children, when PrepareQuery was given directive definitions.
+func (o *prepareOptions) prepareDirectives(selectionSet *SelectionSet) error {
+	if o.directives == nil {
+		return nil
+	}
+	for _, selection := range selectionSet.Selections {
+		if err := prepareDirectives(selection.Directives, o.directives, DirectiveLocationField); err != nil {
+			return err
+		}
+	}
+	for _, fragment := range selectionSet.Fragments {
+		if err := prepareDirectives(fragment.Directives, o.directives, DirectiveLocationFragmentSpread, DirectiveLocationInlineFragment); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func prepareQuery(ctx context.Context, typ Type, selectionSet *SelectionSet, options *prepareOptions) error {
 	switch typ := typ.(type) {
 	case *Scalar:
 		if selectionSet != nil {
@@ -122,13 +223,16 @@ func PrepareQuery(ctx context.Context, typ Type, selectionSet *SelectionSet) err
 		if selectionSet == nil {
 			return NewClientError("object field must have selections")
 		}
+		if err := options.prepareDirectives(selectionSet); err != nil {
+			return err
+		}
 
 		for _, fragment := range selectionSet.Fragments {
-			for typString, graphqlTyp := range typ.Types {
-				if fragment.On != typString {
+			for _, graphqlTyp := range typ.Types {
+				if !matchesTypeCondition(graphqlTyp, fragment.On) {
 					continue
 				}
-				if err := PrepareQuery(ctx, graphqlTyp, fragment.SelectionSet); err != nil {
+				if err := prepareQuery(ctx, graphqlTyp, fragment.SelectionSet, options); err != nil {
 					return err
 				}
 			}
@@ -149,10 +253,30 @@ func PrepareQuery(ctx context.Context, typ Type, selectionSet *SelectionSet) err
 			return NewClientError(`unknown field "%s"`, selection.Name)
 		}
 		return nil
+	case *Interface:
+		if selectionSet == nil {
+			return NewClientError("object field must have selections")
+		}
+		if err := validateInterfaceSelections(typ, selectionSet); err != nil {
+			return err
+		}
+
+		selectionSet.possibleTypes = make(map[string]*SelectionSet, len(typ.Types))
+		for name, obj := range typ.Types {
+			typed := selectionSetForType(selectionSet, obj)
+			if err := prepareQuery(ctx, obj, typed, options); err != nil {
+				return err
+			}
+			selectionSet.possibleTypes[name] = typed
+		}
+		return nil
 	case *Object:
 		if selectionSet == nil {
 			return NewClientError("object field must have selections")
 		}
+		if err := options.prepareDirectives(selectionSet); err != nil {
+			return err
+		}
 		for _, selection := range selectionSet.Selections {
 			if selection.Name == "__typename" {
 				if !isNilArgs(selection.UnparsedArgs) {
@@ -172,6 +296,9 @@ func PrepareQuery(ctx context.Context, typ Type, selectionSet *SelectionSet) err
 			// Only parse args once for a given selection.
 			if !selection.parsed {
 				selection.parsed = true
+				if err := coerceVariableUsages(field, selection, options.strictVariables); err != nil {
+					return err
+				}
 				parsed, err := field.ParseArguments(selection.UnparsedArgs)
 				if err != nil {
 					return NewClientError(`error parsing args for "%s": %s`, selection.Name, err)
@@ -181,28 +308,104 @@ func PrepareQuery(ctx context.Context, typ Type, selectionSet *SelectionSet) err
 
 			selection.ParentType = typ.Name
 
-			if err := PrepareQuery(ctx, field.Type, selection.SelectionSet); err != nil {
+			if err := prepareQuery(ctx, field.Type, selection.SelectionSet, options); err != nil {
 				return err
 			}
 		}
 		for _, fragment := range selectionSet.Fragments {
-			if err := PrepareQuery(ctx, typ, fragment.SelectionSet); err != nil {
+			if err := prepareQuery(ctx, typ, fragment.SelectionSet, options); err != nil {
 				return err
 			}
 		}
 		return nil
 
 	case *List:
-		return PrepareQuery(ctx, typ.Type, selectionSet)
+		return prepareQuery(ctx, typ.Type, selectionSet, options)
 
 	case *NonNull:
-		return PrepareQuery(ctx, typ.Type, selectionSet)
+		return prepareQuery(ctx, typ.Type, selectionSet, options)
 
 	default:
 		panic("unknown type kind")
 	}
 }
 
+// matchesTypeCondition returns whether a fragment on the type named on applies
+// to values of the object obj, either because it names obj itself or one of
+// the interfaces obj implements.
+func matchesTypeCondition(obj *Object, on string) bool {
+	if obj.Name == on {
+		return true
+	}
+	_, ok := obj.Interfaces[on]
+	return ok
+}
+
+// validateInterfaceSelections checks that the selections in a selection set
+// on an interface exist on that interface, and that every fragment could
+// apply to at least one of its possible types.
+func validateInterfaceSelections(typ *Interface, selectionSet *SelectionSet) error {
+	for _, selection := range selectionSet.Selections {
+		if selection.Name == "__typename" {
+			continue
+		}
+		if _, ok := typ.Fields[selection.Name]; !ok {
+			return NewClientError(`unknown field "%s"`, selection.Name)
+		}
+	}
+
+	for _, fragment := range selectionSet.Fragments {
+		if fragment.On == typ.Name {
+			if err := validateInterfaceSelections(typ, fragment.SelectionSet); err != nil {
+				return err
+			}
+			continue
+		}
+
+		possible := false
+		for _, obj := range typ.Types {
+			if matchesTypeCondition(obj, fragment.On) {
+				possible = true
+				break
+			}
+		}
+		if !possible {
+			return NewClientError(`fragment on "%s" can never apply to interface "%s"`, fragment.On, typ.Name)
+		}
+	}
+	return nil
+}
+
+// selectionSetForType returns the part of a selection set on an interface
+// that applies to the possible type obj. Selections are copied so that their
+// arguments can be parsed by obj's fields.
+func selectionSetForType(selectionSet *SelectionSet, obj *Object) *SelectionSet {
+	typed := &SelectionSet{
+		Selections: make([]*Selection, 0, len(selectionSet.Selections)),
+	}
+	for _, selection := range selectionSet.Selections {
+		typed.Selections = append(typed.Selections, &Selection{
+			Name:         selection.Name,
+			Alias:        selection.Alias,
+			UnparsedArgs: selection.UnparsedArgs,
+			SelectionSet: selection.SelectionSet,
+			Directives:   selection.Directives,
+			variables:    selection.variables,
+		})
+	}
+	for _, fragment := range selectionSet.Fragments {
+		if !matchesTypeCondition(obj, fragment.On) {
+			continue
+		}
+		typed.Fragments = append(typed.Fragments, &Fragment{
+			On:           fragment.On,
+			SelectionSet: selectionSetForType(fragment.SelectionSet, obj),
+			Directives:   fragment.Directives,
+		})
+	}
+	return typed
+}
+
 func SafeExecuteBatchResolver(ctx context.Context, field *Field, sources []interface{}, args interface{}, selectionSet *SelectionSet) (results []interface{}, err error) {
 	defer func() {
 		if panicErr := recover(); panicErr != nil {
@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/samsarahq/thunder/graphql"
@@ -201,6 +203,46 @@ func TestRepeatedFragment(t *testing.T) {
 	}
 }
 
+func TestFlattenDirectives(t *testing.T) {
+	upper := &graphql.Directive{Name: "upper", Args: map[string]interface{}{}}
+	auth := &graphql.Directive{Name: "auth", Args: map[string]interface{}{"role": "admin"}}
+	selection := func(directives ...*graphql.Directive) *graphql.Selection {
+		return &graphql.Selection{
+			Name:         "a",
+			Alias:        "a",
+			SelectionSet: &graphql.SelectionSet{},
+			Directives:   directives,
+		}
+	}
+
+	first := selection(upper)
+	result, err := graphql.Flatten(&graphql.SelectionSet{
+		Selections: []*graphql.Selection{first, selection(), selection(auth, upper)},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, result, 1)
+	assert.Equal(t, []*graphql.Directive{upper, auth}, result[0].Directives)
+	assert.Equal(t, []*graphql.Directive{upper}, first.Directives)
+
+	// An excluded occurrence of a field does not exclude the others.
+	for _, show := range []bool{false, true} {
+		q := graphql.MustParse(`
+			query Q($show: Boolean) { name ...D }
+			fragment D on Query { name @include(if: $show) }`, map[string]interface{}{"show": show})
+		result, err = graphql.Flatten(q.SelectionSet)
+		assert.NoError(t, err)
+		if assert.Len(t, result, 1) {
+			assert.Equal(t, "name", result[0].Alias)
+			assert.Empty(t, result[0].Directives)
+		}
+	}
+
+	q := graphql.MustParse(`{ name @skip(if: true) ... on Query { name @include(if: false) } }`, nil)
+	result, err = graphql.Flatten(q.SelectionSet)
+	assert.NoError(t, err)
+	assert.Empty(t, result)
+}
+
 func TestFlatten(t *testing.T) {
 	type Args struct {
 		Value int
@@ -467,6 +509,118 @@ func TestExecutorRuns(t *testing.T) {
 	}
 }
 
+func TestMutationsRunSerially(t *testing.T) {
+	type Item struct {
+		Name string
+	}
+
+	var mu sync.Mutex
+	var log []string
+	record := func(entry string) {
+		mu.Lock()
+		defer mu.Unlock()
+		log = append(log, entry)
+	}
+
+	builder := schemabuilder.NewSchema()
+	builder.Mutation().FieldFunc("add", func(args struct{ Name string }) (*Item, error) {
+		record("start " + args.Name)
+		time.Sleep(5 * time.Millisecond)
+		record("end " + args.Name)
+		if args.Name == "fail" {
+			return nil, errors.New("failed")
+		}
+		return &Item{Name: args.Name}, nil
+	})
+	item := builder.Object("item", Item{})
+	item.FieldFunc("saved", func(i *Item) bool {
+		record("saved " + i.Name)
+		return true
+	}, schemabuilder.Expensive)
+	schema := builder.MustBuild()
+
+	execute := func(query string) error {
+		log = nil
+		q := graphql.MustParse(query, nil)
+		require.NoError(t, graphql.PrepareQuery(context.Background(), schema.Mutation, q.SelectionSet))
+		e := graphql.NewExecutor(graphql.NewImmediateGoroutineScheduler())
+		_, err := e.Execute(context.Background(), schema.Mutation, nil, q)
+		return err
+	}
+
+	// Each field runs along with its selections before the next one starts.
+	require.NoError(t, execute(`mutation {
+		c: add(name: "c") { saved }
+		a: add(name: "a") { saved }
+		b: add(name: "b") { saved }
+	}`))
+	assert.Equal(t, []string{
+		"start c", "end c", "saved c",
+		"start a", "end a", "saved a",
+		"start b", "end b", "saved b",
+	}, log)
+
+	// Fields after a failed field do not run, as the mutation fails.
+	err := execute(`mutation {
+		a: add(name: "a") { saved }
+		fail: add(name: "fail") { saved }
+		b: add(name: "b") { saved }
+	}`)
+	require.Error(t, err)
+	assert.Equal(t, "fail: failed", err.Error())
+	assert.Equal(t, []string{"start a", "end a", "saved a", "start fail", "end fail"}, log)
+}
+
+func TestMutationTransaction(t *testing.T) {
+	var committed []string
+	var pending []string
+	transaction := func(ctx context.Context, execute func(ctx context.Context) error) error {
+		pending = nil
+		if err := execute(ctx); err != nil {
+			return err
+		}
+		committed = append(committed, pending...)
+		return nil
+	}
+
+	builder := schemabuilder.NewSchema()
+	builder.Mutation().FieldFunc("add", func(args struct{ Name string }) (string, error) {
+		if args.Name == "fail" {
+			return "", errors.New("failed")
+		}
+		pending = append(pending, args.Name)
+		return args.Name, nil
+	})
+	schema := builder.MustBuild()
+	schema.Mutation.(*graphql.Object).Transaction = transaction
+
+	for _, partial := range []bool{false, true} {
+		committed = nil
+		var opts []graphql.ExecutorOption
+		if partial {
+			opts = append(opts, graphql.WithPartialResults())
+		}
+		e := graphql.NewExecutor(graphql.NewImmediateGoroutineScheduler(), opts...)
+
+		q := graphql.MustParse(`mutation { a: add(name: "a") b: add(name: "b") }`, nil)
+		require.NoError(t, graphql.PrepareQuery(context.Background(), schema.Mutation, q.SelectionSet))
+		result, err := e.Execute(context.Background(), schema.Mutation, nil, q)
+		require.NoError(t, err)
+		assert.Equal(t, internal.ParseJSON(`{"a": "a", "b": "b"}`), internal.AsJSON(result))
+		assert.Equal(t, []string{"a", "b"}, committed)
+
+		// A failed field rolls back the whole mutation, even with partial
+		// results.
+		q = graphql.MustParse(`mutation { c: add(name: "c") fail: add(name: "fail") }`, nil)
+		require.NoError(t, graphql.PrepareQuery(context.Background(), schema.Mutation, q.SelectionSet))
+		result, err = e.Execute(context.Background(), schema.Mutation, nil, q)
+		require.Error(t, err)
+		assert.Nil(t, result)
+		assert.Contains(t, err.Error(), "fail: failed")
+		assert.Equal(t, []string{"a", "b"}, committed)
+	}
+}
+
 func Test_pathError_Reason(t *testing.T) {
 	type fields struct {
 		inner error
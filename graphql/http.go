@@ -1,9 +1,9 @@
 package graphql
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
-	"errors"
 	"net/http"
 	"sync"
 
@@ -12,92 +12,243 @@ import (
 )
 
 func HTTPHandler(schema *Schema, middlewares ...MiddlewareFunc) http.Handler {
-	return HTTPHandlerWithExecutor(schema, (NewExecutor(NewImmediateGoroutineScheduler())), middlewares...)
+	return NewHTTPHandler(schema, WithHTTPMiddlewares(middlewares...))
 }
 
 func HTTPHandlerWithExecutor(schema *Schema, executor ExecutorRunner, middlewares ...MiddlewareFunc) http.Handler {
-	return &httpHandler{
-		schema:      schema,
-		middlewares: middlewares,
-		executor:    executor,
+	return NewHTTPHandler(schema, WithHTTPExecutor(executor), WithHTTPMiddlewares(middlewares...))
+}
+
+// DefaultHTTPMaxBatchSize is the default maximum number of operations in a
+// batched HTTP request.
+const DefaultHTTPMaxBatchSize = 50
+
+// NewHTTPHandler returns an http.Handler that executes queries and mutations
+// sent as POST requests with a JSON body, or queries sent as GET requests
+// with query, variables, operationName and extensions URL parameters.
+//
+// A POST body may also be a JSON array of operations, which are executed
+// concurrently and answered with an array of responses in the same order.
+// The operations of such a batch share their batching context, so that calls
+// to a batch.Func from different operations are combined.
+func NewHTTPHandler(schema *Schema, opts ...HTTPHandlerOption) http.Handler {
+	h := &httpHandler{
+		schema:       schema,
+		executor:     NewExecutor(NewImmediateGoroutineScheduler()),
+		maxBatchSize: DefaultHTTPMaxBatchSize,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+type HTTPHandlerOption func(*httpHandler)
+
+func WithHTTPExecutor(executor ExecutorRunner) HTTPHandlerOption {
+	return func(h *httpHandler) {
+		h.executor = executor
+	}
+}
+
+func WithHTTPMiddlewares(middlewares ...MiddlewareFunc) HTTPHandlerOption {
+	return func(h *httpHandler) {
+		h.middlewares = append(h.middlewares, middlewares...)
+	}
+}
+
+// WithHTTPPersistedQueryStore enables automatic persisted queries, storing
+// them in store.
+func WithHTTPPersistedQueryStore(store PersistedQueryStore) HTTPHandlerOption {
+	return func(h *httpHandler) {
+		h.persistedQueries = store
+	}
+}
+
+// WithHTTPComplexityLimits rejects queries and mutations that exceed limits.
+func WithHTTPComplexityLimits(limits ComplexityLimits) HTTPHandlerOption {
+	return func(h *httpHandler) {
+		h.complexityLimits = limits
+	}
+}
+
+// WithHTTPMaxBatchSize limits the number of operations in a batched request.
+func WithHTTPMaxBatchSize(max int) HTTPHandlerOption {
+	return func(h *httpHandler) {
+		h.maxBatchSize = max
 	}
 }
 
 type httpHandler struct {
-	schema      *Schema
-	middlewares []MiddlewareFunc
-	executor    ExecutorRunner
+	schema           *Schema
+	middlewares      []MiddlewareFunc
+	executor         ExecutorRunner
+	persistedQueries PersistedQueryStore
+	complexityLimits ComplexityLimits
+	maxBatchSize     int
 }
 
 type httpPostBody struct {
-	Query     string                 `json:"query"`
-	Variables map[string]interface{} `json:"variables"`
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables"`
+	OperationName string                 `json:"operationName"`
+	Extensions    map[string]interface{} `json:"extensions"`
+}
+
+// readHTTPGetParams reads the parameters of a GET request from its URL.
+// Variables and extensions are JSON-encoded.
+func readHTTPGetParams(r *http.Request, params *httpPostBody) error {
+	values := r.URL.Query()
+	params.Query = values.Get("query")
+	params.OperationName = values.Get("operationName")
+	if variables := values.Get("variables"); variables != "" {
+		if err := json.Unmarshal([]byte(variables), &params.Variables); err != nil {
+			return NewClientError("variables must be JSON: %s", err)
+		}
+	}
+	if extensions := values.Get("extensions"); extensions != "" {
+		if err := json.Unmarshal([]byte(extensions), &params.Extensions); err != nil {
+			return NewClientError("extensions must be JSON: %s", err)
+		}
+	}
+	return nil
 }
 
 type httpResponse struct {
-	Data   interface{} `json:"data"`
-	Errors []string    `json:"errors"`
+	Data       interface{}            `json:"data"`
+	Errors     []ResponseError        `json:"errors"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+
+	// cacheHint is the cache hint of a successful query, if it is cacheable.
+	cacheHint *CacheHint
 }
 
 func (h *httpHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	writeResponse := func(value interface{}, err error) {
-		response := httpResponse{}
-		if err != nil {
-			response.Errors = []string{err.Error()}
-		} else {
-			response.Data = value
+	switch r.Method {
+	case "GET":
+		var params httpPostBody
+		if err := readHTTPGetParams(r, &params); err != nil {
+			writeHTTPResponse(w, newHTTPResponse(&params, nil, err, nil))
+			return
 		}
+		response := h.execute(r.Context(), r.Method, &params)
+		setCacheControl(w, response)
+		writeHTTPResponse(w, response)
 
-		responseJSON, err := json.Marshal(response)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+	case "POST":
+		if r.Body == nil {
+			writeHTTPResponse(w, newHTTPResponse(&httpPostBody{}, nil, NewClientError("request must include a query"), nil))
 			return
 		}
-		if w.Header().Get("Content-Type") == "" {
-			w.Header().Set("Content-Type", "application/json")
+
+		var body json.RawMessage
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeHTTPResponse(w, newHTTPResponse(&httpPostBody{}, nil, NewClientError("request body must be JSON: %s", err), nil))
+			return
 		}
-		w.Write(responseJSON)
+		if trimmed := bytes.TrimLeft(body, " \t\r\n"); len(trimmed) > 0 && trimmed[0] == '[' {
+			h.serveBatch(w, r, body)
+			return
+		}
+
+		var params httpPostBody
+		if err := json.Unmarshal(body, &params); err != nil {
+			writeHTTPResponse(w, newHTTPResponse(&params, nil, NewClientError("request body must be JSON: %s", err), nil))
+			return
+		}
+		writeHTTPResponse(w, h.execute(r.Context(), r.Method, &params))
+
+	default:
+		writeHTTPResponse(w, newHTTPResponse(&httpPostBody{}, nil, NewClientError("request must be a GET or POST"), nil))
 	}
+}
 
-	if r.Method != "POST" {
-		writeResponse(nil, errors.New("request must be a POST"))
+// serveBatch executes an array of operations concurrently, and responds with
+// an array of their results in the same order. The operations share a single
+// batching context, so that batch.Funcs combine the calls of all operations.
+func (h *httpHandler) serveBatch(w http.ResponseWriter, r *http.Request, body json.RawMessage) {
+	var batchParams []*httpPostBody
+	if err := json.Unmarshal(body, &batchParams); err != nil {
+		writeHTTPResponse(w, newHTTPResponse(&httpPostBody{}, nil, NewClientError("request body must be JSON: %s", err), nil))
 		return
 	}
-
-	if r.Body == nil {
-		writeResponse(nil, errors.New("request must include a query"))
+	if len(batchParams) == 0 {
+		writeHTTPResponse(w, newHTTPResponse(&httpPostBody{}, nil, NewClientError("batch must include at least one operation"), nil))
 		return
 	}
-
-	var params httpPostBody
-	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
-		writeResponse(nil, err)
+	if len(batchParams) > h.maxBatchSize {
+		writeHTTPResponse(w, newHTTPResponse(&httpPostBody{}, nil, NewClientError("batch must include at most %d operations", h.maxBatchSize), nil))
 		return
 	}
 
-	query, err := Parse(params.Query, params.Variables)
+	ctx := batch.WithBatching(r.Context())
+	responses := make([]*httpResponse, len(batchParams))
+	var wg sync.WaitGroup
+	for i, params := range batchParams {
+		if params == nil {
+			responses[i] = newHTTPResponse(&httpPostBody{}, nil, NewClientError("operation must be an object"), nil)
+			continue
+		}
+		wg.Add(1)
+		go func(i int, params *httpPostBody) {
+			defer wg.Done()
+			responses[i] = h.execute(ctx, r.Method, params)
+		}(i, params)
+	}
+	wg.Wait()
+
+	writeHTTPResponse(w, responses)
+}
+
+// execute executes the operation of params. If ctx already has batching, the
+// operation shares it.
+func (h *httpHandler) execute(ctx context.Context, method string, params *httpPostBody) *httpResponse {
+	queryText, err := resolvePersistedQuery(ctx, h.persistedQueries, params.Query, params.Extensions)
 	if err != nil {
-		writeResponse(nil, err)
-		return
+		return newHTTPResponse(params, nil, err, nil)
+	}
+	params.Query = queryText
+
+	query, err := ParseOperation(params.Query, params.Variables, params.OperationName)
+	if err != nil {
+		return newHTTPResponse(params, nil, err, nil)
+	}
+
+	if query.Kind == "subscription" {
+		return newHTTPResponse(params, nil, NewClientError("subscriptions are only supported over websockets"), nil)
+	}
+	if query.Kind == "mutation" && method == "GET" {
+		return newHTTPResponse(params, nil, NewClientError("mutations must be sent as POST requests"), nil)
 	}
 
 	schema := h.schema.Query
 	if query.Kind == "mutation" {
 		schema = h.schema.Mutation
 	}
-	if err := PrepareQuery(r.Context(), schema, query.SelectionSet); err != nil {
-		writeResponse(nil, err)
-		return
+	if err := PrepareQuery(ctx, schema, query.SelectionSet, h.schema.PrepareOptions()...); err != nil {
+		return newHTTPResponse(params, nil, err, nil)
+	}
+	if err := CheckComplexity(schema, query.SelectionSet, h.complexityLimits); err != nil {
+		return newHTTPResponse(params, nil, err, nil)
+	}
+	var cacheHint *CacheHint
+	if query.Kind == "query" {
+		if hint, ok := ComputeCacheHint(schema, query.SelectionSet); ok {
+			cacheHint = &hint
+		}
 	}
 
+	var response *httpResponse
 	var wg sync.WaitGroup
 	e := h.executor
 
 	wg.Add(1)
-	runner := reactive.NewRerunner(r.Context(), func(ctx context.Context) (interface{}, error) {
+	runner := reactive.NewRerunner(ctx, func(ctx context.Context) (interface{}, error) {
 		defer wg.Done()
 
-		ctx = batch.WithBatching(ctx)
+		if !batch.HasBatching(ctx) {
+			ctx = batch.WithBatching(ctx)
+		}
 
 		var middlewares []MiddlewareFunc
 		middlewares = append(middlewares, h.middlewares...)
@@ -108,10 +259,12 @@ func (h *httpHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		})
 
 		output := RunMiddlewares(middlewares, &ComputationInput{
-			Ctx:         ctx,
-			ParsedQuery: query,
-			Query:       params.Query,
-			Variables:   params.Variables,
+			Ctx:           ctx,
+			ParsedQuery:   query,
+			Query:         params.Query,
+			OperationName: params.OperationName,
+			Variables:     params.Variables,
+			Extensions:    params.Extensions,
 		})
 		current, err := output.Current, output.Error
 
@@ -120,14 +273,57 @@ func (h *httpHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 				return nil, err
 			}
 
-			writeResponse(nil, err)
+			if _, ok := err.(*PartialResultError); ok {
+				response = newHTTPResponse(params, current, err, output.Extensions)
+				return nil, err
+			}
+			response = newHTTPResponse(params, nil, err, output.Extensions)
 			return nil, err
 		}
 
-		writeResponse(current, nil)
+		response = newHTTPResponse(params, current, nil, output.Extensions)
+		response.cacheHint = cacheHint
 		return nil, nil
 	}, DefaultMinRerunInterval, false)
 
 	wg.Wait()
 	runner.Stop()
+
+	if response == nil {
+		// The request was canceled.
+		return newHTTPResponse(params, nil, ctx.Err(), nil)
+	}
+	return response
+}
+
+// newHTTPResponse builds the response to the operation of params. extensions
+// holds the extensions of the computation.
+func newHTTPResponse(params *httpPostBody, value interface{}, err error, extensions map[string]interface{}) *httpResponse {
+	response := &httpResponse{Data: value, Extensions: extensions}
+	if err != nil {
+		response.Errors = NewResponseErrors(err, params.Query, params.OperationName)
+	}
+	return response
+}
+
+// setCacheControl sets the Cache-Control header of the response to a GET
+// request to its cache hint, if it is cacheable. Responses to POST requests
+// are never cached, so they get no header.
+func setCacheControl(w http.ResponseWriter, response *httpResponse) {
+	if response.cacheHint != nil {
+		w.Header().Set("Cache-Control", cacheControlHeader(*response.cacheHint))
+	}
+}
+
+// writeHTTPResponse writes a response, or an array of responses to a batch.
+func writeHTTPResponse(w http.ResponseWriter, response interface{}) {
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	w.Write(responseJSON)
 }
@@ -1,36 +1,66 @@
 package graphql_test
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/kylelemons/godebug/pretty"
 
+	"github.com/samsarahq/thunder/batch"
 	"github.com/samsarahq/thunder/graphql"
 	"github.com/samsarahq/thunder/graphql/schemabuilder"
+	"github.com/samsarahq/thunder/internal"
 )
 
-func testHTTPRequest(req *http.Request) *httptest.ResponseRecorder {
+type httpItem struct {
+	Id int64
+}
+
+func testHTTPSchema() *graphql.Schema {
 	schema := schemabuilder.NewSchema()
 
 	query := schema.Query()
 	query.FieldFunc("mirror", func(args struct{ Value int64 }) int64 {
 		return args.Value * -1
 	})
+	query.FieldFunc("items", func() []*httpItem {
+		return []*httpItem{{Id: 1}, {Id: 2}}
+	})
+	query.FieldFunc("broken", func() (string, error) {
+		return "", errors.New("database password is hunter2")
+	})
 
-	builtSchema := schema.MustBuild()
+	item := schema.Object("httpItem", httpItem{})
+	item.FieldFunc("name", func(i *httpItem) (string, error) {
+		if i.Id == 2 {
+			return "", graphql.WithErrorCode(graphql.NewClientError("item %d is hidden", i.Id), "FORBIDDEN")
+		}
+		return "item", nil
+	})
 
+	return schema.MustBuild()
+}
+
+func testHTTPRequest(req *http.Request) *httptest.ResponseRecorder {
 	rr := httptest.NewRecorder()
-	handler := graphql.HTTPHandler(builtSchema)
+	handler := graphql.HTTPHandler(testHTTPSchema())
 
 	handler.ServeHTTP(rr, req)
 	return rr
 }
 
 func TestHTTPMustPost(t *testing.T) {
-	req, err := http.NewRequest("GET", "/graphql", nil)
+	req, err := http.NewRequest("PUT", "/graphql", nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -41,7 +71,7 @@ func TestHTTPMustPost(t *testing.T) {
 		t.Errorf("expected 200, but received %d", rr.Code)
 	}
 
-	if diff := pretty.Compare(rr.Body.String(), "{\"data\":null,\"errors\":[\"request must be a POST\"]}"); diff != "" {
+	if diff := pretty.Compare(rr.Body.String(), "{\"data\":null,\"errors\":[{\"message\":\"request must be a GET or POST\"}]}"); diff != "" {
 		t.Errorf("expected response to match, but received %s", diff)
 	}
 }
@@ -58,7 +88,7 @@ func TestHTTPParseQuery(t *testing.T) {
 		t.Errorf("expected 200, but received %d", rr.Code)
 	}
 
-	if diff := pretty.Compare(rr.Body.String(), "{\"data\":null,\"errors\":[\"request must include a query\"]}"); diff != "" {
+	if diff := pretty.Compare(rr.Body.String(), "{\"data\":null,\"errors\":[{\"message\":\"request must include a query\"}]}"); diff != "" {
 		t.Errorf("expected response to match, but received %s", diff)
 	}
 }
@@ -75,7 +105,7 @@ func TestHTTPMustHaveQuery(t *testing.T) {
 		t.Errorf("expected 200, but received %d", rr.Code)
 	}
 
-	if diff := pretty.Compare(rr.Body.String(), "{\"data\":null,\"errors\":[\"must have a single query\"]}"); diff != "" {
+	if diff := pretty.Compare(rr.Body.String(), "{\"data\":null,\"errors\":[{\"message\":\"must have a single query\"}]}"); diff != "" {
 		t.Errorf("expected response to match, but received %s", diff)
 	}
 }
@@ -97,6 +127,23 @@ func TestHTTPSuccess(t *testing.T) {
 	}
 }
 
+func TestHTTPOperationName(t *testing.T) {
+	req, err := http.NewRequest("POST", "/graphql", strings.NewReader(`{"query": "query A { mirror(value: 1) } query B { mirror(value: 2) }", "operationName": "B"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := testHTTPRequest(req)
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("expected 200, but received %d", rr.Code)
+	}
+
+	if diff := pretty.Compare(rr.Body.String(), "{\"data\":{\"mirror\":-2},\"errors\":null}"); diff != "" {
+		t.Errorf("expected response to match, but received %s", diff)
+	}
+}
+
 func TestHTTPContentType(t *testing.T) {
 	req, err := http.NewRequest("POST", "/graphql", strings.NewReader(`{"query": "query TestQuery($value: int64) { mirror(value: $value) }", "variables": { "value": 1 }}`))
 	if err != nil {
@@ -113,3 +160,220 @@ func TestHTTPContentType(t *testing.T) {
 		t.Errorf("expected response to match, but received %s", diff)
 	}
 }
+
+func TestHTTPFieldErrors(t *testing.T) {
+	for _, tc := range []struct {
+		body     string
+		expected string
+	}{
+		{
+			`{"query": "query Items {\n  items {\n    name\n  }\n}"}`,
+			`{"data": null, "errors": [{
+				"message": "item 2 is hidden",
+				"path": ["items", 1, "name"],
+				"locations": [{"line": 3, "column": 5}],
+				"extensions": {"code": "FORBIDDEN"}
+			}]}`,
+		},
+		{
+			`{"query": "{ broken }"}`,
+			`{"data": null, "errors": [{
+				"message": "Internal server error",
+				"path": ["broken"],
+				"locations": [{"line": 1, "column": 3}]
+			}]}`,
+		},
+	} {
+		req, err := http.NewRequest("POST", "/graphql", strings.NewReader(tc.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr := testHTTPRequest(req)
+
+		if diff := pretty.Compare(internal.ParseJSON(rr.Body.String()), internal.ParseJSON(tc.expected)); diff != "" {
+			t.Errorf("expected response to match, but received %s", diff)
+		}
+	}
+}
+
+func TestHTTPGet(t *testing.T) {
+	params := url.Values{}
+	params.Set("query", "query TestQuery($value: int64) { mirror(value: $value) }")
+	params.Set("variables", `{"value": 1}`)
+	req, err := http.NewRequest("GET", "/graphql?"+params.Encode(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := testHTTPRequest(req)
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("expected 200, but received %d", rr.Code)
+	}
+
+	if diff := pretty.Compare(rr.Body.String(), "{\"data\":{\"mirror\":-1},\"errors\":null}"); diff != "" {
+		t.Errorf("expected response to match, but received %s", diff)
+	}
+}
+
+func TestHTTPPersistedQuery(t *testing.T) {
+	handler := graphql.NewHTTPHandler(testHTTPSchema(), graphql.WithHTTPPersistedQueryStore(graphql.NewMemoryPersistedQueryStore()))
+
+	query := "{ mirror(value: 1) }"
+	sum := sha256.Sum256([]byte(query))
+	extensions := fmt.Sprintf(`{"persistedQuery": {"version": 1, "sha256Hash": "%s"}}`, hex.EncodeToString(sum[:]))
+
+	for _, tc := range []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{
+			"unknown hash",
+			fmt.Sprintf(`{"extensions": %s}`, extensions),
+			`{"data": null, "errors": [{"message": "PersistedQueryNotFound", "extensions": {"code": "PERSISTED_QUERY_NOT_FOUND"}}]}`,
+		},
+		{
+			"hash and query",
+			fmt.Sprintf(`{"query": %q, "extensions": %s}`, query, extensions),
+			`{"data": {"mirror": -1}, "errors": null}`,
+		},
+		{
+			"known hash",
+			fmt.Sprintf(`{"extensions": %s}`, extensions),
+			`{"data": {"mirror": -1}, "errors": null}`,
+		},
+		{
+			"mismatched hash",
+			fmt.Sprintf(`{"query": "{ mirror(value: 2) }", "extensions": %s}`, extensions),
+			`{"data": null, "errors": [{"message": "provided sha does not match query"}]}`,
+		},
+	} {
+		req, err := http.NewRequest("POST", "/graphql", strings.NewReader(tc.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		if diff := pretty.Compare(internal.ParseJSON(rr.Body.String()), internal.ParseJSON(tc.expected)); diff != "" {
+			t.Errorf("%s: expected response to match, but received %s", tc.name, diff)
+		}
+	}
+}
+
+func TestHTTPBatch(t *testing.T) {
+	req, err := http.NewRequest("POST", "/graphql", strings.NewReader(`[
+		{"query": "{ mirror(value: 1) }"},
+		{"query": "query Items { items { id } }"},
+		{"query": "{ broken }"},
+		{"query": "{ mirror(value: 2) }"}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := testHTTPRequest(req)
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("expected 200, but received %d", rr.Code)
+	}
+
+	expected := `[
+		{"data": {"mirror": -1}, "errors": null},
+		{"data": {"items": [{"id": 1}, {"id": 2}]}, "errors": null},
+		{"data": null, "errors": [{"message": "Internal server error", "path": ["broken"], "locations": [{"line": 1, "column": 3}]}]},
+		{"data": {"mirror": -2}, "errors": null}
+	]`
+	if diff := pretty.Compare(internal.ParseJSON(rr.Body.String()), internal.ParseJSON(expected)); diff != "" {
+		t.Errorf("expected response to match, but received %s", diff)
+	}
+}
+
+func TestHTTPBatchSharesBatching(t *testing.T) {
+	var mu sync.Mutex
+	var batches [][]interface{}
+	double := &batch.Func{
+		Many: func(ctx context.Context, args []interface{}) ([]interface{}, error) {
+			mu.Lock()
+			batches = append(batches, args)
+			mu.Unlock()
+
+			results := make([]interface{}, len(args))
+			for i, arg := range args {
+				results[i] = arg.(int64) * 2
+			}
+			return results, nil
+		},
+		WaitInterval: 50 * time.Millisecond,
+		MaxDuration:  time.Second,
+	}
+
+	schema := schemabuilder.NewSchema()
+	schema.Query().FieldFunc("double", func(ctx context.Context, args struct{ Value int64 }) (int64, error) {
+		result, err := double.Invoke(ctx, args.Value)
+		if err != nil {
+			return 0, err
+		}
+		return result.(int64), nil
+	})
+	schema.Mutation()
+
+	req, err := http.NewRequest("POST", "/graphql", strings.NewReader(`[
+		{"query": "{ double(value: 1) }"},
+		{"query": "{ double(value: 2) }"}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr := httptest.NewRecorder()
+	graphql.HTTPHandler(schema.MustBuild()).ServeHTTP(rr, req)
+
+	expected := `[{"data": {"double": 2}, "errors": null}, {"data": {"double": 4}, "errors": null}]`
+	if diff := pretty.Compare(internal.ParseJSON(rr.Body.String()), internal.ParseJSON(expected)); diff != "" {
+		t.Errorf("expected response to match, but received %s", diff)
+	}
+	if len(batches) != 1 || len(batches[0]) != 2 {
+		t.Errorf("expected both operations to share a batch, but received %v", batches)
+	}
+}
+
+func TestHTTPBatchErrors(t *testing.T) {
+	handler := graphql.NewHTTPHandler(testHTTPSchema(), graphql.WithHTTPMaxBatchSize(2))
+
+	for _, tc := range []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{
+			"empty batch",
+			`[]`,
+			`{"data": null, "errors": [{"message": "batch must include at least one operation"}]}`,
+		},
+		{
+			"too many operations",
+			`[{"query": "{ mirror(value: 1) }"}, {"query": "{ mirror(value: 2) }"}, {"query": "{ mirror(value: 3) }"}]`,
+			`{"data": null, "errors": [{"message": "batch must include at most 2 operations"}]}`,
+		},
+		{
+			"bad operation",
+			`[{"query": "{ mirror(value: 1) }"}, null]`,
+			`[{"data": {"mirror": -1}, "errors": null}, {"data": null, "errors": [{"message": "operation must be an object"}]}]`,
+		},
+	} {
+		req, err := http.NewRequest("POST", "/graphql", strings.NewReader(tc.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		if diff := pretty.Compare(internal.ParseJSON(rr.Body.String()), internal.ParseJSON(tc.expected)); diff != "" {
+			t.Errorf("%s: expected response to match, but received %s", tc.name, diff)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package graphql_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/samsarahq/thunder/graphql"
+	"github.com/samsarahq/thunder/graphql/introspection"
+	"github.com/samsarahq/thunder/graphql/schemabuilder"
+	"github.com/samsarahq/thunder/internal"
+	"github.com/samsarahq/thunder/internal/testgraphql"
+)
+
+type Device interface {
+	isDevice()
+}
+
+type Truck struct {
+	Name  string
+	Speed int64
+}
+
+func (*Truck) isDevice() {}
+
+type Tracker struct {
+	Name         string
+	BatteryLevel int64
+}
+
+func (*Tracker) isDevice() {}
+
+func makeInterfaceSchema() *schemabuilder.Schema {
+	schema := schemabuilder.NewSchema()
+	schema.Object("Truck", Truck{})
+	tracker := schema.Object("Tracker", Tracker{})
+	tracker.FieldFunc("paired", func(t *Tracker) Device {
+		return &Truck{Name: "paired to " + t.Name, Speed: 10}
+	})
+	schema.Interface("Device", (*Device)(nil), Truck{}, Tracker{})
+
+	query := schema.Query()
+	query.FieldFunc("devices", func() []Device {
+		return []Device{
+			&Truck{Name: "a", Speed: 50},
+			&Tracker{Name: "b", BatteryLevel: 5},
+			nil,
+		}
+	})
+	query.FieldFunc("truck", func() *Truck {
+		return &Truck{Name: "c", Speed: 20}
+	})
+	return schema
+}
+
+func TestInterfaceType(t *testing.T) {
+	builtSchema := makeInterfaceSchema().MustBuild()
+
+	ctx := context.Background()
+
+	q := graphql.MustParse(`
+		{
+			devices {
+				__typename
+				name
+				... on Truck { speed }
+				... on Tracker { batteryLevel paired { name ... on Truck { speed } } }
+			}
+			truck { ... on Device { name } }
+		}
+	`, nil)
+
+	if err := graphql.PrepareQuery(ctx, builtSchema.Query, q.SelectionSet); err != nil {
+		t.Fatal(err)
+	}
+
+	e := testgraphql.NewExecutorWrapper(t)
+
+	result, err := e.Execute(ctx, builtSchema.Query, nil, q)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if d := pretty.Compare(internal.AsJSON(result), internal.ParseJSON(`
+		{
+			"devices": [
+				{"__typename": "Truck", "name": "a", "speed": 50},
+				{"__typename": "Tracker", "name": "b", "batteryLevel": 5, "paired": {"name": "paired to b", "speed": 10}},
+				null
+			],
+			"truck": {"name": "c"}
+		}`)); d != "" {
+		t.Errorf("expected did not match result: %s", d)
+	}
+}
+
+func TestInterfaceTypeBadQueries(t *testing.T) {
+	builtSchema := makeInterfaceSchema().MustBuild()
+
+	for _, tc := range []struct {
+		query string
+		err   string
+	}{
+		{`{ devices { speed } }`, `unknown field "speed"`},
+		{`{ devices { ... on Query { truck { name } } } }`, `fragment on "Query" can never apply to interface "Device"`},
+		{`{ devices { ... on Truck { batteryLevel } } }`, `unknown field "batteryLevel"`},
+	} {
+		q := graphql.MustParse(tc.query, nil)
+		err := graphql.PrepareQuery(context.Background(), builtSchema.Query, q.SelectionSet)
+		if err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%s: expected error %q, received %v", tc.query, tc.err, err)
+		}
+	}
+}
+
+type NotADevice struct {
+	Name string
+}
+
+func TestInterfaceBadImplementation(t *testing.T) {
+	schema := schemabuilder.NewSchema()
+	schema.Interface("Device", (*Device)(nil), Truck{}, NotADevice{})
+	schema.Query().FieldFunc("device", func() Device { return nil })
+
+	_, err := schema.Build()
+	if err == nil || !strings.Contains(err.Error(), "does not implement") {
+		t.Errorf("expected does not implement error, received %v", err)
+	}
+}
+
+func TestInterfaceIntrospection(t *testing.T) {
+	builtSchema := makeInterfaceSchema().MustBuild()
+	introspection.AddIntrospectionToSchema(builtSchema)
+
+	q := graphql.MustParse(`
+		{
+			device: __type(name: "Device") { kind name fields { name } possibleTypes { name } }
+			truck: __type(name: "Truck") { kind interfaces { name } }
+		}
+	`, nil)
+	if err := graphql.PrepareQuery(context.Background(), builtSchema.Query, q.SelectionSet); err != nil {
+		t.Fatal(err)
+	}
+
+	e := testgraphql.NewExecutorWrapper(t)
+	result, err := e.Execute(context.Background(), builtSchema.Query, nil, q)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if d := pretty.Compare(internal.AsJSON(result), internal.ParseJSON(`
+		{
+			"device": {
+				"kind": "INTERFACE",
+				"name": "Device",
+				"fields": [{"name": "name"}],
+				"possibleTypes": [{"name": "Tracker"}, {"name": "Truck"}]
+			},
+			"truck": {"kind": "OBJECT", "interfaces": [{"name": "Device"}]}
+		}`)); d != "" {
+		t.Errorf("expected did not match result: %s", d)
+	}
+}
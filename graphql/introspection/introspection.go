@@ -11,9 +11,11 @@ import (
 )
 
 type introspection struct {
-	types    map[string]graphql.Type
-	query    graphql.Type
-	mutation graphql.Type
+	types        map[string]graphql.Type
+	query        graphql.Type
+	mutation     graphql.Type
+	subscription graphql.Type
+	directives   map[string]*graphql.DirectiveDefinition
 }
 
 type DirectiveLocation string
@@ -25,6 +27,7 @@ const (
 	FRAGMENT_DEFINITION                   = "FRAGMENT_DEFINITION"
 	FRAGMENT_SPREAD                       = "FRAGMENT_SPREAD"
 	INLINE_FRAGMENT                       = "INLINE_FRAGMENT"
+	FIELD_DEFINITION                      = "FIELD_DEFINITION"
 )
 
 type TypeKind string
@@ -132,6 +135,30 @@ var TypeAsOptionalDirective = Directive{
 	Args: []InputValue{},
 }
 
+var OneOfDirective = Directive{
+	Description: "Indicates that exactly one field of an input object must be set.",
+	Locations: []DirectiveLocation{
+		INPUT_OBJECT,
+	},
+	Name: graphql.ONE_OF,
+	Args: []InputValue{},
+}
+
+var SpecifiedByDirective = Directive{
+	Description: "Exposes a URL that specifies the behavior of a custom scalar.",
+	Locations: []DirectiveLocation{
+		DirectiveLocation(SCALAR),
+	},
+	Name: graphql.SPECIFIED_BY,
+	Args: []InputValue{
+		InputValue{
+			Name:        "url",
+			Type:        Type{Inner: &graphql.NonNull{Type: &graphql.Scalar{Type: "string"}}},
+			Description: "The URL that specifies the behavior of the scalar.",
+		},
+	},
+}
+
 func (s *introspection) registerType(schema *schemabuilder.Schema) {
 	object := schema.Object("__Type", Type{})
 	object.FieldFunc("kind", func(t Type) TypeKind {
@@ -140,6 +167,8 @@ func (s *introspection) registerType(schema *schemabuilder.Schema) {
 			return OBJECT
 		case *graphql.Union:
 			return UNION
+		case *graphql.Interface:
+			return INTERFACE
 		case *graphql.Scalar:
 			return SCALAR
 		case *graphql.Enum:
@@ -161,6 +190,8 @@ func (s *introspection) registerType(schema *schemabuilder.Schema) {
 			return &t.Name
 		case *graphql.Union:
 			return &t.Name
+		case *graphql.Interface:
+			return &t.Name
 		case *graphql.Scalar:
 			return &t.Type
 		case *graphql.Enum:
@@ -178,17 +209,32 @@ func (s *introspection) registerType(schema *schemabuilder.Schema) {
 			return t.Description
 		case *graphql.Union:
 			return t.Description
+		case *graphql.Interface:
+			return t.Description
+		case *graphql.Scalar:
+			return t.Description
 		default:
 			return ""
 		}
 	})
 
-	object.FieldFunc("interfaces", func() []Type { return nil })
-	object.FieldFunc("possibleTypes", func(t Type) []Type {
+	object.FieldFunc("specifiedByURL", func(t Type) *string {
 		switch t := t.Inner.(type) {
-		case *graphql.Union:
-			types := make([]Type, 0, len(t.Types))
-			for _, typ := range t.Types {
+		case *graphql.Scalar:
+			if t.SpecifiedByURL == "" {
+				return nil
+			}
+			return &t.SpecifiedByURL
+		default:
+			return nil
+		}
+	})
+
+	object.FieldFunc("interfaces", func(t Type) []Type {
+		switch t := t.Inner.(type) {
+		case *graphql.Object:
+			types := make([]Type, 0, len(t.Interfaces))
+			for _, typ := range t.Interfaces {
 				types = append(types, Type{Inner: typ})
 			}
 
@@ -198,6 +244,25 @@ func (s *introspection) registerType(schema *schemabuilder.Schema) {
 			return nil
 		}
 	})
+	object.FieldFunc("possibleTypes", func(t Type) []Type {
+		var possibleTypes map[string]*graphql.Object
+		switch t := t.Inner.(type) {
+		case *graphql.Union:
+			possibleTypes = t.Types
+		case *graphql.Interface:
+			possibleTypes = t.Types
+		default:
+			return nil
+		}
+
+		types := make([]Type, 0, len(possibleTypes))
+		for _, typ := range possibleTypes {
+			types = append(types, Type{Inner: typ})
+		}
+
+		sort.Slice(types, func(i, j int) bool { return types[i].Inner.String() < types[j].Inner.String() })
+		return types
+	})
 
 	object.FieldFunc("inputFields", func(t Type) []InputValue {
 		var fields []InputValue
@@ -206,8 +271,9 @@ func (s *introspection) registerType(schema *schemabuilder.Schema) {
 		case *graphql.InputObject:
 			for name, f := range t.InputFields {
 				fields = append(fields, InputValue{
-					Name: name,
-					Type: Type{Inner: f},
+					Name:        name,
+					Description: t.InputFieldDescriptions[name],
+					Type:        Type{Inner: f},
 				})
 			}
 		}
@@ -216,29 +282,52 @@ func (s *introspection) registerType(schema *schemabuilder.Schema) {
 		return fields
 	})
 
+	object.FieldFunc("isOneOf", func(t Type) *bool {
+		switch t := t.Inner.(type) {
+		case *graphql.InputObject:
+			return &t.OneOf
+		default:
+			return nil
+		}
+	})
+
 	object.FieldFunc("fields", func(t Type, args struct {
 		IncludeDeprecated *bool
 	}) []field {
 		var fields []field
 
+		var objectFields map[string]*graphql.Field
 		switch t := t.Inner.(type) {
 		case *graphql.Object:
-			for name, f := range t.Fields {
-				var args []InputValue
-				for name, a := range f.Args {
-					args = append(args, InputValue{
-						Name: name,
-						Type: Type{Inner: a},
-					})
-				}
-				sort.Slice(args, func(i, j int) bool { return args[i].Name < args[j].Name })
+			objectFields = t.Fields
+		case *graphql.Interface:
+			objectFields = t.Fields
+		}
+
+		includeDeprecated := args.IncludeDeprecated != nil && *args.IncludeDeprecated
+		for name, f := range objectFields {
+			if f.IsDeprecated && !includeDeprecated {
+				continue
+			}
 
-				fields = append(fields, field{
-					Name: name,
-					Type: Type{Inner: f.Type},
-					Args: args,
+			var args []InputValue
+			for name, a := range f.Args {
+				args = append(args, InputValue{
+					Name:        name,
+					Type:        Type{Inner: a},
+					Description: f.ArgDescriptions[name],
 				})
 			}
+			sort.Slice(args, func(i, j int) bool { return args[i].Name < args[j].Name })
+
+			fields = append(fields, field{
+				Name:              name,
+				Description:       f.Description,
+				Type:              Type{Inner: f.Type},
+				Args:              args,
+				IsDeprecated:      f.IsDeprecated,
+				DeprecationReason: f.DeprecationReason,
+			})
 		}
 		sort.Slice(fields, func(i, j int) bool { return fields[i].Name < fields[j].Name })
 
@@ -262,11 +351,19 @@ func (s *introspection) registerType(schema *schemabuilder.Schema) {
 
 		switch t := t.Inner.(type) {
 		case *graphql.Enum:
+			includeDeprecated := args.IncludeDeprecated != nil && *args.IncludeDeprecated
 			var enumVals []EnumValue
 			for k, v := range t.ReverseMap {
-				val := fmt.Sprintf("%v", k)
+				reason, deprecated := t.DeprecatedValues[v]
+				if deprecated && !includeDeprecated {
+					continue
+				}
+				description, ok := t.ValueDescriptions[v]
+				if !ok {
+					description = fmt.Sprintf("%v", k)
+				}
 				enumVals = append(enumVals,
-					EnumValue{Name: v, Description: val, IsDeprecated: false, DeprecationReason: ""})
+					EnumValue{Name: v, Description: description, IsDeprecated: deprecated, DeprecationReason: reason})
 			}
 			sort.Slice(enumVals, func(i, j int) bool { return enumVals[i].Name < enumVals[j].Name })
 			return enumVals
@@ -313,6 +410,22 @@ func collectTypes(typ graphql.Type, types map[string]graphql.Type) {
 			collectTypes(graphqlTyp, types)
 		}
 
+	case *graphql.Interface:
+		if _, ok := types[typ.Name]; ok {
+			return
+		}
+		types[typ.Name] = typ
+		for _, field := range typ.Fields {
+			collectTypes(field.Type, types)
+
+			for _, arg := range field.Args {
+				collectTypes(arg, types)
+			}
+		}
+		for _, graphqlTyp := range typ.Types {
+			collectTypes(graphqlTyp, types)
+		}
+
 	case *graphql.List:
 		collectTypes(typ.Type, types)
 
@@ -354,15 +467,26 @@ func (s *introspection) registerQuery(schema *schemabuilder.Schema) {
 		}
 		sort.Slice(types, func(i, j int) bool { return types[i].Inner.String() < types[j].Inner.String() })
 
+		var subscriptionType *Type
+		if s.subscription != nil {
+			subscriptionType = &Type{Inner: s.subscription}
+		}
+
+		directives := []Directive{
+			IncludeDirective,
+			SkipDirective,
+			TypeAsOptionalDirective,
+			OneOfDirective,
+			SpecifiedByDirective,
+		}
+		directives = append(directives, customDirectives(s.directives)...)
+
 		return &Schema{
-			Types:        types,
-			QueryType:    &Type{Inner: s.query},
-			MutationType: &Type{Inner: s.mutation},
-			Directives: []Directive{
-				IncludeDirective,
-				SkipDirective,
-				TypeAsOptionalDirective,
-			},
+			Types:            types,
+			QueryType:        &Type{Inner: s.query},
+			MutationType:     &Type{Inner: s.mutation},
+			SubscriptionType: subscriptionType,
+			Directives:       directives,
 		}
 	})
 
@@ -374,6 +498,35 @@ func (s *introspection) registerQuery(schema *schemabuilder.Schema) {
 	})
 }
 
+// customDirectives returns the custom directives of a schema, sorted by name.
+func customDirectives(definitions map[string]*graphql.DirectiveDefinition) []Directive {
+	directives := make([]Directive, 0, len(definitions))
+	for _, definition := range definitions {
+		locations := make([]DirectiveLocation, 0, len(definition.Locations))
+		for _, location := range definition.Locations {
+			locations = append(locations, DirectiveLocation(location))
+		}
+
+		args := make([]InputValue, 0, len(definition.Args))
+		for name, typ := range definition.Args {
+			args = append(args, InputValue{
+				Name: name,
+				Type: Type{Inner: typ},
+			})
+		}
+		sort.Slice(args, func(i, j int) bool { return args[i].Name < args[j].Name })
+
+		directives = append(directives, Directive{
+			Name:        definition.Name,
+			Description: definition.Description,
+			Locations:   locations,
+			Args:        args,
+		})
+	}
+	sort.Slice(directives, func(i, j int) bool { return directives[i].Name < directives[j].Name })
+	return directives
+}
+
 func (s *introspection) registerMutation(schema *schemabuilder.Schema) {
 	schema.Mutation()
 }
@@ -396,10 +549,20 @@ func BareIntrospectionSchema(schema *graphql.Schema) *graphql.Schema {
 	types := make(map[string]graphql.Type)
 	collectTypes(schema.Query, types)
 	collectTypes(schema.Mutation, types)
+	if schema.Subscription != nil {
+		collectTypes(schema.Subscription, types)
+	}
+	for _, directive := range schema.Directives {
+		for _, arg := range directive.Args {
+			collectTypes(arg, types)
+		}
+	}
 	is := &introspection{
-		types:    types,
-		query:    schema.Query,
-		mutation: schema.Mutation,
+		types:        types,
+		query:        schema.Query,
+		mutation:     schema.Mutation,
+		subscription: schema.Subscription,
+		directives:   schema.Directives,
 	}
 	return is.schema()
 }
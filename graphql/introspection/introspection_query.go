@@ -23,6 +23,7 @@ fragment FullType on __Type {
 	kind
 	name
 	description
+	specifiedByURL
 	fields(includeDeprecated: true) {
 		name
 		description
@@ -38,6 +39,7 @@ fragment FullType on __Type {
 	inputFields {
 		...InputValue
 	}
+	isOneOf
 	interfaces {
 		...TypeRef
 	}
@@ -5,8 +5,10 @@ import (
 	"testing"
 
 	"github.com/samsarahq/go/snapshotter"
+	"github.com/samsarahq/thunder/graphql"
 	"github.com/samsarahq/thunder/graphql/introspection"
 	"github.com/samsarahq/thunder/graphql/schemabuilder"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -117,6 +119,156 @@ func TestComputeSchemaJSON(t *testing.T) {
 	snap.Snapshot("schema", actual)
 }
 
+func TestComputeSchemaJSONCustomDirectives(t *testing.T) {
+	schema := schemabuilder.NewSchema()
+	schema.Query().FieldFunc("me", func() string { return "me" })
+	directive := schema.Directive("auth", struct{ Role string }{}, graphql.DirectiveLocationFieldDefinition)
+	directive.Description = "Restricts a field to users with a role."
+
+	actualBytes, err := introspection.ComputeSchemaJSON(*schema)
+	require.NoError(t, err)
+
+	var actual struct {
+		Schema struct {
+			Directives []struct {
+				Name        string
+				Description string
+				Locations   []string
+				Args        []struct{ Name string }
+			}
+		} `json:"__schema"`
+	}
+	require.NoError(t, json.Unmarshal(actualBytes, &actual))
+
+	directives := actual.Schema.Directives
+	require.NotEmpty(t, directives)
+	auth := directives[len(directives)-1]
+	assert.Equal(t, "auth", auth.Name)
+	assert.Equal(t, "Restricts a field to users with a role.", auth.Description)
+	assert.Equal(t, []string{"FIELD_DEFINITION"}, auth.Locations)
+	require.Len(t, auth.Args, 1)
+	assert.Equal(t, "role", auth.Args[0].Name)
+}
+
+type cents int64
+
+func TestComputeSchemaJSONCustomScalars(t *testing.T) {
+	schema := schemabuilder.NewSchema()
+	scalar := schema.Scalar("Cents", cents(0),
+		func(value interface{}) (interface{}, error) { return int64(value.(cents)), nil },
+		func(value interface{}) (interface{}, error) { return cents(value.(float64)), nil })
+	scalar.Description = "An amount of money in cents."
+	scalar.SpecifiedByURL = "https://example.com/cents"
+	schema.Query().FieldFunc("balance", func() cents { return 0 })
+	schema.Query().FieldFunc("currency", func() string { return "USD" })
+
+	actualBytes, err := introspection.ComputeSchemaJSON(*schema)
+	require.NoError(t, err)
+
+	type typ struct {
+		Kind           string
+		Name           string
+		Description    string
+		SpecifiedByURL *string
+	}
+	var actual struct {
+		Schema struct {
+			Types      []typ
+			Directives []struct{ Name string }
+		} `json:"__schema"`
+	}
+	require.NoError(t, json.Unmarshal(actualBytes, &actual))
+
+	types := make(map[string]typ)
+	for _, t := range actual.Schema.Types {
+		types[t.Name] = t
+	}
+	url := "https://example.com/cents"
+	assert.Equal(t, typ{Kind: "SCALAR", Name: "Cents", Description: "An amount of money in cents.", SpecifiedByURL: &url}, types["Cents"])
+	assert.Equal(t, typ{Kind: "SCALAR", Name: "string"}, types["string"])
+
+	var directives []string
+	for _, directive := range actual.Schema.Directives {
+		directives = append(directives, directive.Name)
+	}
+	assert.Contains(t, directives, "specifiedBy")
+}
+
+type documentedUser struct {
+	Name     string `description:"The full name of the user."`
+	Nickname string `deprecated:"Use name instead."`
+	Login    string `deprecated:""`
+}
+
+type documentedStatus int32
+
+type documentedFilter struct {
+	Name string `description:"A prefix of the name."`
+}
+
+func TestComputeSchemaJSONDescriptionsAndDeprecations(t *testing.T) {
+	schema := schemabuilder.NewSchema()
+	schema.Enum(documentedStatus(0), map[string]documentedStatus{
+		"active":   documentedStatus(0),
+		"inactive": documentedStatus(1),
+		"disabled": documentedStatus(2),
+	},
+		schemabuilder.EnumValueDescription("active", "The user can log in."),
+		schemabuilder.EnumValueDeprecated("disabled", "Use inactive instead."),
+	)
+
+	query := schema.Query()
+	query.FieldFunc("me", func() documentedUser { return documentedUser{} },
+		schemabuilder.Description("The current user."))
+	query.FieldFunc("viewer", func() documentedUser { return documentedUser{} },
+		schemabuilder.Deprecated("Use me instead."))
+	query.FieldFunc("search", func(args struct{ Filter documentedFilter }) documentedStatus {
+		return 0
+	})
+
+	actualBytes, err := introspection.ComputeSchemaJSON(*schema)
+	require.NoError(t, err)
+
+	type field struct {
+		Name              string
+		Description       string
+		IsDeprecated      bool
+		DeprecationReason string
+	}
+	var actual struct {
+		Schema struct {
+			Types []struct {
+				Name        string
+				Fields      []field
+				EnumValues  []field
+				InputFields []field
+			}
+		} `json:"__schema"`
+	}
+	require.NoError(t, json.Unmarshal(actualBytes, &actual))
+
+	types := make(map[string][]field)
+	for _, typ := range actual.Schema.Types {
+		types[typ.Name] = append(append(typ.Fields, typ.EnumValues...), typ.InputFields...)
+	}
+
+	assert.Contains(t, types["Query"], field{Name: "me", Description: "The current user."})
+	assert.Contains(t, types["Query"], field{Name: "viewer", IsDeprecated: true, DeprecationReason: "Use me instead."})
+	assert.Equal(t, []field{
+		{Name: "login", IsDeprecated: true, DeprecationReason: "No longer supported"},
+		{Name: "name", Description: "The full name of the user."},
+		{Name: "nickname", IsDeprecated: true, DeprecationReason: "Use name instead."},
+	}, types["documentedUser"])
+	assert.Equal(t, []field{
+		{Name: "active", Description: "The user can log in."},
+		{Name: "disabled", Description: "2", IsDeprecated: true, DeprecationReason: "Use inactive instead."},
+		{Name: "inactive", Description: "1"},
+	}, types["documentedStatus"])
+	assert.Equal(t, []field{
+		{Name: "name", Description: "A prefix of the name."},
+	}, types["documentedFilter_InputObject"])
+}
+
 // Uuid is a stub version of a "Text Marshalable" type.
 type Uuid struct{}
 
@@ -7,6 +7,7 @@ import (
 type ComputationInput struct {
 	Id                   string
 	Query                string
+	OperationName        string
 	ParsedQuery          *Query
 	Variables            map[string]interface{}
 	Ctx                  context.Context
@@ -17,8 +18,12 @@ type ComputationInput struct {
 
 type ComputationOutput struct {
 	Metadata map[string]interface{}
-	Current  interface{}
-	Error    error
+	// Extensions are sent as the extensions of HTTP responses. Unlike
+	// Metadata, which middlewares use to pass state to each other, every
+	// entry is public.
+	Extensions map[string]interface{}
+	Current    interface{}
+	Error      error
 }
 
 type MiddlewareFunc func(input *ComputationInput, next MiddlewareNextFunc) *ComputationOutput
@@ -29,7 +34,8 @@ func RunMiddlewares(middlewares []MiddlewareFunc, input *ComputationInput) *Comp
 	run = func(index int, middlewares []MiddlewareFunc, input *ComputationInput) *ComputationOutput {
 		if index >= len(middlewares) {
 			return &ComputationOutput{
-				Metadata: make(map[string]interface{}),
+				Metadata:   make(map[string]interface{}),
+				Extensions: make(map[string]interface{}),
 			}
 		}
 
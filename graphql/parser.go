@@ -105,8 +105,9 @@ func argsToJson(input []*ast.Argument, vars map[string]interface{}) (map[string]
 }
 
 // parseSelectionSet takes a grapqhl-go selection set and converts it to a
-// simplified *SelectionSet, bindings vars
-func parseSelectionSet(input *ast.SelectionSet, globalFragments map[string]*Fragment, vars map[string]interface{}) (*SelectionSet, error) {
+// simplified *SelectionSet, bindings vars and recording uses of the variables
+// in definitions
+func parseSelectionSet(input *ast.SelectionSet, globalFragments map[string]*Fragment, vars map[string]interface{}, definitions map[string]*ast.VariableDefinition) (*SelectionSet, error) {
 	if input == nil {
 		return nil, nil
 	}
@@ -131,7 +132,7 @@ func parseSelectionSet(input *ast.SelectionSet, globalFragments map[string]*Frag
 				return nil, err
 			}
 
-			selectionSet, err := parseSelectionSet(selection.SelectionSet, globalFragments, vars)
+			selectionSet, err := parseSelectionSet(selection.SelectionSet, globalFragments, vars, definitions)
 			if err != nil {
 				return nil, err
 			}
@@ -141,6 +142,7 @@ func parseSelectionSet(input *ast.SelectionSet, globalFragments map[string]*Frag
 				Name:         selection.Name.Value,
 				UnparsedArgs: args,
 				SelectionSet: selectionSet,
+				variables:    collectVariableUsages(selection.Arguments, definitions),
 			}
 
 			if len(selection.Directives) > 0 {
@@ -174,7 +176,7 @@ func parseSelectionSet(input *ast.SelectionSet, globalFragments map[string]*Frag
 				return nil, err
 			}
 
-			selectionSet, err := parseSelectionSet(selection.SelectionSet, globalFragments, vars)
+			selectionSet, err := parseSelectionSet(selection.SelectionSet, globalFragments, vars, definitions)
 			if err != nil {
 				return nil, err
 			}
@@ -331,13 +333,28 @@ type Query struct {
 // contains no cycles or unused fragments or immediate conflicts. However, it
 // does not validate that the query is legal under a given schema, which
 // instead is done by PrepareQuery.
+//
+// Parse only accepts documents containing a single operation; use
+// ParseOperation to select one of several operations by name.
 func Parse(source string, vars map[string]interface{}) (*Query, error) {
+	return ParseOperation(source, vars, "")
+}
+
+// ParseOperation parses an input GraphQL string into a *Query for the
+// operation named operationName.
+//
+// If operationName is empty, the document must contain exactly one
+// operation. Fragments used by any of the document's operations are not
+// reported as unused.
+func ParseOperation(source string, vars map[string]interface{}, operationName string) (*Query, error) {
 	document, err := parser.Parse(parser.ParseParams{Source: source})
 	if err != nil {
 		return nil, NewClientError(err.Error())
 	}
 
 	var queryDefinition *ast.OperationDefinition
+	var operationDefinitions []*ast.OperationDefinition
+	operationNames := make(map[string]bool)
 	fragmentDefinitions := make(map[string]*ast.FragmentDefinition)
 
 	for _, definition := range document.Definitions {
@@ -350,23 +367,52 @@ func Parse(source string, vars map[string]interface{}) (*Query, error) {
 			fragmentDefinitions[name] = definition
 
 		case *ast.OperationDefinition:
-			if definition.Operation != "query" && definition.Operation != "mutation" {
-				return nil, NewClientError("only support queries or mutations")
+			switch definition.Operation {
+			case "query", "mutation", "subscription":
+			default:
+				return nil, NewClientError("only support queries, mutations or subscriptions")
 			}
-			if queryDefinition != nil {
-				return nil, NewClientError("only support a single query")
+
+			var name string
+			if definition.Name != nil {
+				name = definition.Name.Value
+			}
+			if name != "" && operationNames[name] {
+				return nil, NewClientError("duplicate operation %q", name)
+			}
+			operationNames[name] = true
+
+			operationDefinitions = append(operationDefinitions, definition)
+			if operationName != "" && name == operationName {
+				queryDefinition = definition
 			}
-			queryDefinition = definition
 
 		default:
 			return nil, NewClientError("unsupported definition")
 		}
 	}
 
-	if queryDefinition == nil {
+	if len(operationDefinitions) == 0 {
 		return nil, NewClientError("must have a single query")
 	}
 
+	if len(operationDefinitions) > 1 {
+		// Anonymous operations are only allowed as the sole operation of a
+		// document.
+		if operationNames[""] {
+			return nil, NewClientError("anonymous operation must be the only operation in the query")
+		}
+		if operationName == "" {
+			return nil, NewClientError("must provide an operation name when the query contains multiple operations")
+		}
+	} else if operationName == "" {
+		queryDefinition = operationDefinitions[0]
+	}
+
+	if queryDefinition == nil {
+		return nil, NewClientError("unknown operation %q", operationName)
+	}
+
 	kind := queryDefinition.Operation
 	var name string
 	if queryDefinition.Name != nil {
@@ -381,13 +427,21 @@ func Parse(source string, vars map[string]interface{}) (*Query, error) {
 
 	// Parse variable definitions, default values, etc.
 	var defaultedVars map[string]interface{}
+	definitions := make(map[string]*ast.VariableDefinition)
 	for _, variableDefinition := range queryDefinition.VariableDefinitions {
 		name := variableDefinition.Variable.Name.Value
+		if _, found := definitions[name]; found {
+			return rv, NewClientError("duplicate variable: $%s", name)
+		}
+		definitions[name] = variableDefinition
 
 		if _, ok := variableDefinition.Type.(*ast.NonNull); ok {
 			if variableDefinition.DefaultValue != nil {
 				return rv, NewClientError("required variable cannot provide a default value: $%s", name)
 			}
+			if vars[name] == nil {
+				return rv, NewClientError(`variable "$%s" of required type "%s" was not provided`, name, astTypeString(variableDefinition.Type))
+			}
 
 			continue
 		}
@@ -406,8 +460,8 @@ func Parse(source string, vars map[string]interface{}) (*Query, error) {
 				}
 			}
 
-			// TODO: properly implement coerceValue.
-			// See: https://github.com/graphql/graphql-js/blob/17a0bfd5292f39cafe4eec5b3bd0e22514243b68/src/execution/values.js#L84
+			// Default values are coerced along with provided values by
+			// PrepareQuery, once the types of their uses are known.
 			val, err := valueToJson(variableDefinition.DefaultValue, nil)
 			if err != nil {
 				return rv, NewClientError("failed to parse default value: %s", err.Error())
@@ -429,19 +483,31 @@ func Parse(source string, vars map[string]interface{}) (*Query, error) {
 	}
 
 	for name, fragment := range fragmentDefinitions {
-		selectionSet, err := parseSelectionSet(fragment.SelectionSet, globalFragments, vars)
+		selectionSet, err := parseSelectionSet(fragment.SelectionSet, globalFragments, vars, definitions)
 		if err != nil {
 			return rv, err
 		}
 		globalFragments[name].SelectionSet = selectionSet
 	}
 
-	selectionSet, err := parseSelectionSet(queryDefinition.SelectionSet, globalFragments, vars)
+	selectionSet, err := parseSelectionSet(queryDefinition.SelectionSet, globalFragments, vars, definitions)
 	if err != nil {
 		return rv, err
 	}
 
-	if err := detectCyclesAndUnusedFragments(selectionSet, globalFragments); err != nil {
+	// Fragments may be used by operations other than the selected one, in
+	// which case they are not unused.
+	usedFragments := make(map[string]*Fragment, len(globalFragments))
+	for name, fragment := range globalFragments {
+		usedFragments[name] = fragment
+	}
+	for _, definition := range operationDefinitions {
+		if definition != queryDefinition {
+			removeSpreadFragments(definition.SelectionSet, fragmentDefinitions, usedFragments)
+		}
+	}
+
+	if err := detectCyclesAndUnusedFragments(selectionSet, usedFragments); err != nil {
 		return rv, err
 	}
 
@@ -454,6 +520,32 @@ func Parse(source string, vars map[string]interface{}) (*Query, error) {
 	return rv, nil
 }
 
+// removeSpreadFragments removes every fragment spread (directly or through
+// other fragments) in a graphql-go selection set from fragments.
+func removeSpreadFragments(input *ast.SelectionSet, fragmentDefinitions map[string]*ast.FragmentDefinition, fragments map[string]*Fragment) {
+	if input == nil {
+		return
+	}
+
+	for _, selection := range input.Selections {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			removeSpreadFragments(selection.SelectionSet, fragmentDefinitions, fragments)
+		case *ast.InlineFragment:
+			removeSpreadFragments(selection.SelectionSet, fragmentDefinitions, fragments)
+		case *ast.FragmentSpread:
+			name := selection.Name.Value
+			if _, ok := fragments[name]; !ok {
+				continue
+			}
+			delete(fragments, name)
+			if definition, ok := fragmentDefinitions[name]; ok {
+				removeSpreadFragments(definition.SelectionSet, fragmentDefinitions, fragments)
+			}
+		}
+	}
+}
+
 func MustParse(source string, vars map[string]interface{}) *Query {
 	query, err := Parse(source, vars)
 	if err != nil {
@@ -481,8 +573,12 @@ func MustParse(source string, vars map[string]interface{}) *Query {
 //
 // Flatten does _not_ flatten out the inner queries, so the name above does not
 // get flattened out yet.
+//
+// Selections are returned in the order their keys first appear in the query,
+// which is the order the fields of a mutation execute in.
 func Flatten(selectionSet *SelectionSet) ([]*Selection, error) {
 	grouped := make(map[string][]*Selection)
+	var aliases []string
 
 	state := make(map[*SelectionSet]visitState)
 	var visit func(*SelectionSet) error
@@ -492,6 +588,18 @@ func Flatten(selectionSet *SelectionSet) ([]*Selection, error) {
 		}
 
 		for _, selection := range selectionSet.Selections {
+			// Excluded occurrences of a field do not affect the others, so
+			// drop them before merging.
+			ok, err := ShouldIncludeNode(selection.Directives)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+			if _, ok := grouped[selection.Alias]; !ok {
+				aliases = append(aliases, selection.Alias)
+			}
 			grouped[selection.Alias] = append(grouped[selection.Alias], selection)
 		}
 
@@ -516,9 +624,17 @@ func Flatten(selectionSet *SelectionSet) ([]*Selection, error) {
 	}
 
 	var flattened []*Selection
-	for _, selections := range grouped {
+	for _, alias := range aliases {
+		selections := grouped[alias]
+		directives := mergeDirectives(selections)
 		if len(selections) == 1 || selections[0].SelectionSet == nil {
-			flattened = append(flattened, selections[0])
+			selection := selections[0]
+			if len(directives) != len(selection.Directives) {
+				copied := *selection
+				copied.Directives = directives
+				selection = &copied
+			}
+			flattened = append(flattened, selection)
 			continue
 		}
 
@@ -535,12 +651,44 @@ func Flatten(selectionSet *SelectionSet) ([]*Selection, error) {
 			UnparsedArgs: selections[0].UnparsedArgs,
 			Args:         selections[0].Args,
 			SelectionSet: merged,
+			Directives:   directives,
 		})
 	}
 
 	return flattened, nil
 }
 
+// mergeDirectives returns the directives of all selections, without
+// repeating identical directives, so that a custom directive used on any of
+// the merged selections applies. The selections must all be included, so the
+// skip and include directives of the others are dropped.
+func mergeDirectives(selections []*Selection) []*Directive {
+	merged := selections[0].Directives
+	for _, selection := range selections[1:] {
+		for _, directive := range selection.Directives {
+			if directive.Name == SKIP || directive.Name == INCLUDE {
+				continue
+			}
+			if !containsDirective(merged, directive) {
+				// Never append to the directives of the first selection.
+				merged = append(merged[:len(merged):len(merged)], directive)
+			}
+		}
+	}
+	return merged
+}
+
+// containsDirective returns if directives has a directive with the name and
+// arguments of directive.
+func containsDirective(directives []*Directive, directive *Directive) bool {
+	for _, other := range directives {
+		if other.Name == directive.Name && reflect.DeepEqual(other.Args, directive.Args) {
+			return true
+		}
+	}
+	return false
+}
+
 /*
 // TODO: precompute flatten
 // TODO: properly typecheck fragments
@@ -175,8 +175,8 @@ func TestParseUnsupported(t *testing.T) {
 {
 	baz
 }`, map[string]interface{}{})
-	if err == nil || err.Error() != "only support a single query" {
-		t.Error("expected multiple queries to fail", err)
+	if err == nil || err.Error() != "anonymous operation must be the only operation in the query" {
+		t.Error("expected multiple anonymous queries to fail", err)
 	}
 
 	_, err = Parse(`
@@ -272,3 +272,73 @@ query Operation($x: int64 = 2) {
 		t.Errorf("expected 2, received %v", val)
 	}
 }
+
+func TestParseOperation(t *testing.T) {
+	source := `
+query First($x: int64) {
+	first: field(x: $x) { ...shared }
+}
+
+query Second {
+	second: field { ...shared ...onlySecond }
+}
+
+mutation Third {
+	third
+}
+
+fragment shared on Field {
+	a
+}
+
+fragment onlySecond on Field {
+	b
+}`
+
+	query, err := ParseOperation(source, map[string]interface{}{"x": float64(1)}, "First")
+	if err != nil {
+		t.Fatal("expected operation to parse, but received", err)
+	}
+	if query.Name != "First" || query.Kind != "query" {
+		t.Errorf("expected query First, received %s %s", query.Kind, query.Name)
+	}
+	if alias := query.SelectionSet.Selections[0].Alias; alias != "first" {
+		t.Errorf("expected first selection, received %s", alias)
+	}
+	if val := query.SelectionSet.Selections[0].UnparsedArgs["x"]; val != float64(1) {
+		t.Errorf("expected 1, received %v", val)
+	}
+
+	query, err = ParseOperation(source, nil, "Third")
+	if err != nil {
+		t.Fatal("expected operation to parse, but received", err)
+	}
+	if query.Name != "Third" || query.Kind != "mutation" {
+		t.Errorf("expected mutation Third, received %s %s", query.Kind, query.Name)
+	}
+
+	_, err = Parse(source, nil)
+	if err == nil || err.Error() != "must provide an operation name when the query contains multiple operations" {
+		t.Error("expected missing operation name to fail", err)
+	}
+
+	_, err = ParseOperation(source, nil, "Fourth")
+	if err == nil || err.Error() != `unknown operation "Fourth"` {
+		t.Error("expected unknown operation to fail", err)
+	}
+
+	_, err = ParseOperation(`
+query First { a }
+query First { b }`, nil, "First")
+	if err == nil || err.Error() != `duplicate operation "First"` {
+		t.Error("expected duplicate operation to fail", err)
+	}
+
+	_, err = ParseOperation(`
+query First { a }
+query Second { b }
+fragment unused on Foo { c }`, nil, "First")
+	if err == nil || err.Error() != "unused fragment" {
+		t.Error("expected unused fragment to fail", err)
+	}
+}
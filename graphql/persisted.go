@@ -0,0 +1,101 @@
+package graphql
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+)
+
+// This file implements Automatic Persisted Queries, as described in
+// https://github.com/apollographql/apollo-link-persisted-queries#protocol.
+//
+// A client sends the sha256 hash of its query in the "persistedQuery"
+// extension instead of the query itself. If the server does not know the hash
+// yet, it responds with ErrPersistedQueryNotFound, and the client retries with
+// both the hash and the query, which the server then stores.
+
+// ErrPersistedQueryNotFound is returned for a request that only sent the hash
+// of a persisted query the PersistedQueryStore does not know.
+var ErrPersistedQueryNotFound = WithErrorCode(NewClientError("PersistedQueryNotFound"), "PERSISTED_QUERY_NOT_FOUND")
+
+// ErrPersistedQueryNotSupported is returned for a request with a persisted
+// query to a server without a PersistedQueryStore.
+var ErrPersistedQueryNotSupported = WithErrorCode(NewClientError("PersistedQueryNotSupported"), "PERSISTED_QUERY_NOT_SUPPORTED")
+
+// PersistedQueryStore stores the text of persisted queries by the hex-encoded
+// sha256 hash of the text.
+type PersistedQueryStore interface {
+	// Get returns the query with the given hash, or false if it is unknown.
+	Get(ctx context.Context, hash string) (string, bool, error)
+	// Put stores query under its hash.
+	Put(ctx context.Context, hash string, query string) error
+}
+
+// NewMemoryPersistedQueryStore returns a PersistedQueryStore that keeps all
+// queries in memory.
+func NewMemoryPersistedQueryStore() PersistedQueryStore {
+	return &memoryPersistedQueryStore{
+		queries: make(map[string]string),
+	}
+}
+
+type memoryPersistedQueryStore struct {
+	mu      sync.RWMutex
+	queries map[string]string
+}
+
+func (s *memoryPersistedQueryStore) Get(ctx context.Context, hash string) (string, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	query, ok := s.queries[hash]
+	return query, ok, nil
+}
+
+func (s *memoryPersistedQueryStore) Put(ctx context.Context, hash string, query string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queries[hash] = query
+	return nil
+}
+
+// resolvePersistedQuery returns the text of the query of a request. If the
+// request has a "persistedQuery" extension, the query is looked up in store
+// when the request has no query text, and stored otherwise.
+func resolvePersistedQuery(ctx context.Context, store PersistedQueryStore, query string, extensions map[string]interface{}) (string, error) {
+	persisted, ok := extensions["persistedQuery"].(map[string]interface{})
+	if !ok {
+		return query, nil
+	}
+	if store == nil {
+		return "", ErrPersistedQueryNotSupported
+	}
+
+	if version, ok := persisted["version"].(float64); !ok || version != 1 {
+		return "", NewClientError("unsupported persisted query version")
+	}
+	hash, ok := persisted["sha256Hash"].(string)
+	if !ok || hash == "" {
+		return "", NewClientError("persisted query must have a sha256Hash")
+	}
+
+	if query == "" {
+		query, ok, err := store.Get(ctx, hash)
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			return "", ErrPersistedQueryNotFound
+		}
+		return query, nil
+	}
+
+	sum := sha256.Sum256([]byte(query))
+	if hex.EncodeToString(sum[:]) != hash {
+		return "", NewClientError("provided sha does not match query")
+	}
+	if err := store.Put(ctx, hash, query); err != nil {
+		return "", err
+	}
+	return query, nil
+}
@@ -130,9 +130,11 @@ func (sb *schemaBuilder) buildBatchFunctionAndFuncCtx(typ reflect.Type, m *metho
 		Batch:                      true,
 		External:                   true,
 		Args:                       args,
+		ArgDescriptions:            funcCtx.argDescriptions,
 		Type:                       retType,
 		ParseArguments:             argParser.Parse,
 		Expensive:                  m.Expensive,
+		Cost:                       m.Cost,
 		NumParallelInvocationsFunc: m.ConcurrencyArgs.numParallelInvocationsFunc,
 	}, funcCtx, nil
 }
@@ -145,6 +147,9 @@ type batchFuncContext struct {
 	hasRet          bool
 	hasError        bool
 
+	// argDescriptions are the descriptions of the args, if any.
+	argDescriptions map[string]string
+
 	enforceNoNilResps bool
 
 	funcType     reflect.Type
@@ -225,6 +230,9 @@ func (funcCtx *batchFuncContext) consumeArgs(sb *schemaBuilder, in []reflect.Typ
 	if err != nil {
 		return nil, nil, in, fmt.Errorf("attempted to parse %s as arguments struct, but failed: %s", inType.Name(), err.Error())
 	}
+	if err := checkArgsNotOneOf(argType); err != nil {
+		return nil, nil, in, err
+	}
 	inputObject, ok := argType.(*graphql.InputObject)
 	if !ok {
 		return nil, nil, nil, fmt.Errorf("%s's args should be an object", funcCtx.funcType)
@@ -234,6 +242,7 @@ func (funcCtx *batchFuncContext) consumeArgs(sb *schemaBuilder, in []reflect.Typ
 		args[name] = typ
 	}
 	funcCtx.hasArgs = true
+	funcCtx.argDescriptions = argDescriptions(argType)
 	return argParser, args, in, nil
 }
 
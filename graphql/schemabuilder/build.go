@@ -17,8 +17,22 @@ type schemaBuilder struct {
 	types        map[reflect.Type]graphql.Type
 	typeNames    map[string]reflect.Type
 	objects      map[reflect.Type]*Object
+	interfaces   map[reflect.Type]*Interface
 	enumMappings map[reflect.Type]*EnumMapping
+	scalars      map[reflect.Type]*customScalar
 	typeCache    map[reflect.Type]cachedType // typeCache maps Go types to GraphQL datatypes
+
+	schemaDirectives map[string]*Directive
+	directives       map[string]*graphql.DirectiveDefinition
+
+	// describeValidations appends the rules of `validate` tags to the
+	// descriptions of input fields.
+	describeValidations bool
+
+	// fieldCache caches the results of Cached fields in fieldCacheStore. It
+	// is created with the first Cached field.
+	fieldCacheStore FieldCacheStore
+	fieldCache      *fieldCache
 }
 
 // EnumMapping is a representation of an enum that includes both the mapping and
@@ -26,6 +40,22 @@ type schemaBuilder struct {
 type EnumMapping struct {
 	Map        map[string]interface{}
 	ReverseMap map[interface{}]string
+
+	// Descriptions maps values to their description, and DeprecationReasons
+	// maps deprecated values to the reason they are deprecated.
+	Descriptions       map[string]string
+	DeprecationReasons map[string]string
+}
+
+// buildEnum returns the graphql.Enum named name with values.
+func (m *EnumMapping) buildEnum(name string, values []string) *graphql.Enum {
+	return &graphql.Enum{
+		Type:              name,
+		Values:            values,
+		ReverseMap:        m.ReverseMap,
+		ValueDescriptions: m.Descriptions,
+		DeprecatedValues:  m.DeprecationReasons,
+	}
 }
 
 // cachedType is a container for GraphQL datatype and the list of its fields
@@ -43,7 +73,18 @@ func (sb *schemaBuilder) getType(nodeType reflect.Type, forceListEntryNonNull bo
 	// Support scalars and optional scalars. Scalars have precedence over structs
 	// to have eg. time.Time function as a scalar.
 	if typeName, values, ok := sb.getEnum(nodeType); ok {
-		return &graphql.NonNull{Type: &graphql.Enum{Type: typeName, Values: values, ReverseMap: sb.enumMappings[nodeType].ReverseMap}}, nil
+		return &graphql.NonNull{Type: sb.enumMappings[nodeType].buildEnum(typeName, values)}, nil
+	}
+
+	// Custom scalars have precedence over the built-in scalars they may be an
+	// alias of.
+	if scalar, ok := sb.getCustomScalar(nodeType); ok {
+		return &graphql.NonNull{Type: scalar}, nil
+	}
+	if nodeType.Kind() == reflect.Ptr {
+		if scalar, ok := sb.getCustomScalar(nodeType.Elem()); ok {
+			return scalar, nil
+		}
 	}
 
 	if typeName, ok := getScalar(nodeType); ok {
@@ -59,6 +100,16 @@ func (sb *schemaBuilder) getType(nodeType reflect.Type, forceListEntryNonNull bo
 		return sb.getTextMarshalerType(nodeType)
 	}
 
+	// Interfaces are always nullable, as a nil interface is a valid value.
+	if nodeType.Kind() == reflect.Interface {
+		if _, ok := sb.interfaces[nodeType]; ok {
+			if err := sb.buildInterface(nodeType); err != nil {
+				return nil, err
+			}
+			return sb.types[nodeType], nil
+		}
+	}
+
 	// Structs
 	if nodeType.Kind() == reflect.Struct {
 		if err := sb.buildStruct(nodeType); err != nil {
@@ -91,7 +142,7 @@ func (sb *schemaBuilder) getType(nodeType reflect.Type, forceListEntryNonNull bo
 		return &graphql.NonNull{Type: &graphql.List{Type: elementType}}, nil
 
 	default:
-		return nil, fmt.Errorf("bad type %s: should be a scalar, slice, or struct type", nodeType)
+		return nil, fmt.Errorf("bad type %s: should be a scalar, slice, struct, or registered interface type", nodeType)
 	}
 }
 
@@ -0,0 +1,362 @@
+package schemabuilder
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/samsarahq/thunder/graphql"
+	"github.com/samsarahq/thunder/reactive"
+)
+
+// Cached is an option that can be passed to a FieldFunc to cache its results
+// across requests for ttl, keyed by the key of the source object and the
+// arguments of the field. Cached fields must be on the Query object or on an
+// object with a key, and cannot be batch or paginated fields or take a
+// selection set.
+//
+// A cached result is dropped once ttl expires or once any reactive dependency
+// added by the FieldFunc is invalidated, and live queries that used it rerun.
+// Cached results are shared between requests, so the FieldFunc must not
+// depend on who makes the request, and its results must not be modified.
+//
+// Cached fields also get a public cache hint with a MaxAge of ttl, unless they
+// are given another with CacheHint. Errors are not cached.
+func Cached(ttl time.Duration) FieldFuncOption {
+	var fieldFuncCached fieldFuncOptionFunc = func(m *method) {
+		m.CacheTTL = ttl
+	}
+	return fieldFuncCached
+}
+
+// CacheHint is an option that can be passed to a FieldFunc to set how long
+// HTTP GET responses including the field may be cached, and by whom. See
+// graphql.ComputeCacheHint.
+func CacheHint(maxAge time.Duration, scope graphql.CacheScope) FieldFuncOption {
+	var fieldFuncCacheHint fieldFuncOptionFunc = func(m *method) {
+		m.CacheHint = &graphql.CacheHint{MaxAge: maxAge, Scope: scope}
+	}
+	return fieldFuncCacheHint
+}
+
+// FieldCacheStore stores the results of Cached fields. Values must be
+// returned as they were stored; they are only valid in the process that
+// stored them. Values that a store drops before their ttl expires keep their
+// dependencies until then, unlike values evicted from the default store.
+type FieldCacheStore interface {
+	// Get returns the value stored for key, if it has not expired.
+	Get(key string) (interface{}, bool)
+	// Set stores value for key until ttl expires.
+	Set(key string, value interface{}, ttl time.Duration)
+	// Delete removes the value stored for key.
+	Delete(key string)
+}
+
+// DefaultFieldCacheSize is the number of results stored by the default
+// FieldCacheStore of a schema.
+const DefaultFieldCacheSize = 10000
+
+// FieldCache sets the store of the results of Cached fields. By default, a
+// schema stores them in an in-memory LRU store of DefaultFieldCacheSize
+// results, created when the schema is built.
+func (s *Schema) FieldCache(store FieldCacheStore) {
+	s.fieldCacheStore = store
+}
+
+// lruFieldCacheStore is an in-memory FieldCacheStore that evicts the least
+// recently used values once it is full.
+type lruFieldCacheStore struct {
+	mu      sync.Mutex
+	size    int
+	entries map[string]*list.Element
+	// order holds *lruFieldCacheEntry, most recently used first.
+	order *list.List
+}
+
+type lruFieldCacheEntry struct {
+	key     string
+	value   interface{}
+	expires time.Time
+}
+
+// NewLRUFieldCacheStore returns an in-memory FieldCacheStore that holds at
+// most size values, evicting the least recently used ones.
+func NewLRUFieldCacheStore(size int) FieldCacheStore {
+	return &lruFieldCacheStore{
+		size:    size,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+func (s *lruFieldCacheStore) Get(key string) (interface{}, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	element, ok := s.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*lruFieldCacheEntry)
+	if time.Now().After(entry.expires) {
+		s.order.Remove(element)
+		delete(s.entries, key)
+		return nil, false
+	}
+	s.order.MoveToFront(element)
+	return entry.value, true
+}
+
+func (s *lruFieldCacheStore) Set(key string, value interface{}, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry := &lruFieldCacheEntry{key: key, value: value, expires: time.Now().Add(ttl)}
+	if element, ok := s.entries[key]; ok {
+		element.Value = entry
+		s.order.MoveToFront(element)
+		return
+	}
+	s.entries[key] = s.order.PushFront(entry)
+	for s.order.Len() > s.size {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		evicted := oldest.Value.(*lruFieldCacheEntry)
+		delete(s.entries, evicted.key)
+		if entry, ok := evicted.value.(*fieldCacheEntry); ok {
+			// The store is called with the field cache locked, which
+			// invalidating the entry may need.
+			go entry.invalidate()
+		}
+	}
+}
+
+func (s *lruFieldCacheStore) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if element, ok := s.entries[key]; ok {
+		s.order.Remove(element)
+		delete(s.entries, key)
+	}
+}
+
+// fieldCache caches the results of the Cached fields of a schema.
+type fieldCache struct {
+	store FieldCacheStore
+
+	mu sync.Mutex
+	// calls holds the computations in progress, so that concurrent requests
+	// for a key compute it once.
+	calls map[string]chan struct{}
+}
+
+func newFieldCache(store FieldCacheStore) *fieldCache {
+	if store == nil {
+		store = NewLRUFieldCacheStore(DefaultFieldCacheSize)
+	}
+	return &fieldCache{
+		store: store,
+		calls: make(map[string]chan struct{}),
+	}
+}
+
+// fieldCacheEntry is a cached result. Computations that use it depend on
+// resource, which is strobed once the result is invalidated.
+type fieldCacheEntry struct {
+	value    interface{}
+	resource *reactive.Resource
+
+	mu          sync.Mutex
+	invalidated bool
+	// release frees the dependencies of the computation of value.
+	release func()
+	timer   *time.Timer
+}
+
+func (e *fieldCacheEntry) isInvalidated() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.invalidated
+}
+
+// invalidate marks the entry invalidated, reruns the computations that used
+// it and frees its dependencies. invalidate returns false if the entry was
+// already invalidated.
+func (e *fieldCacheEntry) invalidate() bool {
+	e.mu.Lock()
+	if e.invalidated {
+		e.mu.Unlock()
+		return false
+	}
+	e.invalidated = true
+	release, timer := e.release, e.timer
+	e.mu.Unlock()
+
+	if timer != nil {
+		timer.Stop()
+	}
+	e.resource.Strobe()
+	if release != nil {
+		release()
+	}
+	return true
+}
+
+// resolve returns the cached result for key, or computes it with f and caches
+// it for ttl.
+func (c *fieldCache) resolve(ctx context.Context, key string, ttl time.Duration, f reactive.ComputeFunc) (interface{}, error) {
+	for {
+		c.mu.Lock()
+		if value, ok := c.store.Get(key); ok {
+			c.mu.Unlock()
+			entry := value.(*fieldCacheEntry)
+			// Depend on the entry before checking it, so that an invalidation
+			// in between is not missed.
+			reactive.AddDependency(ctx, entry.resource, nil)
+			if !entry.isInvalidated() {
+				return entry.value, nil
+			}
+			c.mu.Lock()
+		}
+
+		if done, ok := c.calls[key]; ok {
+			c.mu.Unlock()
+			select {
+			case <-done:
+				continue
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+
+		done := make(chan struct{})
+		c.calls[key] = done
+		c.mu.Unlock()
+
+		value, err := c.compute(ctx, key, ttl, f)
+
+		c.mu.Lock()
+		delete(c.calls, key)
+		c.mu.Unlock()
+		close(done)
+
+		return value, err
+	}
+}
+
+// compute computes and caches the result for key.
+func (c *fieldCache) compute(ctx context.Context, key string, ttl time.Duration, f reactive.ComputeFunc) (interface{}, error) {
+	entry := &fieldCacheEntry{resource: reactive.NewResource()}
+	value, release, err := reactive.Track(ctx, f, func() {
+		c.invalidate(key, entry)
+	})
+	if err != nil {
+		return nil, err
+	}
+	reactive.AddDependency(ctx, entry.resource, nil)
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	entry.value = value
+	if entry.invalidated {
+		// A dependency changed while computing the result, so it is already
+		// stale.
+		release()
+		entry.resource.Strobe()
+		return value, nil
+	}
+	entry.release = release
+	entry.timer = time.AfterFunc(ttl, func() {
+		c.invalidate(key, entry)
+	})
+
+	c.mu.Lock()
+	c.store.Set(key, entry, ttl)
+	c.mu.Unlock()
+	return value, nil
+}
+
+// invalidate drops entry from the cache, and reruns the computations that
+// used it.
+func (c *fieldCache) invalidate(key string, entry *fieldCacheEntry) {
+	if !entry.invalidate() {
+		return
+	}
+
+	c.mu.Lock()
+	if value, ok := c.store.Get(key); ok && value == entry {
+		c.store.Delete(key)
+	}
+	c.mu.Unlock()
+}
+
+// cachedArgs are the parsed arguments of a Cached field, along with their
+// key in the field cache.
+type cachedArgs struct {
+	args interface{}
+	key  string
+}
+
+// buildCachedField wraps the resolver of a Cached field with the field cache
+// of the schema.
+func (sb *schemaBuilder) buildCachedField(typ reflect.Type, object *graphql.Object, name string, m *method, field *graphql.Field) error {
+	isQuery := typ == reflect.TypeOf(query{})
+	if !isQuery && (object.KeyField == nil || typ == reflect.TypeOf(mutation{}) || typ == reflect.TypeOf(subscription{})) {
+		return fmt.Errorf("cached fields must be on Query or on an object with a key")
+	}
+	if m.Batch || m.Paginated {
+		return fmt.Errorf("batch and paginated fields cannot be cached")
+	}
+	fnTyp := reflect.TypeOf(m.Fn)
+	for i := 0; i < fnTyp.NumIn(); i++ {
+		if fnTyp.In(i) == selectionSetType {
+			return fmt.Errorf("fields that take a selection set cannot be cached")
+		}
+	}
+
+	if sb.fieldCache == nil {
+		sb.fieldCache = newFieldCache(sb.fieldCacheStore)
+	}
+	cache, ttl, resolve, parse := sb.fieldCache, m.CacheTTL, field.Resolve, field.ParseArguments
+	keyField := object.KeyField
+
+	// The arguments are keyed by their GraphQL names and values, as not every
+	// field of the parsed arguments is marshaled to JSON.
+	field.ParseArguments = func(args interface{}) (interface{}, error) {
+		parsed, err := parse(args)
+		if err != nil {
+			return nil, err
+		}
+		key, err := json.Marshal(args)
+		if err != nil {
+			return nil, err
+		}
+		return cachedArgs{args: parsed, key: string(key)}, nil
+	}
+
+	field.Resolve = func(ctx context.Context, source, args interface{}, selectionSet *graphql.SelectionSet) (interface{}, error) {
+		cached := args.(cachedArgs)
+		var sourceKey interface{}
+		if !isQuery {
+			var err error
+			if sourceKey, err = keyField.Resolve(ctx, source, nil, nil); err != nil {
+				return nil, err
+			}
+		}
+		sourceKeyJSON, err := json.Marshal(sourceKey)
+		if err != nil {
+			return nil, err
+		}
+		key := fmt.Sprintf("%s.%s:%s:%s", object.Name, name, sourceKeyJSON, cached.key)
+
+		return cache.resolve(ctx, key, ttl, func(ctx context.Context) (interface{}, error) {
+			return resolve(ctx, source, cached.args, selectionSet)
+		})
+	}
+	return nil
+}
@@ -0,0 +1,238 @@
+package schemabuilder_test
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/samsarahq/thunder/graphql"
+	"github.com/samsarahq/thunder/graphql/schemabuilder"
+	"github.com/samsarahq/thunder/internal"
+	"github.com/samsarahq/thunder/reactive"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type cacheOrg struct {
+	Id int64
+}
+
+// cacheBackend counts the calls to the cached fields of its schema.
+type cacheBackend struct {
+	mu       sync.Mutex
+	calls    map[string]int
+	flags    string
+	resource *reactive.Resource
+}
+
+func (b *cacheBackend) call(name string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.calls[name]++
+}
+
+func (b *cacheBackend) count(name string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.calls[name]
+}
+
+func (b *cacheBackend) setFlags(flags string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.flags = flags
+	b.resource.Strobe()
+}
+
+func (b *cacheBackend) schema(ttl time.Duration) *graphql.Schema {
+	schema := schemabuilder.NewSchema()
+	query := schema.Query()
+	query.FieldFunc("org", func(args struct{ Id int64 }) *cacheOrg {
+		return &cacheOrg{Id: args.Id}
+	})
+	query.FieldFunc("settings", func(args struct{ Name string }) string {
+		b.call("settings")
+		return "value of " + args.Name
+	}, schemabuilder.Cached(ttl))
+
+	org := schema.Object("org", cacheOrg{})
+	org.Key("id")
+	org.FieldFunc("flags", func(ctx context.Context, o *cacheOrg) string {
+		b.call("flags")
+		reactive.AddDependency(ctx, b.resource, nil)
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		return b.flags
+	}, schemabuilder.Cached(ttl))
+	return schema.MustBuild()
+}
+
+func newCacheBackend() *cacheBackend {
+	return &cacheBackend{
+		calls:    make(map[string]int),
+		flags:    "a",
+		resource: reactive.NewResource(),
+	}
+}
+
+func executeCacheQuery(t *testing.T, ctx context.Context, schema *graphql.Schema, query string) interface{} {
+	q := graphql.MustParse(query, nil)
+	require.NoError(t, graphql.PrepareQuery(ctx, schema.Query, q.SelectionSet))
+	e := graphql.NewExecutor(graphql.NewImmediateGoroutineScheduler())
+	result, err := e.Execute(ctx, schema.Query, nil, q)
+	require.NoError(t, err)
+	return internal.AsJSON(result)
+}
+
+func TestCachedField(t *testing.T) {
+	b := newCacheBackend()
+	schema := b.schema(time.Hour)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, internal.ParseJSON(`{"a": "value of a", "b": "value of b"}`),
+			executeCacheQuery(t, ctx, schema, `{ a: settings(name: "a") b: settings(name: "b") }`))
+	}
+	assert.Equal(t, 2, b.count("settings"))
+
+	// Results are keyed by the key of their source object.
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, internal.ParseJSON(`{"one": {"__key": 1, "id": 1, "flags": "a"}, "two": {"__key": 2, "id": 2, "flags": "a"}}`),
+			executeCacheQuery(t, ctx, schema, `{ one: org(id: 1) { id flags } two: org(id: 2) { id flags } }`))
+	}
+	assert.Equal(t, 2, b.count("flags"))
+}
+
+func TestCachedFieldInvalidation(t *testing.T) {
+	b := newCacheBackend()
+	schema := b.schema(time.Hour)
+	ctx := context.Background()
+
+	results := make(chan interface{}, 10)
+	runner := reactive.NewRerunner(ctx, func(ctx context.Context) (interface{}, error) {
+		results <- executeCacheQuery(t, ctx, schema, `{ org(id: 1) { flags } }`)
+		return nil, nil
+	}, 0, false)
+	defer runner.Stop()
+
+	assert.Equal(t, internal.ParseJSON(`{"org": {"__key": 1, "flags": "a"}}`), <-results)
+
+	// Invalidating a dependency of the cached result drops it, and reruns the
+	// live query that used it.
+	b.setFlags("b")
+	select {
+	case result := <-results:
+		assert.Equal(t, internal.ParseJSON(`{"org": {"__key": 1, "flags": "b"}}`), result)
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected live query to rerun")
+	}
+	assert.Equal(t, 2, b.count("flags"))
+}
+
+func TestCachedFieldExpires(t *testing.T) {
+	b := newCacheBackend()
+	schema := b.schema(10 * time.Millisecond)
+	ctx := context.Background()
+
+	executeCacheQuery(t, ctx, schema, `{ settings(name: "a") }`)
+	time.Sleep(50 * time.Millisecond)
+	executeCacheQuery(t, ctx, schema, `{ settings(name: "a") }`)
+	assert.Equal(t, 2, b.count("settings"))
+}
+
+func TestCachedFieldStore(t *testing.T) {
+	store := schemabuilder.NewLRUFieldCacheStore(1)
+	var calls int
+	released := make(chan string, 10)
+	schema := schemabuilder.NewSchema()
+	schema.FieldCache(store)
+	schema.Query().FieldFunc("settings", func(ctx context.Context, args struct{ Name string }) string {
+		calls++
+		resource := reactive.NewResource()
+		resource.Cleanup(func() { released <- args.Name })
+		reactive.AddDependency(ctx, resource, nil)
+		return args.Name
+	}, schemabuilder.Cached(time.Hour))
+	built := schema.MustBuild()
+	ctx := context.Background()
+
+	// The store only holds one result, so a evicts b and b evicts a.
+	for _, name := range []string{"a", "b", "a", "a"} {
+		executeCacheQuery(t, ctx, built, `{ settings(name: "`+name+`") }`)
+	}
+	assert.Equal(t, 3, calls)
+
+	// Evicted results release their dependencies.
+	var names []string
+	for len(names) < 2 {
+		select {
+		case name := <-released:
+			names = append(names, name)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("expected evicted results to be released, released %v", names)
+		}
+	}
+	sort.Strings(names)
+	assert.Equal(t, []string{"a", "b"}, names)
+}
+
+func TestCachedFieldArgs(t *testing.T) {
+	b := newCacheBackend()
+	schema := schemabuilder.NewSchema()
+	schema.Query().FieldFunc("greeting", func(args struct {
+		Name   string
+		Suffix string `json:"-"`
+	}) string {
+		b.call("greeting")
+		return args.Name + args.Suffix
+	}, schemabuilder.Cached(time.Hour))
+	built := schema.MustBuild()
+	ctx := context.Background()
+
+	// Arguments are keyed by their GraphQL values, even if they are not
+	// marshaled to JSON.
+	assert.Equal(t, internal.ParseJSON(`{"a": "hi!", "b": "hi?", "c": "hi!"}`),
+		executeCacheQuery(t, ctx, built, `{ a: greeting(name: "hi", suffix: "!") b: greeting(name: "hi", suffix: "?") c: greeting(name: "hi", suffix: "!") }`))
+	assert.Equal(t, 2, b.count("greeting"))
+}
+
+func TestCachedFieldErrors(t *testing.T) {
+	for _, testCase := range []struct {
+		name  string
+		build func(schema *schemabuilder.Schema)
+		err   string
+	}{
+		{
+			name: "object without key",
+			build: func(schema *schemabuilder.Schema) {
+				schema.Query().FieldFunc("org", func() *cacheOrg { return nil })
+				schema.Object("org", cacheOrg{}).FieldFunc("flags", func(o *cacheOrg) string { return "" }, schemabuilder.Cached(time.Hour))
+			},
+			err: "cached fields must be on Query or on an object with a key",
+		},
+		{
+			name: "mutation",
+			build: func(schema *schemabuilder.Schema) {
+				schema.Mutation().FieldFunc("flags", func() string { return "" }, schemabuilder.Cached(time.Hour))
+			},
+			err: "cached fields must be on Query or on an object with a key",
+		},
+		{
+			name: "selection set",
+			build: func(schema *schemabuilder.Schema) {
+				schema.Query().FieldFunc("flags", func(selectionSet *graphql.SelectionSet) string { return "" }, schemabuilder.Cached(time.Hour))
+			},
+			err: "fields that take a selection set cannot be cached",
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			schema := schemabuilder.NewSchema()
+			testCase.build(schema)
+			_, err := schema.Build()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), testCase.err)
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package schemabuilder
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/samsarahq/thunder/graphql"
+)
+
+// A Directive is a custom directive registered on a Schema.
+//
+// For example, a directive that restricts fields to users with a role could
+// be declared as follows:
+//
+//	type authArgs struct {
+//	  Role string
+//	}
+//	auth := s.Directive("auth", authArgs{}, graphql.DirectiveLocationFieldDefinition)
+//	auth.Handler = func(ctx context.Context, args interface{}, next graphql.DirectiveNextFunc) (interface{}, error) {
+//	  if !hasRole(ctx, args.(authArgs).Role) {
+//	    return nil, graphql.NewClientError("forbidden")
+//	  }
+//	  return next(ctx)
+//	}
+//
+// And then applied to a field as:
+//
+//	user.FieldFunc("salary", salary, schemabuilder.ApplyDirective("auth", authArgs{Role: "admin"}))
+type Directive struct {
+	Name        string
+	Description string
+	// Args is a struct whose fields are the arguments of the directive, or
+	// nil if the directive has no arguments.
+	Args      interface{}
+	Locations []graphql.DirectiveLocation
+	// Handler, if set, wraps the resolution of every field the directive is
+	// used on. Its args are a value of the same type as Args.
+	Handler graphql.DirectiveHandler
+}
+
+// Directive registers a custom directive that may be used at locations, whose
+// arguments are the fields of the struct args.
+func (s *Schema) Directive(name string, args interface{}, locations ...graphql.DirectiveLocation) *Directive {
+	if s.directives == nil {
+		s.directives = make(map[string]*Directive)
+	}
+	if _, ok := s.directives[name]; ok {
+		panic("duplicate directive " + name)
+	}
+	switch name {
+	case graphql.SKIP, graphql.INCLUDE, graphql.TYPE_AS_OPTIONAL, graphql.ONE_OF, graphql.SPECIFIED_BY:
+		panic("cannot redefine built-in directive " + name)
+	}
+
+	directive := &Directive{
+		Name:      name,
+		Args:      args,
+		Locations: locations,
+	}
+	s.directives[name] = directive
+	return directive
+}
+
+// appliedDirective is a directive applied to a FieldFunc with ApplyDirective.
+type appliedDirective struct {
+	name string
+	args interface{}
+}
+
+// ApplyDirective is an option that can be passed to a FieldFunc to apply a
+// directive registered on the schema to the field. The directive must allow
+// graphql.DirectiveLocationFieldDefinition, and args must have the same type
+// as the directive's Args.
+func ApplyDirective(name string, args interface{}) FieldFuncOption {
+	var fieldFuncDirective fieldFuncOptionFunc = func(m *method) {
+		m.Directives = append(m.Directives, appliedDirective{name: name, args: args})
+	}
+	return fieldFuncDirective
+}
+
+// buildDirectives builds the definitions of the custom directives of the
+// schema.
+func (sb *schemaBuilder) buildDirectives(directives map[string]*Directive) (map[string]*graphql.DirectiveDefinition, error) {
+	sb.schemaDirectives = directives
+	definitions := make(map[string]*graphql.DirectiveDefinition, len(directives))
+	for name, directive := range directives {
+		definition := &graphql.DirectiveDefinition{
+			Name:        name,
+			Description: directive.Description,
+			Locations:   directive.Locations,
+			Args:        make(map[string]graphql.Type),
+			Handler:     directive.Handler,
+		}
+
+		if directive.Args != nil {
+			parser, argType, err := sb.makeStructParser(reflect.TypeOf(directive.Args))
+			if err != nil {
+				return nil, fmt.Errorf("bad args for directive %s: %s", name, err)
+			}
+			for argName, typ := range argType.(*graphql.InputObject).InputFields {
+				definition.Args[argName] = typ
+			}
+			definition.ParseArguments = parser.Parse
+		}
+
+		definitions[name] = definition
+	}
+	return definitions, nil
+}
+
+// buildAppliedDirectives returns the directives applied to a field.
+func (sb *schemaBuilder) buildAppliedDirectives(applied []appliedDirective) ([]*graphql.Directive, error) {
+	directives := make([]*graphql.Directive, 0, len(applied))
+	for _, a := range applied {
+		definition, ok := sb.directives[a.name]
+		if !ok {
+			return nil, fmt.Errorf("unknown directive %s", a.name)
+		}
+
+		allowed := false
+		for _, location := range definition.Locations {
+			if location == graphql.DirectiveLocationFieldDefinition {
+				allowed = true
+			}
+		}
+		if !allowed {
+			return nil, fmt.Errorf("directive %s may not be applied to fields", a.name)
+		}
+
+		if expected := reflect.TypeOf(sb.schemaDirectives[a.name].Args); reflect.TypeOf(a.args) != expected {
+			return nil, fmt.Errorf("directive %s expects args of type %v, got %T", a.name, expected, a.args)
+		}
+
+		directives = append(directives, &graphql.Directive{
+			Name:       a.name,
+			Definition: definition,
+			ParsedArgs: a.args,
+		})
+	}
+	return directives, nil
+}
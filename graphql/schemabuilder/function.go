@@ -30,6 +30,12 @@ func (sb *schemaBuilder) buildFunction(typ reflect.Type, m *method) (*graphql.Fi
 		}
 	}
 
+	// Fields on the subscription root return streams of events instead of
+	// values.
+	if typ == reflect.TypeOf(subscription{}) {
+		return sb.buildSubscriptionFunction(typ, m)
+	}
+
 	field, _, err := sb.buildFunctionAndFuncCtx(typ, m)
 	return field, err
 }
@@ -91,9 +97,11 @@ func (sb *schemaBuilder) buildFunctionAndFuncCtx(typ reflect.Type, m *method) (*
 
 		},
 		Args:                       args,
+		ArgDescriptions:            argDescriptions(argType),
 		Type:                       retType,
 		ParseArguments:             argParser.Parse,
 		Expensive:                  m.Expensive,
+		Cost:                       m.Cost,
 		External:                   true,
 		NumParallelInvocationsFunc: m.ConcurrencyArgs.numParallelInvocationsFunc,
 	}, funcCtx, nil
@@ -254,6 +262,9 @@ func (funcCtx *funcContext) getArgParserAndTyp(sb *schemaBuilder, in []reflect.T
 		if argParser, argType, err = sb.makeStructParser(in[0]); err != nil {
 			return nil, nil, in, fmt.Errorf("attempted to parse %s as arguments struct, but failed: %s", in[0].Name(), err.Error())
 		}
+		if err := checkArgsNotOneOf(argType); err != nil {
+			return nil, nil, in, err
+		}
 		in = in[1:]
 	}
 	return argParser, argType, in, nil
@@ -346,6 +357,15 @@ func (funcCtx *funcContext) argsTypeMap(argType graphql.Type) (map[string]graphq
 	return args, nil
 }
 
+// argDescriptions returns a map from input arg field names to their
+// description, for the fields that have one.
+func argDescriptions(argType graphql.Type) map[string]string {
+	if inputObject, ok := argType.(*graphql.InputObject); ok && len(inputObject.InputFieldDescriptions) > 0 {
+		return inputObject.InputFieldDescriptions
+	}
+	return nil
+}
+
 // prepareResolveArgs converts the provided source, args and context into the
 // required list of reflect.Value types that the function needs to be called.
 func (funcCtx *funcContext) prepareResolveArgs(source interface{}, hasArgs bool, args interface{}, ctx context.Context, selectionSet *graphql.SelectionSet) []reflect.Value {
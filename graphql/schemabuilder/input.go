@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"strings"
 	"time"
 
 	"github.com/samsarahq/thunder/graphql"
@@ -43,6 +44,9 @@ func (p *dualArgParser) Parse(args interface{}) (interface{}, error) {
 type argField struct {
 	field  reflect.StructField
 	parser *argParser
+	// validator checks the parsed value of the field, if it has a `validate`
+	// tag.
+	validator *fieldValidator
 }
 
 // argParser is a struct that holds information for how to deserialize a JSON
@@ -90,6 +94,9 @@ func (sb *schemaBuilder) makeStructParser(typ reflect.Type) (*argParser, graphql
 			if !ok {
 				return errors.New("not an object")
 			}
+			if argType.OneOf && !graphql.IsOneOfValue(asMap) {
+				return errors.New("exactly one field must be set")
+			}
 
 			for name, field := range fields {
 				value := asMap[name]
@@ -97,6 +104,11 @@ func (sb *schemaBuilder) makeStructParser(typ reflect.Type) (*argParser, graphql
 				if err := field.parser.FromJSON(value, fieldDest); err != nil {
 					return fmt.Errorf("%s: %s", name, err)
 				}
+				if field.validator != nil {
+					if err := field.validator.validate(value != nil, fieldDest); err != nil {
+						return fmt.Errorf("%s: %s", name, err)
+					}
+				}
 			}
 
 			return nil
@@ -116,8 +128,9 @@ func (sb *schemaBuilder) getStructObjectFields(typ reflect.Type) (*graphql.Input
 
 	fields := make(map[string]argField)
 	argType := &graphql.InputObject{
-		Name:        typ.Name(),
-		InputFields: make(map[string]graphql.Type),
+		Name:                   typ.Name(),
+		InputFields:            make(map[string]graphql.Type),
+		InputFieldDescriptions: make(map[string]string),
 	}
 	if argType.Name != "" {
 		argType.Name += "_InputObject"
@@ -132,6 +145,10 @@ func (sb *schemaBuilder) getStructObjectFields(typ reflect.Type) (*graphql.Input
 
 	for i := 0; i < typ.NumField(); i++ {
 		field := typ.Field(i)
+		if field.Anonymous && field.Type == oneOfType {
+			argType.OneOf = true
+			continue
+		}
 		if field.Anonymous {
 			return nil, nil, fmt.Errorf("bad arg type %s: anonymous fields not supported", typ)
 		}
@@ -143,6 +160,9 @@ func (sb *schemaBuilder) getStructObjectFields(typ reflect.Type) (*graphql.Input
 		if fieldInfo.Skipped {
 			continue
 		}
+		if fieldInfo.Deprecated {
+			return nil, nil, fmt.Errorf("bad arg type %s: input field %s cannot be deprecated", typ, fieldInfo.Name)
+		}
 
 		if _, ok := fields[fieldInfo.Name]; ok {
 			return nil, nil, fmt.Errorf("bad arg type %s: duplicate field %s", typ, fieldInfo.Name)
@@ -154,17 +174,50 @@ func (sb *schemaBuilder) getStructObjectFields(typ reflect.Type) (*graphql.Input
 		if fieldInfo.OptionalInputField {
 			parser, fieldArgTyp = wrapWithZeroValue(parser, fieldArgTyp)
 		}
+		validator, err := sb.makeFieldValidator(field)
+		if err != nil {
+			return nil, nil, fmt.Errorf("bad arg type %s: %s", typ, err)
+		}
 
 		fields[fieldInfo.Name] = argField{
-			field:  field,
-			parser: parser,
+			field:     field,
+			parser:    parser,
+			validator: validator,
 		}
 		argType.InputFields[fieldInfo.Name] = fieldArgTyp
+		description := fieldInfo.Description
+		if validator != nil && sb.describeValidations {
+			description = strings.TrimSpace(description + " " + validator.describe())
+		}
+		if description != "" {
+			argType.InputFieldDescriptions[fieldInfo.Name] = description
+		}
+	}
+
+	if argType.OneOf {
+		for name, fieldArgTyp := range argType.InputFields {
+			if _, ok := fieldArgTyp.(*graphql.NonNull); ok {
+				return nil, nil, fmt.Errorf("bad arg type %s: one-of input field %s must be a pointer or optional", typ, name)
+			}
+		}
 	}
 
 	return argType, fields, nil
 }
 
+// errOneOfArgs is returned for args structs that embed OneOf, since GraphQL
+// cannot express one-of arguments.
+var errOneOfArgs = errors.New("args struct cannot embed schemabuilder.OneOf; use a one-of input object as an argument instead")
+
+// checkArgsNotOneOf returns errOneOfArgs if the args struct of a function is
+// a one-of input object.
+func checkArgsNotOneOf(argType graphql.Type) error {
+	if inputObject, ok := argType.(*graphql.InputObject); ok && inputObject.OneOf {
+		return errOneOfArgs
+	}
+	return nil
+}
+
 // makeArgParser reads the information on a passed in variable type and returns
 // an ArgParser that can be used to "fill" that type from a GraphQL JSON input.
 func (sb *schemaBuilder) makeArgParser(typ reflect.Type) (*argParser, graphql.Type, error) {
@@ -191,6 +244,10 @@ func (sb *schemaBuilder) makeArgParserInner(typ reflect.Type) (*argParser, graph
 		return parser, argType, nil
 	}
 
+	if scalar, ok := sb.scalars[typ]; ok {
+		return scalar.parser, scalar.typ, nil
+	}
+
 	if parser, argType, ok := getScalarArgParser(typ); ok {
 		return parser, argType, nil
 	}
@@ -274,7 +331,7 @@ func (sb *schemaBuilder) getEnumArgParser(typ reflect.Type) (*argParser, graphql
 		}
 		dest.Set(reflect.ValueOf(val).Convert(dest.Type()))
 		return nil
-	}, Type: typ}, &graphql.Enum{Type: typ.Name(), Values: values, ReverseMap: sb.enumMappings[typ].ReverseMap}
+	}, Type: typ}, sb.enumMappings[typ].buildEnum(typ.Name(), values)
 
 }
 
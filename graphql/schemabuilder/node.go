@@ -0,0 +1,300 @@
+package schemabuilder
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/samsarahq/thunder/graphql"
+)
+
+// Node is the Go type of the Relay Node interface, which is implemented by
+// every object registered with FetchNodesFromKeys.
+// (https://relay.dev/graphql/objectidentification.htm)
+type Node interface{}
+
+// ID is a scalar holding an opaque global object ID, such as the id of a
+// Node.
+type ID string
+
+var (
+	nodeType = reflect.TypeOf((*Node)(nil)).Elem()
+	idType   = reflect.TypeOf(ID(""))
+)
+
+// FetchNodesFromKeys is an option that can be passed to Object to make the
+// object a Relay Node. The object must have a key, set with Key, which is
+// encoded with the name of the object in the global ID of its id field. The
+// object cannot have an id field of its own, so a key named Id must be
+// exposed under another name.
+//
+// f fetches the objects of a list of keys, in the order of the keys and with
+// nil for missing objects, and is called once per type for the root node and
+// nodes fields. IDs that are malformed or of an unknown type fetch null. It
+// takes the keys as a Keys argument, like the function of FetchObjectFromKeys:
+//
+//	user := s.Object("User", User{}, schemabuilder.FetchNodesFromKeys(
+//	  func(ctx context.Context, args struct{ Keys []int64 }) ([]*User, error) {
+//	    return getUsers(ctx, args.Keys)
+//	  }))
+//	user.Key("id")
+//
+// Clients can then refetch any node with
+//
+//	{ node(id: "VXNlcjox") { id ... on User { name } } }
+func FetchNodesFromKeys(f interface{}) ObjectOption {
+	var fetchNodesFromKeys objectOptionFunc = func(s *Schema, obj *Object) {
+		if s.nodeFetchers == nil {
+			s.nodeFetchers = make(map[string]*method)
+		}
+		if _, ok := s.nodeFetchers[obj.Name]; ok {
+			panic("duplicate node fetcher")
+		}
+		s.nodeFetchers[obj.Name] = &method{Fn: f}
+
+		s.Interface("Node", (*Node)(nil), obj.Type)
+		if _, ok := s.scalars[idType]; !ok {
+			id := s.Scalar("ID", ID(""), serializeID, parseID)
+			id.Description = "An opaque global object ID."
+		}
+	}
+	return fetchNodesFromKeys
+}
+
+func serializeID(value interface{}) (interface{}, error) {
+	return string(value.(ID)), nil
+}
+
+func parseID(value interface{}) (interface{}, error) {
+	asString, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("not a string")
+	}
+	return ID(asString), nil
+}
+
+// buildNodes adds the global id field to every object registered with
+// FetchNodesFromKeys, and the node and nodes fields to queryObject.
+func (sb *schemaBuilder) buildNodes(queryObject *graphql.Object, objects map[string]*Object, methods map[string]*method) error {
+	if len(methods) == 0 {
+		return nil
+	}
+	for _, name := range []string{"node", "nodes"} {
+		if _, ok := queryObject.Fields[name]; ok {
+			return fmt.Errorf("bad node fields: Query already has a %s field", name)
+		}
+	}
+
+	fetchers := make(map[string]*graphql.Field, len(methods))
+	nodes := func(ctx context.Context, ids []ID) ([]Node, error) {
+		return fetchNodes(ctx, fetchers, ids)
+	}
+	nodeField, err := sb.buildFunction(reflect.TypeOf(query{}), &method{
+		Fn: func(ctx context.Context, args struct{ Id ID }) (Node, error) {
+			fetched, err := nodes(ctx, []ID{args.Id})
+			if err != nil {
+				return nil, err
+			}
+			return fetched[0], nil
+		},
+	})
+	if err != nil {
+		return err
+	}
+	nodesField, err := sb.buildFunction(reflect.TypeOf(query{}), &method{
+		Fn: func(ctx context.Context, args struct{ Ids []ID }) ([]Node, error) {
+			return nodes(ctx, args.Ids)
+		},
+	})
+	if err != nil {
+		return err
+	}
+	nodeField.Description = "Fetches an object given its ID."
+	nodesField.Description = "Fetches objects given their IDs, in the order of the IDs."
+	queryObject.Fields["node"] = nodeField
+	queryObject.Fields["nodes"] = nodesField
+
+	for name, m := range methods {
+		object, ok := sb.types[reflect.TypeOf(objects[name].Type)].(*graphql.Object)
+		if !ok {
+			return fmt.Errorf("bad node %s: should be an object", name)
+		}
+		if object.KeyField == nil || object.KeyField.Resolve == nil {
+			return fmt.Errorf("bad node %s: should have a key", name)
+		}
+
+		field, err := sb.buildFunction(reflect.TypeOf(query{}), m)
+		if err != nil {
+			return fmt.Errorf("bad node fetcher for %s: %s", name, err)
+		}
+		if !returnsListOf(field.Type, object) {
+			return fmt.Errorf("bad node fetcher for %s: should return a list of %s", name, name)
+		}
+		if _, ok := field.Args["keys"]; !ok {
+			return fmt.Errorf("bad node fetcher for %s: should have a Keys argument", name)
+		}
+		if _, ok := object.Fields["id"]; ok {
+			return fmt.Errorf("bad node %s: already has an id field, which would be replaced by the global ID", name)
+		}
+		fetchers[name] = field
+		object.Fields["id"] = sb.buildIDField(object)
+	}
+	return nil
+}
+
+// returnsListOf returns if typ is a list of object.
+func returnsListOf(typ graphql.Type, object *graphql.Object) bool {
+	if nonNull, ok := typ.(*graphql.NonNull); ok {
+		typ = nonNull.Type
+	}
+	list, ok := typ.(*graphql.List)
+	if !ok {
+		return false
+	}
+	typ = list.Type
+	if nonNull, ok := typ.(*graphql.NonNull); ok {
+		typ = nonNull.Type
+	}
+	return typ == object
+}
+
+// buildIDField builds the id field of a node, which returns the global ID of
+// the name of object and its key.
+func (sb *schemaBuilder) buildIDField(object *graphql.Object) *graphql.Field {
+	idScalar, _ := sb.getCustomScalar(idType)
+	keyField := object.KeyField
+	keyType := keyField.Type
+	if nonNull, ok := keyType.(*graphql.NonNull); ok {
+		keyType = nonNull.Type
+	}
+	keyScalar := keyType.(*graphql.Scalar)
+
+	return &graphql.Field{
+		Resolve: func(ctx context.Context, source, args interface{}, selectionSet *graphql.SelectionSet) (interface{}, error) {
+			key, err := keyField.Resolve(ctx, source, nil, nil)
+			if err != nil {
+				return nil, err
+			}
+			// The key is encoded as it is in responses.
+			if keyScalar.Unwrapper != nil {
+				if key, err = keyScalar.Unwrapper(key); err != nil {
+					return nil, err
+				}
+			}
+			encoded, err := json.Marshal(key)
+			if err != nil {
+				return nil, err
+			}
+			return encodeID(object.Name, encoded), nil
+		},
+		Type:           &graphql.NonNull{Type: idScalar},
+		ParseArguments: nilParseArguments,
+		Description:    "The global ID of the object.",
+	}
+}
+
+// encodeID returns the global ID of the object of type name with the JSON
+// key.
+func encodeID(name string, key []byte) ID {
+	return ID(base64.StdEncoding.EncodeToString([]byte(name + ":" + string(key))))
+}
+
+// decodeID returns the type name and the JSON key of a global ID.
+func decodeID(id ID) (string, string, error) {
+	decoded, err := base64.StdEncoding.DecodeString(string(id))
+	if err != nil {
+		return "", "", err
+	}
+	parts := strings.SplitN(string(decoded), ":", 2)
+	if len(parts) != 2 {
+		return "", "", fmt.Errorf("missing type")
+	}
+	return parts[0], parts[1], nil
+}
+
+// parseKey parses the JSON key of a global ID with the arguments of fetcher,
+// and returns the parsed arguments and key.
+func parseKey(fetcher *graphql.Field, key string) (interface{}, reflect.Value, error) {
+	var value interface{}
+	if err := json.Unmarshal([]byte(key), &value); err != nil {
+		return nil, reflect.Value{}, err
+	}
+	args, err := fetcher.ParseArguments(map[string]interface{}{"keys": []interface{}{value}})
+	if err != nil {
+		return nil, reflect.Value{}, err
+	}
+	parsed := reflect.ValueOf(args).FieldByName("Keys").Index(0)
+
+	// Arguments parse numbers as float64s, which cannot hold every int64, so
+	// set integer keys from their JSON.
+	switch parsed.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n, err := strconv.ParseInt(key, 10, 64); err == nil && !parsed.OverflowInt(n) {
+			parsed.SetInt(n)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if n, err := strconv.ParseUint(key, 10, 64); err == nil && !parsed.OverflowUint(n) {
+			parsed.SetUint(n)
+		}
+	}
+	return args, parsed, nil
+}
+
+// fetchNodes fetches the nodes of ids, in the order of ids, calling the
+// fetcher of each type once. Invalid ids fetch nil.
+func fetchNodes(ctx context.Context, fetchers map[string]*graphql.Field, ids []ID) ([]Node, error) {
+	args := make(map[string]reflect.Value)
+	indices := make(map[string][]int)
+	for i, id := range ids {
+		name, key, err := decodeID(id)
+		if err != nil {
+			continue
+		}
+		fetcher, ok := fetchers[name]
+		if !ok {
+			continue
+		}
+		// Parse every key on its own, so that it does not fail the other
+		// keys of its type.
+		parsedArgs, parsedKey, err := parseKey(fetcher, key)
+		if err != nil {
+			continue
+		}
+		if _, ok := args[name]; !ok {
+			args[name] = reflect.New(reflect.TypeOf(parsedArgs)).Elem()
+		}
+		keys := args[name].FieldByName("Keys")
+		keys.Set(reflect.Append(keys, parsedKey))
+		indices[name] = append(indices[name], i)
+	}
+
+	var names []string
+	for name := range args {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	nodes := make([]Node, len(ids))
+	for _, name := range names {
+		fetched, err := fetchers[name].Resolve(ctx, nil, args[name].Interface(), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		value := reflect.ValueOf(fetched)
+		if value.Len() != len(indices[name]) {
+			return nil, fmt.Errorf("node fetcher for %s returned %d objects for %d keys", name, value.Len(), len(indices[name]))
+		}
+		for j, i := range indices[name] {
+			if node := value.Index(j); node.Kind() != reflect.Ptr || !node.IsNil() {
+				nodes[i] = node.Interface()
+			}
+		}
+	}
+	return nodes, nil
+}
@@ -0,0 +1,242 @@
+package schemabuilder_test
+
+import (
+	"context"
+	"encoding/base64"
+	"sort"
+	"testing"
+
+	"github.com/samsarahq/thunder/graphql"
+	"github.com/samsarahq/thunder/graphql/schemabuilder"
+	"github.com/samsarahq/thunder/internal"
+	"github.com/samsarahq/thunder/internal/testgraphql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type nodeUser struct {
+	UserId int64
+	Name   string
+}
+
+type nodeTeam struct {
+	Slug string
+}
+
+func makeNodeSchema(fetched map[string][][]string) *graphql.Schema {
+	users := map[int64]*nodeUser{1: {UserId: 1, Name: "alice"}, 2: {UserId: 2, Name: "bob"}}
+
+	schema := schemabuilder.NewSchema()
+	user := schema.Object("User", nodeUser{}, schemabuilder.FetchNodesFromKeys(
+		func(ctx context.Context, args struct{ Keys []int64 }) ([]*nodeUser, error) {
+			var keys []string
+			result := make([]*nodeUser, 0, len(args.Keys))
+			for _, key := range args.Keys {
+				keys = append(keys, users[key].Name)
+				result = append(result, users[key])
+			}
+			fetched["User"] = append(fetched["User"], keys)
+			return result, nil
+		}))
+	user.Key("userId")
+
+	team := schema.Object("Team", nodeTeam{}, schemabuilder.FetchNodesFromKeys(
+		func(args struct{ Keys []string }) []*nodeTeam {
+			fetched["Team"] = append(fetched["Team"], args.Keys)
+			result := make([]*nodeTeam, 0, len(args.Keys))
+			for _, key := range args.Keys {
+				if key == "missing" {
+					result = append(result, nil)
+					continue
+				}
+				result = append(result, &nodeTeam{Slug: key})
+			}
+			return result
+		}))
+	team.Key("slug")
+
+	schema.Query().FieldFunc("users", func() []*nodeUser {
+		return []*nodeUser{users[1], users[2]}
+	})
+	return schema.MustBuild()
+}
+
+func globalID(name, key string) string {
+	return base64.StdEncoding.EncodeToString([]byte(name + ":" + key))
+}
+
+func executeNodeQuery(t *testing.T, schema *graphql.Schema, query string, variables map[string]interface{}) (interface{}, error) {
+	q, err := graphql.Parse(query, variables)
+	require.NoError(t, err)
+	if err := graphql.PrepareQuery(context.Background(), schema.Query, q.SelectionSet); err != nil {
+		return nil, err
+	}
+	e := testgraphql.NewExecutorWrapper(t)
+	result, err := e.Execute(context.Background(), schema.Query, nil, q)
+	return internal.AsJSON(result), err
+}
+
+func TestNodeIDs(t *testing.T) {
+	schema := makeNodeSchema(make(map[string][][]string))
+
+	result, err := executeNodeQuery(t, schema, `{ users { id name } }`, nil)
+	require.NoError(t, err)
+	assert.Equal(t, internal.ParseJSON(`{"users": [
+		{"__key": 1, "id": "`+globalID("User", "1")+`", "name": "alice"},
+		{"__key": 2, "id": "`+globalID("User", "2")+`", "name": "bob"}
+	]}`), result)
+
+	// The Node interface only has the id field, even though every node has
+	// a different key.
+	node := schema.Query.(*graphql.Object).Fields["node"].Type.(*graphql.Interface)
+	var fields []string
+	for name := range node.Fields {
+		fields = append(fields, name)
+	}
+	sort.Strings(fields)
+	assert.Equal(t, []string{"id"}, fields)
+	assert.Equal(t, "ID!", node.Fields["id"].Type.String())
+}
+
+func TestNodeFields(t *testing.T) {
+	fetched := make(map[string][][]string)
+	schema := makeNodeSchema(fetched)
+
+	result, err := executeNodeQuery(t, schema, `query Q($id: ID!) {
+		node(id: $id) { __typename id ... on User { name } }
+	}`, map[string]interface{}{"id": globalID("User", "2")})
+	require.NoError(t, err)
+	assert.Equal(t, internal.ParseJSON(`{"node": {"__typename": "User", "__key": 2, "id": "`+globalID("User", "2")+`", "name": "bob"}}`), result)
+	assert.Equal(t, map[string][][]string{"User": {{"bob"}}}, fetched)
+
+	// nodes fetches each type once, and keeps the order of the ids.
+	delete(fetched, "User")
+	result, err = executeNodeQuery(t, schema, `{
+		nodes(ids: ["`+globalID("Team", `"core"`)+`", "`+globalID("User", "1")+`", "`+globalID("Team", `"missing"`)+`", "`+globalID("User", "2")+`"]) {
+			id
+			... on User { name }
+			... on Team { slug }
+		}
+	}`, nil)
+	require.NoError(t, err)
+	assert.Equal(t, internal.ParseJSON(`{"nodes": [
+		{"__key": "core", "id": "`+globalID("Team", `"core"`)+`", "slug": "core"},
+		{"__key": 1, "id": "`+globalID("User", "1")+`", "name": "alice"},
+		null,
+		{"__key": 2, "id": "`+globalID("User", "2")+`", "name": "bob"}
+	]}`), result)
+	assert.Equal(t, map[string][][]string{
+		"User": {{"alice", "bob"}},
+		"Team": {{"core", "missing"}},
+	}, fetched)
+
+	// Invalid ids fetch null, without failing the other ids.
+	invalid := []string{"not base64!", globalID("Group", "1"), globalID("User", "{"), globalID("User", `"bob"`)}
+	for _, id := range invalid {
+		result, err = executeNodeQuery(t, schema, `query Q($id: ID!) { node(id: $id) { id } }`, map[string]interface{}{"id": id})
+		require.NoError(t, err)
+		assert.Equal(t, internal.ParseJSON(`{"node": null}`), result)
+	}
+
+	delete(fetched, "User")
+	result, err = executeNodeQuery(t, schema, `query Q($ids: [ID!]!) { nodes(ids: $ids) { id } }`, map[string]interface{}{
+		"ids": []interface{}{invalid[0], invalid[1], globalID("User", "1"), invalid[2], invalid[3]},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, internal.ParseJSON(`{"nodes": [null, null, {"__key": 1, "id": "`+globalID("User", "1")+`"}, null, null]}`), result)
+	assert.Equal(t, [][]string{{"alice"}}, fetched["User"])
+}
+
+func TestNodeLargeKeys(t *testing.T) {
+	// 2^53 + 1 cannot be held by a float64.
+	const key = int64(9007199254740993)
+
+	var fetched []int64
+	schema := schemabuilder.NewSchema()
+	user := schema.Object("User", nodeUser{}, schemabuilder.FetchNodesFromKeys(
+		func(args struct{ Keys []int64 }) []*nodeUser {
+			fetched = append(fetched, args.Keys...)
+			result := make([]*nodeUser, 0, len(args.Keys))
+			for _, k := range args.Keys {
+				if k != key {
+					result = append(result, nil)
+					continue
+				}
+				result = append(result, &nodeUser{UserId: k, Name: "large"})
+			}
+			return result
+		}))
+	user.Key("userId")
+	schema.Query().FieldFunc("user", func() *nodeUser {
+		return &nodeUser{UserId: key, Name: "large"}
+	})
+	builtSchema := schema.MustBuild()
+
+	result, err := executeNodeQuery(t, builtSchema, `{ user { id } }`, nil)
+	require.NoError(t, err)
+	id := result.(map[string]interface{})["user"].(map[string]interface{})["id"].(string)
+	assert.Equal(t, globalID("User", "9007199254740993"), id)
+
+	result, err = executeNodeQuery(t, builtSchema, `query Q($id: ID!) { node(id: $id) { ... on User { name } } }`, map[string]interface{}{"id": id})
+	require.NoError(t, err)
+	assert.Equal(t, internal.ParseJSON(`{"node": {"__key": 9007199254740993, "name": "large"}}`), result)
+	assert.Equal(t, []int64{key}, fetched)
+}
+
+func TestNodeErrors(t *testing.T) {
+	fetchUsers := func(args struct{ Keys []int64 }) []*nodeUser { return nil }
+
+	for _, testCase := range []struct {
+		name  string
+		build func(schema *schemabuilder.Schema)
+		err   string
+	}{
+		{
+			name: "existing id field",
+			build: func(schema *schemabuilder.Schema) {
+				team := schema.Object("Team", nodeTeam{}, schemabuilder.FetchNodesFromKeys(
+					func(args struct{ Keys []string }) []*nodeTeam { return nil }))
+				team.Key("slug")
+				team.FieldFunc("id", func(team *nodeTeam) string { return team.Slug })
+			},
+			err: "bad node Team: already has an id field",
+		},
+		{
+			name: "missing key",
+			build: func(schema *schemabuilder.Schema) {
+				schema.Object("User", nodeUser{}, schemabuilder.FetchNodesFromKeys(fetchUsers))
+			},
+			err: "bad node User: should have a key",
+		},
+		{
+			name: "wrong fetcher type",
+			build: func(schema *schemabuilder.Schema) {
+				schema.Object("Team", nodeTeam{}, schemabuilder.FetchNodesFromKeys(fetchUsers)).Key("slug")
+			},
+			err: "bad node fetcher for Team: should return a list of Team",
+		},
+		{
+			name: "missing keys argument",
+			build: func(schema *schemabuilder.Schema) {
+				schema.Object("User", nodeUser{}, schemabuilder.FetchNodesFromKeys(func(args struct{ Ids []int64 }) []*nodeUser { return nil })).Key("userId")
+			},
+			err: "bad node fetcher for User: should have a Keys argument",
+		},
+		{
+			name: "existing node field",
+			build: func(schema *schemabuilder.Schema) {
+				schema.Object("User", nodeUser{}, schemabuilder.FetchNodesFromKeys(fetchUsers)).Key("userId")
+				schema.Query().FieldFunc("node", func() string { return "" })
+			},
+			err: "bad node fields: Query already has a node field",
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			schema := schemabuilder.NewSchema()
+			testCase.build(schema)
+			_, err := schema.Build()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), testCase.err)
+		})
+	}
+}
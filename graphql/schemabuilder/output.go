@@ -73,6 +73,9 @@ func (sb *schemaBuilder) buildStruct(typ reflect.Type) error {
 		if err != nil {
 			return fmt.Errorf("bad field %s on type %s: %s", fieldInfo.Name, typ, err)
 		}
+		built.Description = fieldInfo.Description
+		built.IsDeprecated = fieldInfo.Deprecated
+		built.DeprecationReason = fieldInfo.DeprecationReason
 		object.Fields[fieldInfo.Name] = built
 		if fieldInfo.KeyField {
 			if object.KeyField != nil {
@@ -135,6 +138,22 @@ func (sb *schemaBuilder) buildStruct(typ reflect.Type) error {
 		object.Fields[name] = built
 	}
 
+	for _, name := range names {
+		method, field := methods[name], object.Fields[name]
+		field.Description = method.Description
+		field.IsDeprecated = method.Deprecated
+		field.DeprecationReason = method.DeprecationReason
+
+		if len(method.Directives) == 0 {
+			continue
+		}
+		directives, err := sb.buildAppliedDirectives(method.Directives)
+		if err != nil {
+			return fmt.Errorf("bad method %s on type %s: %s", name, typ, err)
+		}
+		field.Directives = directives
+	}
+
 	if objectKey != "" {
 		keyPtr, ok := object.Fields[objectKey]
 		if !ok {
@@ -147,6 +166,20 @@ func (sb *schemaBuilder) buildStruct(typ reflect.Type) error {
 		object.KeyField = keyPtr
 	}
 
+	for _, name := range names {
+		method, field := methods[name], object.Fields[name]
+		field.CacheHint = method.CacheHint
+		if method.CacheTTL <= 0 {
+			continue
+		}
+		if field.CacheHint == nil {
+			field.CacheHint = &graphql.CacheHint{MaxAge: method.CacheTTL, Scope: graphql.CacheScopePublic}
+		}
+		if err := sb.buildCachedField(typ, object, name, method, field); err != nil {
+			return fmt.Errorf("bad method %s on type %s: %s", name, typ, err)
+		}
+	}
+
 	return nil
 }
 
@@ -216,6 +249,121 @@ func (sb *schemaBuilder) buildUnionStruct(typ reflect.Type) error {
 	return nil
 }
 
+// buildInterface builds a graphql.Interface type for a registered Go
+// interface type.  The interface's possible types are built immediately, but
+// its fields are only computed by finishInterfaces once every type in the
+// schema has been built.
+func (sb *schemaBuilder) buildInterface(typ reflect.Type) error {
+	if sb.types[typ] != nil {
+		return nil
+	}
+
+	iface := sb.interfaces[typ]
+	if originalType, ok := sb.typeNames[iface.Name]; ok {
+		return fmt.Errorf("duplicate name %s: seen both %v and %v", iface.Name, originalType, typ)
+	}
+
+	built := &graphql.Interface{
+		Name:        iface.Name,
+		Description: iface.Description,
+		Fields:      make(map[string]*graphql.Field),
+		Types:       make(map[string]*graphql.Object),
+	}
+	sb.types[typ] = built
+	sb.typeNames[iface.Name] = typ
+
+	typesByGoType := make(map[reflect.Type]string, len(iface.Types))
+	for _, impl := range iface.Types {
+		implType := reflect.TypeOf(impl)
+		if implType.Kind() == reflect.Ptr {
+			implType = implType.Elem()
+		}
+		if implType.Kind() != reflect.Struct {
+			return fmt.Errorf("bad interface %s: implementation %s should be a struct", iface.Name, implType)
+		}
+		if !implType.Implements(typ) && !reflect.PtrTo(implType).Implements(typ) {
+			return fmt.Errorf("bad interface %s: %s does not implement %s", iface.Name, implType, typ)
+		}
+
+		// Pass forceListEntryNonNull as true to keep backward compatibility.
+		implGraphQLType, err := sb.getType(reflect.PtrTo(implType), true)
+		if err != nil {
+			return err
+		}
+		obj, ok := implGraphQLType.(*graphql.Object)
+		if !ok {
+			return fmt.Errorf("bad interface %s: implementation must be an object, received %s", iface.Name, implGraphQLType.String())
+		}
+		if built.Types[obj.Name] != nil {
+			return fmt.Errorf("bad interface %s: implementation %s may only appear once", iface.Name, obj.Name)
+		}
+
+		built.Types[obj.Name] = obj
+		typesByGoType[implType] = obj.Name
+	}
+
+	built.ResolveType = func(value interface{}) (string, error) {
+		valueType := reflect.TypeOf(value)
+		if valueType != nil && valueType.Kind() == reflect.Ptr {
+			valueType = valueType.Elem()
+		}
+		name, ok := typesByGoType[valueType]
+		if !ok {
+			return "", fmt.Errorf("%v is not a registered implementation of interface %s", valueType, iface.Name)
+		}
+		return name, nil
+	}
+
+	return nil
+}
+
+// finishInterfaces computes the fields of every built interface: the fields
+// that every possible type has with identical types.  It also records on
+// each possible type that it implements the interface.
+func (sb *schemaBuilder) finishInterfaces() error {
+	for typ := range sb.interfaces {
+		built, ok := sb.types[typ].(*graphql.Interface)
+		if !ok {
+			// The interface isn't reachable from the schema.
+			continue
+		}
+		if len(built.Types) == 0 {
+			return fmt.Errorf("bad interface %s: must have at least one implementation", built.Name)
+		}
+
+		var names []string
+		for name := range built.Types {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		first := built.Types[names[0]]
+		for fieldName, field := range first.Fields {
+			// The Node interface only has the id field, so that adding a
+			// field to one node does not change it.
+			shared := typ != nodeType || fieldName == "id"
+			for _, name := range names[1:] {
+				other, ok := built.Types[name].Fields[fieldName]
+				if !ok || other.Type.String() != field.Type.String() {
+					shared = false
+					break
+				}
+			}
+			if shared {
+				built.Fields[fieldName] = field
+			}
+		}
+
+		for _, obj := range built.Types {
+			if obj.Interfaces == nil {
+				obj.Interfaces = make(map[string]*graphql.Interface)
+			}
+			obj.Interfaces[built.Name] = built
+		}
+	}
+	return nil
+}
+
 // isScalarType returns whether a graphql.Type is a scalar type (or a non-null
 // wrapped scalar type).
 func isScalarType(typ graphql.Type) bool {
@@ -1134,11 +1134,13 @@ func (sb *schemaBuilder) buildPaginatedFieldWithFallback(typ reflect.Type, m *me
 		},
 		Type:                       manualPaginationField.Type,
 		Args:                       manualPaginationField.Args,
+		ArgDescriptions:            manualPaginationField.ArgDescriptions,
 		ParseArguments:             dualParser.Parse,
 		UseBatchFunc:               manualPaginationField.UseBatchFunc,
 		Batch:                      manualPaginationField.Batch,
 		External:                   manualPaginationField.External,
 		Expensive:                  manualPaginationField.Expensive,
+		Cost:                       manualPaginationField.Cost,
 		NumParallelInvocationsFunc: manualPaginationField.NumParallelInvocationsFunc,
 	}
 
@@ -1240,9 +1242,11 @@ func (sb *schemaBuilder) buildPaginatedFunctionAndFuncCtx(typ reflect.Type, m *m
 
 		},
 		Args:                       args,
+		ArgDescriptions:            argDescriptions(argType),
 		Type:                       retType,
 		ParseArguments:             argParser.Parse,
 		Expensive:                  m.Expensive,
+		Cost:                       m.Cost,
 		External:                   true,
 		NumParallelInvocationsFunc: m.ConcurrencyArgs.numParallelInvocationsFunc,
 	}
@@ -1327,6 +1331,9 @@ func (sb *schemaBuilder) buildEmbeddedPaginatedArgParser(typ reflect.Type) (*arg
 			pagArgIndex = i
 			continue
 		}
+		if field.Type == oneOfType {
+			return nil, nil, errOneOfArgs
+		}
 
 		name := makeGraphql(field.Name)
 
@@ -1447,6 +1454,9 @@ func (sb *schemaBuilder) buildPaginatedArgParser(originalArgType reflect.Type) (
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to build args for paginated field")
 		}
+		if err := checkArgsNotOneOf(nestedArgType); err != nil {
+			return nil, nil, err
+		}
 		userInputObject, ok := nestedArgType.(*graphql.InputObject)
 		if !ok {
 			return nil, nil, fmt.Errorf("args should be an object")
@@ -53,6 +53,15 @@ type graphQLFieldInfo struct {
 	// OptionalInputField indicates that this field should be treated as an optional
 	// field on graphQL input args.
 	OptionalInputField bool
+
+	// Description is the description of the field, from its `description`
+	// tag.
+	Description string
+
+	// Deprecated indicates that the field has a `deprecated` tag, whose value
+	// is the DeprecationReason.
+	Deprecated        bool
+	DeprecationReason string
 }
 
 // parseGraphQLFieldInfo parses a struct field and returns a struct with the
@@ -87,7 +96,21 @@ func parseGraphQLFieldInfo(field reflect.StructField) (*graphQLFieldInfo, error)
 			}
 		}
 	}
-	return &graphQLFieldInfo{Name: name, KeyField: key, OptionalInputField: optional}, nil
+
+	deprecation, deprecated := field.Tag.Lookup("deprecated")
+	var reason string
+	if deprecated {
+		reason = deprecationReason(deprecation)
+	}
+
+	return &graphQLFieldInfo{
+		Name:               name,
+		KeyField:           key,
+		OptionalInputField: optional,
+		Description:        field.Tag.Get("description"),
+		Deprecated:         deprecated,
+		DeprecationReason:  reason,
+	}, nil
 }
 
 // Common Types that we will need to perform type assertions against.
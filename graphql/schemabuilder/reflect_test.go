@@ -682,6 +682,52 @@ func TestBadArguments(t *testing.T) {
 	}
 }
 
+type oneOfFilter struct {
+	OneOf
+	Id   *int64
+	Name *string
+}
+
+type oneOfArgs struct {
+	Filter oneOfFilter
+}
+
+func TestOneOfArgParser(t *testing.T) {
+	sb := &schemaBuilder{
+		typeCache: make(map[reflect.Type]cachedType, 0),
+	}
+	parser, argType, err := sb.makeArgParser(reflect.TypeOf(oneOfArgs{}))
+	require.NoError(t, err)
+	filterType := argType.(*graphql.NonNull).Type.(*graphql.InputObject).InputFields["filter"].(*graphql.NonNull).Type.(*graphql.InputObject)
+	assert.True(t, filterType.OneOf)
+	assert.Len(t, filterType.InputFields, 2)
+
+	var ten = int64(10)
+	testArgParseOk(t, parser, internal.ParseJSON(`{"filter": {"id": 10}}`), oneOfArgs{Filter: oneOfFilter{Id: &ten}})
+	testArgParseBad(t, parser, internal.ParseJSON(`{"filter": {}}`))
+	testArgParseBad(t, parser, internal.ParseJSON(`{"filter": {"id": null}}`))
+	testArgParseBad(t, parser, internal.ParseJSON(`{"filter": {"id": 10, "name": "bob"}}`))
+}
+
+func TestBadOneOfArguments(t *testing.T) {
+	type required struct {
+		OneOf
+		Id int64
+	}
+
+	schema := NewSchema()
+	schema.Query().FieldFunc("required", func(args struct{ Filter required }) int64 { return 0 })
+	_, err := schema.Build()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "one-of input field id must be a pointer or optional")
+
+	schema = NewSchema()
+	schema.Query().FieldFunc("args", func(args oneOfFilter) int64 { return 0 })
+	_, err = schema.Build()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), errOneOfArgs.Error())
+}
+
 func TestObjectKeyMustBeScalar(t *testing.T) {
 	t.Run("struct key tag", func(t *testing.T) {
 		type key struct{ Name string }
@@ -0,0 +1,175 @@
+package schemabuilder
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/samsarahq/thunder/graphql"
+)
+
+// A Scalar is a custom scalar registered on a Schema.
+//
+// For example, a point on the globe sent as a [lat, lng] pair could be
+// declared as follows:
+//
+//	type GeoPoint struct {
+//	  Lat, Lng float64
+//	}
+//	geoPoint := s.Scalar("GeoPoint", GeoPoint{},
+//	  func(value interface{}) (interface{}, error) {
+//	    point := value.(GeoPoint)
+//	    return []float64{point.Lat, point.Lng}, nil
+//	  },
+//	  func(value interface{}) (interface{}, error) {
+//	    pair, ok := value.([]interface{})
+//	    if !ok || len(pair) != 2 {
+//	      return nil, errors.New("expected a [lat, lng] pair")
+//	    }
+//	    lat, latOk := pair[0].(float64)
+//	    lng, lngOk := pair[1].(float64)
+//	    if !latOk || !lngOk {
+//	      return nil, errors.New("expected a [lat, lng] pair")
+//	    }
+//	    return GeoPoint{Lat: lat, Lng: lng}, nil
+//	  })
+//	geoPoint.Description = "A point on the globe, as a [lat, lng] pair."
+//
+// Fields and arguments of type GeoPoint or *GeoPoint are then of the GeoPoint
+// scalar type.
+type Scalar struct {
+	Name        string
+	Description string
+	// SpecifiedByURL, if set, links to the specification of the format of
+	// the scalar.
+	SpecifiedByURL string
+
+	// Type is a value of the Go type of the scalar.
+	Type interface{}
+	// Serialize converts a value of Type to the JSON value of the scalar in
+	// responses, such as a string, a number, a slice or a map.
+	Serialize func(value interface{}) (interface{}, error)
+	// Parse converts the JSON value of an argument, as decoded by
+	// encoding/json, to a value of Type.
+	Parse func(value interface{}) (interface{}, error)
+}
+
+// Scalar registers the Go type of typ as a custom scalar named name, which is
+// converted to and from JSON with serialize and parse. Custom scalars take
+// precedence over the built-in scalars they are an alias of, and over
+// encoding.TextMarshaler and encoding.TextUnmarshaler.
+func (s *Schema) Scalar(name string, typ interface{}, serialize func(value interface{}) (interface{}, error), parse func(value interface{}) (interface{}, error)) *Scalar {
+	t := reflect.TypeOf(typ)
+	if t == nil || t.Kind() == reflect.Ptr || t.Kind() == reflect.Interface {
+		panic("scalar type should be a value, e.g. GeoPoint{}")
+	}
+	if _, ok := scalars[t]; ok {
+		panic("cannot redefine built-in scalar " + t.String())
+	}
+	if s.scalars == nil {
+		s.scalars = make(map[reflect.Type]*Scalar)
+	}
+	if _, ok := s.scalars[t]; ok {
+		panic("duplicate scalar for " + t.String())
+	}
+
+	scalar := &Scalar{
+		Name:      name,
+		Type:      typ,
+		Serialize: serialize,
+		Parse:     parse,
+	}
+	s.scalars[t] = scalar
+	return scalar
+}
+
+// customScalar is a custom scalar as built for a schema.
+type customScalar struct {
+	typ    *graphql.Scalar
+	parser *argParser
+}
+
+// buildScalars builds the custom scalars registered on a schema.
+func (sb *schemaBuilder) buildScalars(scalars map[reflect.Type]*Scalar) error {
+	sb.scalars = make(map[reflect.Type]*customScalar, len(scalars))
+	for typ, scalar := range scalars {
+		if scalar.Name == "" {
+			return fmt.Errorf("bad scalar %s: should have a name", typ)
+		}
+		if _, ok := builtinScalarNames[scalar.Name]; ok {
+			return fmt.Errorf("bad scalar %s: %s is a built-in scalar", typ, scalar.Name)
+		}
+		if originalType, ok := sb.typeNames[scalar.Name]; ok {
+			return fmt.Errorf("duplicate name %s: seen both %v and %v", scalar.Name, originalType, typ)
+		}
+		if scalar.Serialize == nil || scalar.Parse == nil {
+			return fmt.Errorf("bad scalar %s: serialize and parse must be set", scalar.Name)
+		}
+		sb.typeNames[scalar.Name] = typ
+
+		sb.scalars[typ] = &customScalar{
+			typ:    buildScalarType(scalar),
+			parser: makeScalarParser(typ, scalar),
+		}
+	}
+	return nil
+}
+
+// buildScalarType returns the graphql.Scalar of a custom scalar, which
+// serializes values of its type and pointers to them.
+func buildScalarType(scalar *Scalar) *graphql.Scalar {
+	serialize := scalar.Serialize
+	return &graphql.Scalar{
+		Type:           scalar.Name,
+		Description:    scalar.Description,
+		SpecifiedByURL: scalar.SpecifiedByURL,
+		Unwrapper: func(source interface{}) (interface{}, error) {
+			value := reflect.ValueOf(source)
+			if value.Kind() == reflect.Ptr {
+				if value.IsNil() {
+					return nil, nil
+				}
+				value = value.Elem()
+			}
+			return serialize(value.Interface())
+		},
+	}
+}
+
+// makeScalarParser returns an argParser that parses the JSON values of a
+// custom scalar.
+func makeScalarParser(typ reflect.Type, scalar *Scalar) *argParser {
+	parse := scalar.Parse
+	return &argParser{
+		FromJSON: func(value interface{}, dest reflect.Value) error {
+			parsed, err := parse(value)
+			if err != nil {
+				return err
+			}
+			parsedValue := reflect.ValueOf(parsed)
+			if !parsedValue.IsValid() || parsedValue.Type() != typ {
+				return fmt.Errorf("scalar %s parsed %T, expected %s", scalar.Name, parsed, typ)
+			}
+			dest.Set(parsedValue)
+			return nil
+		},
+		Type: typ,
+	}
+}
+
+// getCustomScalar returns the graphql.Scalar of typ, if it is a custom scalar.
+func (sb *schemaBuilder) getCustomScalar(typ reflect.Type) (*graphql.Scalar, bool) {
+	scalar, ok := sb.scalars[typ]
+	if !ok {
+		return nil, false
+	}
+	return scalar.typ, true
+}
+
+// builtinScalarNames are the names of the built-in scalars.
+var builtinScalarNames = func() map[string]struct{} {
+	names := make(map[string]struct{}, len(scalars))
+	for _, name := range scalars {
+		names[name] = struct{}{}
+	}
+	return names
+}()
@@ -0,0 +1,187 @@
+package schemabuilder_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/samsarahq/thunder/graphql"
+	"github.com/samsarahq/thunder/graphql/schemabuilder"
+	"github.com/samsarahq/thunder/internal"
+	"github.com/samsarahq/thunder/internal/testgraphql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type geoPoint struct {
+	Lat, Lng float64
+}
+
+// cents is an alias of a built-in scalar, which is sent as a string.
+type cents int64
+
+type place struct {
+	Name     string
+	Location geoPoint
+	Entrance *geoPoint
+	Price    cents
+}
+
+type placeFilter struct {
+	Near     *geoPoint
+	MaxPrice *cents
+}
+
+func registerScalars(schema *schemabuilder.Schema) {
+	geo := schema.Scalar("GeoPoint", geoPoint{},
+		func(value interface{}) (interface{}, error) {
+			point := value.(geoPoint)
+			return []float64{point.Lat, point.Lng}, nil
+		},
+		func(value interface{}) (interface{}, error) {
+			pair, ok := value.([]interface{})
+			if !ok || len(pair) != 2 {
+				return nil, errors.New("expected a [lat, lng] pair")
+			}
+			lat, latOk := pair[0].(float64)
+			lng, lngOk := pair[1].(float64)
+			if !latOk || !lngOk {
+				return nil, errors.New("expected a [lat, lng] pair")
+			}
+			return geoPoint{Lat: lat, Lng: lng}, nil
+		})
+	geo.Description = "A point on the globe, as a [lat, lng] pair."
+
+	schema.Scalar("Cents", cents(0),
+		func(value interface{}) (interface{}, error) {
+			return fmt.Sprint(int64(value.(cents))), nil
+		},
+		func(value interface{}) (interface{}, error) {
+			var amount int64
+			if _, err := fmt.Sscan(fmt.Sprint(value), &amount); err != nil {
+				return nil, errors.New("expected an amount of cents")
+			}
+			return cents(amount), nil
+		})
+}
+
+func makeScalarSchema() *graphql.Schema {
+	schema := schemabuilder.NewSchema()
+	registerScalars(schema)
+
+	query := schema.Query()
+	query.FieldFunc("places", func() []*place {
+		return []*place{
+			{Name: "park", Location: geoPoint{Lat: 1, Lng: 2}, Price: 0},
+			{Name: "museum", Location: geoPoint{Lat: 3, Lng: 4}, Entrance: &geoPoint{Lat: 3.5, Lng: 4}, Price: 1250},
+		}
+	})
+	query.FieldFunc("distance", func(args struct {
+		From geoPoint
+		To   *geoPoint
+	}) float64 {
+		if args.To == nil {
+			return 0
+		}
+		return args.To.Lat - args.From.Lat
+	})
+	query.FieldFunc("search", func(args struct{ Filter placeFilter }) string {
+		return fmt.Sprintf("near %v under %v", args.Filter.Near, *args.Filter.MaxPrice)
+	})
+	return schema.MustBuild()
+}
+
+func executeScalarQuery(t *testing.T, schema *graphql.Schema, query string, variables map[string]interface{}) (interface{}, error) {
+	q, err := graphql.Parse(query, variables)
+	require.NoError(t, err)
+	if err := graphql.PrepareQuery(context.Background(), schema.Query, q.SelectionSet); err != nil {
+		return nil, err
+	}
+	e := testgraphql.NewExecutorWrapper(t)
+	result, err := e.Execute(context.Background(), schema.Query, nil, q)
+	return internal.AsJSON(result), err
+}
+
+func TestScalarOutput(t *testing.T) {
+	schema := makeScalarSchema()
+
+	result, err := executeScalarQuery(t, schema, `{ places { name location entrance price } }`, nil)
+	require.NoError(t, err)
+	assert.Equal(t, internal.ParseJSON(`{"places": [
+		{"name": "park", "location": [1, 2], "entrance": null, "price": "0"},
+		{"name": "museum", "location": [3, 4], "entrance": [3.5, 4], "price": "1250"}
+	]}`), result)
+}
+
+func TestScalarInput(t *testing.T) {
+	schema := makeScalarSchema()
+
+	result, err := executeScalarQuery(t, schema, `{
+		a: distance(from: [1, 2], to: [4, 2])
+		b: distance(from: [1, 2])
+		search(filter: {near: [1, 2], maxPrice: "500"})
+	}`, nil)
+	require.NoError(t, err)
+	assert.Equal(t, internal.ParseJSON(`{"a": 3, "b": 0, "search": "near &{1 2} under 500"}`), result)
+
+	result, err = executeScalarQuery(t, schema, `query Q($from: GeoPoint!, $filter: placeFilter_InputObject!) {
+		distance(from: $from, to: [4, 2])
+		search(filter: $filter)
+	}`, map[string]interface{}{
+		"from":   []interface{}{float64(2), float64(2)},
+		"filter": map[string]interface{}{"maxPrice": "100"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, internal.ParseJSON(`{"distance": 2, "search": "near <nil> under 100"}`), result)
+
+	_, err = executeScalarQuery(t, schema, `{ distance(from: "here") }`, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "expected a [lat, lng] pair")
+}
+
+func TestScalarErrors(t *testing.T) {
+	for _, testCase := range []struct {
+		name  string
+		build func(schema *schemabuilder.Schema)
+		err   string
+	}{
+		{
+			name: "built-in name",
+			build: func(schema *schemabuilder.Schema) {
+				schema.Scalar("string", geoPoint{}, func(v interface{}) (interface{}, error) { return v, nil }, func(v interface{}) (interface{}, error) { return v, nil })
+			},
+			err: "bad scalar schemabuilder_test.geoPoint: string is a built-in scalar",
+		},
+		{
+			name: "duplicate name",
+			build: func(schema *schemabuilder.Schema) {
+				schema.Scalar("Money", geoPoint{}, func(v interface{}) (interface{}, error) { return v, nil }, func(v interface{}) (interface{}, error) { return v, nil })
+				schema.Scalar("Money", cents(0), func(v interface{}) (interface{}, error) { return v, nil }, func(v interface{}) (interface{}, error) { return v, nil })
+			},
+			err: "duplicate name Money",
+		},
+		{
+			name: "missing parse",
+			build: func(schema *schemabuilder.Schema) {
+				schema.Scalar("GeoPoint", geoPoint{}, func(v interface{}) (interface{}, error) { return v, nil }, nil)
+			},
+			err: "bad scalar GeoPoint: serialize and parse must be set",
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			schema := schemabuilder.NewSchema()
+			testCase.build(schema)
+			_, err := schema.Build()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), testCase.err)
+		})
+	}
+
+	assert.Panics(t, func() {
+		schemabuilder.NewSchema().Scalar("Int", int64(0), nil, nil)
+	})
+	assert.Panics(t, func() {
+		schemabuilder.NewSchema().Scalar("GeoPoint", &geoPoint{}, nil, nil)
+	})
+}
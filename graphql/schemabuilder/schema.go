@@ -16,15 +16,34 @@ const federationName = "Federation"
 // can be registered against the "Mutation" and "Query" objects in order to
 // build out a full GraphQL schema.
 type Schema struct {
-	Name      string
-	objects   map[string]*Object
-	enumTypes map[reflect.Type]*EnumMapping
+	Name       string
+	objects    map[string]*Object
+	interfaces map[string]*Interface
+	enumTypes  map[reflect.Type]*EnumMapping
+	directives map[string]*Directive
+	scalars    map[reflect.Type]*Scalar
+	// describeValidations is set with DescribeValidations.
+	describeValidations bool
+	// sdl is the SDL the schema is bound to with SDL, if any.
+	sdl string
+	// fieldCacheStore stores the results of Cached fields, if set with
+	// FieldCache.
+	fieldCacheStore FieldCacheStore
+	// mutationTxBeginner begins the transactions of mutations, if set with
+	// MutationTransaction.
+	mutationTxBeginner TxBeginner
+	// nodeFetchers are the fetchers of the objects registered with
+	// FetchNodesFromKeys, by object name.
+	nodeFetchers map[string]*method
+	// strictVariables is set with StrictVariables.
+	strictVariables bool
 }
 
 // NewSchema creates a new schema.
 func NewSchema() *Schema {
 	schema := &Schema{
-		objects: make(map[string]*Object),
+		objects:    make(map[string]*Object),
+		interfaces: make(map[string]*Interface),
 	}
 
 	// Default registrations.
@@ -39,8 +58,9 @@ func NewSchema() *Schema {
 // NewSchema creates a new schema with a schema name
 func NewSchemaWithName(name string) *Schema {
 	schema := &Schema{
-		Name:    strings.ToLower(name),
-		objects: make(map[string]*Object),
+		Name:       strings.ToLower(name),
+		objects:    make(map[string]*Object),
+		interfaces: make(map[string]*Interface),
 	}
 
 	// Default registrations.
@@ -51,6 +71,13 @@ func NewSchemaWithName(name string) *Schema {
 	return schema
 }
 
+// StrictVariables makes the handlers of the built schema check that every
+// variable is declared with the type of the arguments it is passed to, as with
+// graphql.WithStrictVariables.
+func (s *Schema) StrictVariables() {
+	s.strictVariables = true
+}
+
 // Enum registers an enumType in the schema. The val should be any arbitrary value
 // of the enumType to be used for reflection, and the enumMap should be
 // the corresponding map of the enums.
@@ -69,14 +96,66 @@ func NewSchemaWithName(name string) *Schema {
 //     "two":   enumType(2),
 //     "three": enumType(3),
 //   })
-func (s *Schema) Enum(val interface{}, enumMap interface{}) {
+//
+// Values can be documented with EnumValueDescription and EnumValueDeprecated:
+//   s.Enum(enumType(1), enumMap,
+//     schemabuilder.EnumValueDescription("one", "The first value."),
+//     schemabuilder.EnumValueDeprecated("three", "Use two instead."),
+//   )
+func (s *Schema) Enum(val interface{}, enumMap interface{}, options ...EnumOption) {
 	typ := reflect.TypeOf(val)
 	if s.enumTypes == nil {
 		s.enumTypes = make(map[reflect.Type]*EnumMapping)
 	}
 
 	eMap, rMap := getEnumMap(enumMap, typ)
-	s.enumTypes[typ] = &EnumMapping{Map: eMap, ReverseMap: rMap}
+	mapping := &EnumMapping{Map: eMap, ReverseMap: rMap}
+	for _, opt := range options {
+		opt.apply(mapping)
+	}
+	s.enumTypes[typ] = mapping
+}
+
+// EnumOption is an interface for the variadic options that can be passed to
+// Enum for configuring options on that enum.
+type EnumOption interface {
+	apply(*EnumMapping)
+}
+
+// enumOptionFunc is a helper to define EnumOptions from a func.
+type enumOptionFunc func(*EnumMapping)
+
+func (f enumOptionFunc) apply(m *EnumMapping) { f(m) }
+
+// EnumValueDescription is an option that can be passed to Enum to document
+// one of its values in introspection.
+func EnumValueDescription(value string, description string) EnumOption {
+	var enumValueDescription enumOptionFunc = func(m *EnumMapping) {
+		if _, ok := m.Map[value]; !ok {
+			panic("unknown enum value " + value)
+		}
+		if m.Descriptions == nil {
+			m.Descriptions = make(map[string]string)
+		}
+		m.Descriptions[value] = description
+	}
+	return enumValueDescription
+}
+
+// EnumValueDeprecated is an option that can be passed to Enum to mark one of
+// its values as deprecated in introspection. If reason is empty, the value is
+// deprecated with the default reason "No longer supported".
+func EnumValueDeprecated(value string, reason string) EnumOption {
+	var enumValueDeprecated enumOptionFunc = func(m *EnumMapping) {
+		if _, ok := m.Map[value]; !ok {
+			panic("unknown enum value " + value)
+		}
+		if m.DeprecationReasons == nil {
+			m.DeprecationReasons = make(map[string]string)
+		}
+		m.DeprecationReasons[value] = deprecationReason(reason)
+	}
+	return enumValueDeprecated
 }
 
 func getEnumMap(enumMap interface{}, typ reflect.Type) (map[string]interface{}, map[interface{}]string) {
@@ -185,6 +264,51 @@ func (s *Schema) Object(name string, typ interface{}, options ...ObjectOption) *
 	return object
 }
 
+// Interface registers a Go interface type as a GraphQL Interface in our
+// Schema. (https://facebook.github.io/graphql/June2018/#sec-Interfaces)
+// The typ should be a nil pointer to the interface type, and implementations
+// are the structs that can be returned where the interface is expected.  The
+// fields of the GraphQL Interface are the fields (with identical types) that
+// all of its implementations share.
+//
+// For example, an interface could be declared as follows:
+//   type Gateway interface {
+//     isGateway()
+//   }
+//   func (*Vehicle) isGateway() {}
+//   func (*Asset) isGateway() {}
+//
+// Then the Interface can be registered as:
+//   s.Interface("Gateway", (*Gateway)(nil), Vehicle{}, Asset{})
+//
+// Fields returning a Gateway can then be queried with fragments on Vehicle
+// or Asset, or with the fields common to both.
+func (s *Schema) Interface(name string, typ interface{}, implementations ...interface{}) *Interface {
+	if iface, ok := s.interfaces[name]; ok {
+		if reflect.TypeOf(iface.Type) != reflect.TypeOf(typ) {
+			panic("re-registered interface with different type")
+		}
+		for _, impl := range implementations {
+			iface.Implementation(impl)
+		}
+		return iface
+	}
+
+	if t := reflect.TypeOf(typ); t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Interface {
+		panic("interface type should be a pointer to an interface, e.g. (*Gateway)(nil)")
+	}
+
+	iface := &Interface{
+		Name: name,
+		Type: typ,
+	}
+	for _, impl := range implementations {
+		iface.Implementation(impl)
+	}
+	s.interfaces[name] = iface
+	return iface
+}
+
 type query struct{}
 
 // Query returns an Object struct that we can use to register all the top level
@@ -201,13 +325,23 @@ func (s *Schema) Mutation() *Object {
 	return s.Object("Mutation", mutation{})
 }
 
+type subscription struct{}
+
+// Subscription returns an Object struct that we can use to register all the
+// top level graphql subscription functions we'd like to expose. Subscription
+// functions return a channel or event source of the events sent to
+// subscribers.
+func (s *Schema) Subscription() *Object {
+	return s.Object("Subscription", subscription{})
+}
+
 const DuplicateTypeNameErrFormat string = "%s type name is duplicated in packages %s and %s"
 
 // checkTypeNameUniqueness returns an error if the package of the typ argument
 // is different than the package mapped to the type name in typesToPackages.
 // In other words, checkTypeNameUniqueness returns an error if typ's name
 // is used in 2 different packages.
-func checkTypeNameUniqueness(typ reflect.Type, typesToPackages map[string]string) error {
+func checkTypeNameUniqueness(typ reflect.Type, typesToPackages map[string]string, scalars map[reflect.Type]*Scalar) error {
 	// Invoke typ.Elem() until we get a struct or scalar type.
 	for kind := typ.Kind(); kind == reflect.Slice || kind == reflect.Ptr || kind == reflect.Map; kind = typ.Kind() {
 		typ = typ.Elem()
@@ -219,6 +353,10 @@ func checkTypeNameUniqueness(typ reflect.Type, typesToPackages map[string]string
 	if _, ok := getScalar(typ); ok || typ.Implements(textMarshalerType) {
 		return nil
 	}
+	// Custom scalars are named by their registration.
+	if _, ok := scalars[typ]; ok {
+		return nil
+	}
 	// The union type marker is a special type to denote union types and is not
 	// included in the introspection result.
 	if typ == unionType {
@@ -242,7 +380,7 @@ func checkTypeNameUniqueness(typ reflect.Type, typesToPackages map[string]string
 
 	for i := 0; i < typ.NumField(); i++ {
 		field := typ.Field(i)
-		if err := checkTypeNameUniqueness(field.Type, typesToPackages); err != nil {
+		if err := checkTypeNameUniqueness(field.Type, typesToPackages, scalars); err != nil {
 			return err
 		}
 	}
@@ -259,7 +397,7 @@ func checkTypeNameUniqueness(typ reflect.Type, typesToPackages map[string]string
 // Note that package names are not considered when comparing type names.
 // In other words, foo_package.SomeType and bar_package.SomeType
 // are considered to have the same name.
-func checkSchemaTypesAreUnique(objects map[string]*Object, enums map[reflect.Type]*EnumMapping) error {
+func checkSchemaTypesAreUnique(objects map[string]*Object, enums map[reflect.Type]*EnumMapping, scalars map[reflect.Type]*Scalar) error {
 	typesToPackages := map[string]string{}
 
 	for _, object := range objects {
@@ -292,7 +430,7 @@ func checkSchemaTypesAreUnique(objects map[string]*Object, enums map[reflect.Typ
 					continue
 				}
 
-				if err := checkTypeNameUniqueness(arg, typesToPackages); err != nil {
+				if err := checkTypeNameUniqueness(arg, typesToPackages, scalars); err != nil {
 					return err
 				}
 			}
@@ -305,7 +443,7 @@ func checkSchemaTypesAreUnique(objects map[string]*Object, enums map[reflect.Typ
 					continue
 				}
 
-				if err := checkTypeNameUniqueness(ret, typesToPackages); err != nil {
+				if err := checkTypeNameUniqueness(ret, typesToPackages, scalars); err != nil {
 					return err
 				}
 			}
@@ -331,18 +469,49 @@ func checkSchemaTypesAreUnique(objects map[string]*Object, enums map[reflect.Typ
 // Query and Mutation Objects and ensure that those functions are returning
 // other Objects that we can resolve in our GraphQL graph.
 func (s *Schema) Build() (*graphql.Schema, error) {
-	if err := checkSchemaTypesAreUnique(s.objects, s.enumTypes); err != nil {
+	if err := checkSchemaTypesAreUnique(s.objects, s.enumTypes, s.scalars); err != nil {
 		return nil, oops.Wrapf(err, "type names in schema must be unique")
 	}
 
+	var expectedSDL *graphql.Schema
+	if s.sdl != "" {
+		expected, err := s.parseSDL()
+		if err != nil {
+			return nil, err
+		}
+		expectedSDL = expected
+	}
+
 	sb := &schemaBuilder{
 		types:        make(map[reflect.Type]graphql.Type),
 		typeNames:    make(map[string]reflect.Type),
 		objects:      make(map[reflect.Type]*Object),
+		interfaces:   make(map[reflect.Type]*Interface),
 		enumMappings: s.enumTypes,
 		typeCache:    make(map[reflect.Type]cachedType, 0),
+
+		fieldCacheStore:     s.fieldCacheStore,
+		describeValidations: s.describeValidations,
+	}
+
+	for _, iface := range s.interfaces {
+		typ := reflect.TypeOf(iface.Type).Elem()
+		if _, ok := sb.interfaces[typ]; ok {
+			return nil, fmt.Errorf("duplicate interface for %s", typ.String())
+		}
+		sb.interfaces[typ] = iface
+	}
+
+	if err := sb.buildScalars(s.scalars); err != nil {
+		return nil, err
 	}
 
+	directives, err := sb.buildDirectives(s.directives)
+	if err != nil {
+		return nil, err
+	}
+	sb.directives = directives
+
 	s.Object("Query", query{})
 	s.Object("Mutation", mutation{})
 
@@ -363,14 +532,42 @@ func (s *Schema) Build() (*graphql.Schema, error) {
 	if err != nil {
 		return nil, err
 	}
+	if err := sb.buildNodes(queryTyp.(*graphql.Object), s.objects, s.nodeFetchers); err != nil {
+		return nil, err
+	}
 	mutationTyp, err := sb.getType(reflect.TypeOf(&mutation{}), true)
 	if err != nil {
 		return nil, err
 	}
-	return &graphql.Schema{
-		Query:    queryTyp,
-		Mutation: mutationTyp,
-	}, nil
+	if s.mutationTxBeginner != nil {
+		mutationTyp.(*graphql.Object).Transaction = makeTransaction(s.mutationTxBeginner)
+	}
+	// Unlike Query and Mutation, the Subscription object is only part of the
+	// schema if it has been registered.
+	var subscriptionTyp graphql.Type
+	if _, ok := sb.objects[reflect.TypeOf(subscription{})]; ok {
+		subscriptionTyp, err = sb.getType(reflect.TypeOf(&subscription{}), true)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := sb.finishInterfaces(); err != nil {
+		return nil, err
+	}
+	built := &graphql.Schema{
+		Query:        queryTyp,
+		Mutation:     mutationTyp,
+		Subscription: subscriptionTyp,
+		Directives:   directives,
+
+		StrictVariables: s.strictVariables,
+	}
+	if expectedSDL != nil {
+		if err := checkSDL(expectedSDL, built); err != nil {
+			return nil, err
+		}
+	}
+	return built, nil
 }
 
 // MustBuildSchema builds a schema and panics if an error occurs.
@@ -272,3 +272,28 @@ func TestRecursiveType(t *testing.T) {
 
 	schema.MustBuild()
 }
+
+func TestDeprecatedInputFieldFailsSchema(t *testing.T) {
+	schema := schemabuilder.NewSchema()
+
+	type Filter struct {
+		Name string `deprecated:"Use prefix instead."`
+	}
+	schema.Query().FieldFunc("search", func(args struct{ Filter Filter }) string {
+		return ""
+	})
+
+	_, err := schema.Build()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "input field name cannot be deprecated")
+}
+
+func TestEnumValueOptionsPanicOnUnknownValues(t *testing.T) {
+	schema := schemabuilder.NewSchema()
+
+	type Status int32
+	assert.Panics(t, func() {
+		schema.Enum(Status(0), map[string]Status{"active": Status(0)},
+			schemabuilder.EnumValueDescription("inactive", "Not active."))
+	})
+}
@@ -0,0 +1,149 @@
+package schemabuilder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/samsarahq/thunder/graphql"
+	"github.com/samsarahq/thunder/graphql/schemadiff"
+	"github.com/samsarahq/thunder/graphql/sdl"
+)
+
+// SDL binds the schema to a schema written in the GraphQL schema definition
+// language, for schema-first development. Build fails unless the types,
+// fields, arguments, enum values and directives registered in Go match the
+// SDL exactly, including their nullability.
+//
+// Descriptions are not compared. Instead, descriptions in the SDL are used in
+// place of the descriptions registered in Go, so that documentation can be
+// written and reviewed alongside the SDL. Anything without a description in
+// the SDL keeps the description registered in Go.
+//
+// Types must be named as schemabuilder names them, so scalars are declared
+// as, for example, "scalar string" and "scalar int64". The output of
+// "thunder schema print" on an existing schema is a good starting point.
+//
+// For example, with the SDL checked in next to the resolvers:
+//
+//	source, err := ioutil.ReadFile("schema.graphql")
+//	...
+//	schema := schemabuilder.NewSchema()
+//	schema.SDL(string(source))
+//	registerResolvers(schema)
+//	built, err := schema.Build()
+func (s *Schema) SDL(source string) {
+	s.sdl = source
+}
+
+// parseSDL parses the SDL bound to the schema, and copies the descriptions of
+// its enum values onto the schema's enums. Enum descriptions are copied
+// before the schema is built, as every use of an enum builds its own
+// graphql.Enum from the EnumMapping.
+func (s *Schema) parseSDL() (*graphql.Schema, error) {
+	expected, err := sdl.Parse(s.sdl)
+	if err != nil {
+		return nil, fmt.Errorf("bad SDL: %s", err)
+	}
+
+	for name, typ := range sdl.CollectTypes(expected) {
+		enum, ok := typ.(*graphql.Enum)
+		if !ok {
+			continue
+		}
+		for enumType, mapping := range s.enumTypes {
+			if enumType.Name() == name {
+				mapping.Descriptions = mergeDescriptions(enum.ValueDescriptions, mapping.Descriptions)
+			}
+		}
+	}
+	return expected, nil
+}
+
+// checkSDL checks that built matches the SDL expected, and copies the
+// descriptions of the SDL onto built.
+func checkSDL(expected, built *graphql.Schema) error {
+	copyDescriptions(expected, built)
+	// Descriptions are not compared, so the SDL takes the descriptions that
+	// are only registered in Go.
+	copyDescriptions(built, expected)
+
+	// The Mutation object is part of every built schema, but an SDL without
+	// mutations has no Mutation type.
+	actual := *built
+	if expected.Mutation == nil && len(built.Mutation.(*graphql.Object).Fields) == 0 {
+		actual.Mutation = nil
+	}
+
+	changes := schemadiff.Diff(expected, &actual)
+	if len(changes) == 0 {
+		return nil
+	}
+	messages := make([]string, 0, len(changes))
+	for _, change := range changes {
+		messages = append(messages, change.Message)
+	}
+	return fmt.Errorf("schema does not match SDL; changes from the SDL to the schema:\n\t%s", strings.Join(messages, "\n\t"))
+}
+
+// copyDescriptions copies the descriptions of the types in from onto the types
+// with the same name and kind in to. Only descriptions set in from are
+// copied, so descriptions in to are kept unless from overrides them.
+func copyDescriptions(from, to *graphql.Schema) {
+	toTypes := sdl.CollectTypes(to)
+	for name, fromType := range sdl.CollectTypes(from) {
+		switch fromType := fromType.(type) {
+		case *graphql.Object:
+			if toType, ok := toTypes[name].(*graphql.Object); ok {
+				copyDescription(fromType.Description, &toType.Description)
+				copyFieldDescriptions(fromType.Fields, toType.Fields)
+			}
+		case *graphql.Interface:
+			if toType, ok := toTypes[name].(*graphql.Interface); ok {
+				copyDescription(fromType.Description, &toType.Description)
+				copyFieldDescriptions(fromType.Fields, toType.Fields)
+			}
+		case *graphql.Union:
+			if toType, ok := toTypes[name].(*graphql.Union); ok {
+				copyDescription(fromType.Description, &toType.Description)
+			}
+		case *graphql.Enum:
+			if toType, ok := toTypes[name].(*graphql.Enum); ok {
+				toType.ValueDescriptions = mergeDescriptions(fromType.ValueDescriptions, toType.ValueDescriptions)
+			}
+		case *graphql.InputObject:
+			if toType, ok := toTypes[name].(*graphql.InputObject); ok {
+				toType.InputFieldDescriptions = mergeDescriptions(fromType.InputFieldDescriptions, toType.InputFieldDescriptions)
+			}
+		}
+	}
+}
+
+// mergeDescriptions copies the descriptions set in from into to, and returns
+// to, which is allocated if it is nil.
+func mergeDescriptions(from, to map[string]string) map[string]string {
+	for name, description := range from {
+		if description == "" {
+			continue
+		}
+		if to == nil {
+			to = make(map[string]string)
+		}
+		to[name] = description
+	}
+	return to
+}
+
+func copyFieldDescriptions(from, to map[string]*graphql.Field) {
+	for name, fromField := range from {
+		if toField, ok := to[name]; ok {
+			copyDescription(fromField.Description, &toField.Description)
+		}
+	}
+}
+
+// copyDescription sets *to to from, unless from is empty.
+func copyDescription(from string, to *string) {
+	if from != "" {
+		*to = from
+	}
+}
@@ -0,0 +1,144 @@
+package schemabuilder_test
+
+import (
+	"testing"
+
+	"github.com/samsarahq/thunder/graphql"
+	"github.com/samsarahq/thunder/graphql/schemabuilder"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sdlUser struct {
+	Name  string
+	Email *string
+}
+
+const userSDL = `
+type Query {
+  """The current user."""
+  me: sdlUser!
+  users(limit: int64!): [sdlUser!]!
+}
+
+"""A user of the app."""
+type sdlUser {
+  email: string
+  name: string!
+}
+
+scalar int64
+scalar string
+`
+
+func makeSDLSchema() *schemabuilder.Schema {
+	schema := schemabuilder.NewSchema()
+	schema.Object("sdlUser", sdlUser{})
+	query := schema.Query()
+	query.FieldFunc("me", func() sdlUser { return sdlUser{} }, schemabuilder.Description("Overridden by the SDL."))
+	query.FieldFunc("users", func(args struct{ Limit int64 }) []sdlUser { return nil })
+	return schema
+}
+
+func TestSDL(t *testing.T) {
+	schema := makeSDLSchema()
+	schema.SDL(userSDL)
+	built, err := schema.Build()
+	require.NoError(t, err)
+
+	me := built.Query.(*graphql.Object).Fields["me"]
+	assert.Equal(t, "The current user.", me.Description)
+	assert.Equal(t, "A user of the app.", me.Type.(*graphql.NonNull).Type.(*graphql.Object).Description)
+}
+
+func TestSDLMismatch(t *testing.T) {
+	schema := makeSDLSchema()
+	schema.Query().FieldFunc("version", func() string { return "" })
+	schema.SDL(`
+type Query {
+  me: sdlUser
+  users(limit: int64): [sdlUser!]!
+}
+
+type sdlUser {
+  email: string
+  name: string!
+  age: int64!
+}
+
+scalar int64
+scalar string
+`)
+	_, err := schema.Build()
+	assert.EqualError(t, err, `schema does not match SDL; changes from the SDL to the schema:
+	Field Query.me changed type from sdlUser to sdlUser!.
+	Argument limit of field Query.users changed type from int64 to int64!.
+	Field Query.version was added.
+	Field sdlUser.age was removed.`)
+}
+
+func TestSDLMutation(t *testing.T) {
+	schema := makeSDLSchema()
+	schema.Mutation().FieldFunc("ping", func() string { return "pong" })
+	schema.SDL(userSDL)
+	_, err := schema.Build()
+	assert.EqualError(t, err, `schema does not match SDL; changes from the SDL to the schema:
+	Schema mutation type Mutation was added.
+	Type Mutation was added.`)
+
+	schema.SDL(userSDL + "\ntype Mutation {\n  ping: string!\n}\n")
+	_, err = schema.Build()
+	assert.NoError(t, err)
+}
+
+func TestSDLParseError(t *testing.T) {
+	schema := makeSDLSchema()
+	schema.SDL("type Query {")
+	_, err := schema.Build()
+	assert.EqualError(t, err, "bad SDL: 1:13: expected name, got end of input")
+}
+
+type sdlColor int
+
+func TestSDLDescriptions(t *testing.T) {
+	schema := schemabuilder.NewSchema()
+	schema.Enum(sdlColor(0), map[string]sdlColor{"red": 0, "blue": 1},
+		schemabuilder.EnumValueDescription("blue", "Registered in Go."))
+	user := schema.Object("sdlUser", sdlUser{})
+	user.Description = "Registered in Go."
+	query := schema.Query()
+	query.FieldFunc("me", func() sdlUser { return sdlUser{} }, schemabuilder.Description("Registered in Go."))
+	query.FieldFunc("mix", func(args struct{ Color sdlColor }) sdlColor { return args.Color })
+	schema.SDL(`
+type Query {
+  me: sdlUser!
+  mix(color: sdlColor!): sdlColor!
+}
+
+type sdlUser {
+  email: string
+  name: string!
+}
+
+enum sdlColor {
+  blue
+  """Written in the SDL."""
+  red
+}
+
+scalar string
+`)
+	built, err := schema.Build()
+	require.NoError(t, err)
+
+	// Descriptions missing from the SDL are kept.
+	me := built.Query.(*graphql.Object).Fields["me"]
+	assert.Equal(t, "Registered in Go.", me.Description)
+	assert.Equal(t, "Registered in Go.", me.Type.(*graphql.NonNull).Type.(*graphql.Object).Description)
+
+	// Every use of the enum is documented.
+	mix := built.Query.(*graphql.Object).Fields["mix"]
+	expected := map[string]string{"red": "Written in the SDL.", "blue": "Registered in Go."}
+	assert.Equal(t, expected, mix.Type.(*graphql.NonNull).Type.(*graphql.Enum).ValueDescriptions)
+	assert.Equal(t, expected, mix.Args["color"].(*graphql.NonNull).Type.(*graphql.Enum).ValueDescriptions)
+}
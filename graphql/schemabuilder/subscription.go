@@ -0,0 +1,165 @@
+package schemabuilder
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/samsarahq/thunder/graphql"
+)
+
+// buildSubscriptionFunction builds a field on the subscription root. The
+// function returns a stream of events, either as a channel or as an event
+// source with a method
+//
+//   Next(ctx context.Context) (Event, error)
+//
+// that returns io.EOF once the stream has ended. The field's type is the type
+// of the events, and it resolves to the event being executed.
+func (sb *schemaBuilder) buildSubscriptionFunction(typ reflect.Type, m *method) (*graphql.Field, error) {
+	funcCtx := &funcContext{typ: typ}
+
+	callableFunc, err := funcCtx.getFuncVal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	in := funcCtx.getFuncInputTypes()
+	in = funcCtx.consumeContextAndSource(in)
+
+	argParser, argType, in, err := funcCtx.getArgParserAndTyp(sb, in)
+	if err != nil {
+		return nil, err
+	}
+	funcCtx.hasArgs = argParser != nil
+
+	in = funcCtx.consumeSelectionSet(in)
+
+	// We have succeeded if no arguments remain.
+	if len(in) != 0 {
+		return nil, fmt.Errorf("%s arguments should be [context][, [*]%s][, args][, selectionSet]", funcCtx.funcType, typ)
+	}
+
+	if err := funcCtx.parseReturnSignature(m); err != nil {
+		return nil, err
+	}
+	if !funcCtx.hasRet {
+		return nil, fmt.Errorf("%s should return a channel or event source", funcCtx.funcType)
+	}
+
+	streamType := funcCtx.funcType.Out(0)
+	eventType, ok := getEventType(streamType)
+	if !ok {
+		return nil, fmt.Errorf("%s should return a channel or event source, not %s", funcCtx.funcType, streamType)
+	}
+
+	retType, err := sb.getType(eventType, m.MarkedListEntryNonNullable)
+	if err != nil {
+		return nil, err
+	}
+	if m.MarkedNonNullable {
+		if _, ok := retType.(*graphql.NonNull); !ok {
+			retType = &graphql.NonNull{Type: retType}
+		}
+	}
+
+	args, err := funcCtx.argsTypeMap(argType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &graphql.Field{
+		Resolve: func(ctx context.Context, source, args interface{}, selectionSet *graphql.SelectionSet) (interface{}, error) {
+			// The event being executed is the source of the subscription root.
+			return source, nil
+		},
+		Subscribe: func(ctx context.Context, funcRawArgs interface{}, selectionSet *graphql.SelectionSet) (graphql.EventStream, error) {
+			funcInputArgs := funcCtx.prepareResolveArgs(subscription{}, funcCtx.hasArgs, funcRawArgs, ctx, selectionSet)
+			funcOutputArgs := callableFunc.Call(funcInputArgs)
+
+			if funcCtx.hasError {
+				if err := funcOutputArgs[1]; !err.IsNil() {
+					return nil, err.Interface().(error)
+				}
+			}
+
+			stream := funcOutputArgs[0]
+			if stream.Kind() == reflect.Chan {
+				return &chanEventStream{ch: stream}, nil
+			}
+			if (stream.Kind() == reflect.Ptr || stream.Kind() == reflect.Interface) && stream.IsNil() {
+				return nil, fmt.Errorf("%s returned a nil event source", funcCtx.funcType)
+			}
+			return &eventSourceStream{next: stream.MethodByName("Next")}, nil
+		},
+		Args:                       args,
+		ArgDescriptions:            argDescriptions(argType),
+		Type:                       retType,
+		ParseArguments:             argParser.Parse,
+		Expensive:                  m.Expensive,
+		Cost:                       m.Cost,
+		External:                   true,
+		NumParallelInvocationsFunc: m.ConcurrencyArgs.numParallelInvocationsFunc,
+	}, nil
+}
+
+// getEventType returns the type of the events of a channel or event source
+// type.
+func getEventType(typ reflect.Type) (reflect.Type, bool) {
+	if typ.Kind() == reflect.Chan {
+		return typ.Elem(), typ.ChanDir()&reflect.RecvDir != 0
+	}
+
+	method, ok := typ.MethodByName("Next")
+	if !ok {
+		return nil, false
+	}
+
+	// Methods of non-interface types take their receiver as first argument.
+	in := make([]reflect.Type, 0, method.Type.NumIn())
+	for i := 0; i < method.Type.NumIn(); i++ {
+		in = append(in, method.Type.In(i))
+	}
+	if typ.Kind() != reflect.Interface {
+		in = in[1:]
+	}
+
+	if len(in) != 1 || in[0] != contextType || method.Type.NumOut() != 2 || method.Type.Out(1) != errType {
+		return nil, false
+	}
+	return method.Type.Out(0), true
+}
+
+// chanEventStream is a graphql.EventStream receiving events from a channel.
+type chanEventStream struct {
+	ch reflect.Value
+}
+
+func (s *chanEventStream) Next(ctx context.Context) (interface{}, error) {
+	chosen, event, ok := reflect.Select([]reflect.SelectCase{
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())},
+		{Dir: reflect.SelectRecv, Chan: s.ch},
+	})
+	if chosen == 0 {
+		return nil, ctx.Err()
+	}
+	if !ok {
+		return nil, io.EOF
+	}
+	return event.Interface(), nil
+}
+
+// eventSourceStream is a graphql.EventStream calling the Next method of an
+// event source.
+type eventSourceStream struct {
+	next reflect.Value
+}
+
+func (s *eventSourceStream) Next(ctx context.Context) (interface{}, error) {
+	out := s.next.Call([]reflect.Value{reflect.ValueOf(ctx)})
+	if err := out[1]; !err.IsNil() {
+		return nil, err.Interface().(error)
+	}
+	return out[0].Interface(), nil
+}
@@ -0,0 +1,44 @@
+package schemabuilder
+
+import (
+	"context"
+	"database/sql"
+)
+
+// A TxBeginner begins transactions, such as a *sqlgen.DB.
+type TxBeginner interface {
+	// WithTx begins a transaction and returns a derived Context that contains
+	// it.
+	WithTx(ctx context.Context) (context.Context, *sql.Tx, error)
+}
+
+// MutationTransaction runs every mutation in a transaction of db, which
+// resolvers find in their context. The transaction is committed only if
+// every field of the mutation succeeds, and rolled back otherwise.
+//
+// For example, with a *sqlgen.DB:
+//
+//	schema.MutationTransaction(db)
+//	schema.Mutation().FieldFunc("rename", func(ctx context.Context, args struct{ Id int64; Name string }) error {
+//	  return db.UpdateRow(ctx, &User{Id: args.Id, Name: args.Name})
+//	})
+func (s *Schema) MutationTransaction(db TxBeginner) {
+	s.mutationTxBeginner = db
+}
+
+// makeTransaction returns a graphql.Object Transaction that executes
+// mutations in a transaction of db.
+func makeTransaction(db TxBeginner) func(ctx context.Context, execute func(ctx context.Context) error) error {
+	return func(ctx context.Context, execute func(ctx context.Context) error) error {
+		ctx, tx, err := db.WithTx(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		if err := execute(ctx); err != nil {
+			return err
+		}
+		return tx.Commit()
+	}
+}
@@ -0,0 +1,100 @@
+package schemabuilder_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/samsarahq/thunder/graphql"
+	"github.com/samsarahq/thunder/graphql/schemabuilder"
+	"github.com/samsarahq/thunder/internal"
+	"github.com/samsarahq/thunder/sqlgen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// txConnector is a database/sql connector whose connections only log the
+// transactions they begin, commit and roll back.
+type txConnector struct {
+	mu  sync.Mutex
+	log []string
+}
+
+func (c *txConnector) record(entry string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.log = append(c.log, entry)
+}
+
+func (c *txConnector) takeLog() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	log := c.log
+	c.log = nil
+	return log
+}
+
+func (c *txConnector) Connect(ctx context.Context) (driver.Conn, error) { return txConn{c}, nil }
+func (c *txConnector) Driver() driver.Driver                            { return nil }
+
+type txConn struct{ c *txConnector }
+
+func (c txConn) Prepare(query string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c txConn) Close() error                              { return nil }
+func (c txConn) Begin() (driver.Tx, error) {
+	c.c.record("begin")
+	return c, nil
+}
+func (c txConn) Commit() error {
+	c.c.record("commit")
+	return nil
+}
+func (c txConn) Rollback() error {
+	c.c.record("rollback")
+	return nil
+}
+
+func TestMutationTransaction(t *testing.T) {
+	connector := &txConnector{}
+	db := sqlgen.NewDB(sql.OpenDB(connector), sqlgen.NewSchema())
+
+	schema := schemabuilder.NewSchema()
+	schema.MutationTransaction(db)
+	schema.Query().FieldFunc("inTx", func(ctx context.Context) bool {
+		return db.HasTx(ctx)
+	})
+	schema.Mutation().FieldFunc("rename", func(ctx context.Context, args struct{ Name string }) (bool, error) {
+		if args.Name == "" {
+			return false, errors.New("name must not be empty")
+		}
+		return db.HasTx(ctx), nil
+	})
+	built := schema.MustBuild()
+
+	execute := func(typ graphql.Type, query string) (interface{}, error) {
+		q := graphql.MustParse(query, nil)
+		require.NoError(t, graphql.PrepareQuery(context.Background(), typ, q.SelectionSet))
+		e := graphql.NewExecutor(graphql.NewImmediateGoroutineScheduler())
+		result, err := e.Execute(context.Background(), typ, nil, q)
+		return internal.AsJSON(result), err
+	}
+
+	result, err := execute(built.Mutation, `mutation { a: rename(name: "a") b: rename(name: "b") }`)
+	require.NoError(t, err)
+	assert.Equal(t, internal.ParseJSON(`{"a": true, "b": true}`), result)
+	assert.Equal(t, []string{"begin", "commit"}, connector.takeLog())
+
+	_, err = execute(built.Mutation, `mutation { a: rename(name: "a") b: rename(name: "") }`)
+	require.Error(t, err)
+	assert.Equal(t, "b: name must not be empty", err.Error())
+	assert.Equal(t, []string{"begin", "rollback"}, connector.takeLog())
+
+	// Queries do not run in a transaction.
+	result, err = execute(built.Query, `{ inTx }`)
+	require.NoError(t, err)
+	assert.Equal(t, internal.ParseJSON(`{"inTx": false}`), result)
+	assert.Empty(t, connector.takeLog())
+}
@@ -3,6 +3,9 @@ package schemabuilder
 import (
 	"context"
 	"reflect"
+	"time"
+
+	"github.com/samsarahq/thunder/graphql"
 )
 
 // A Object represents a Go type and set of methods to be converted into an
@@ -54,6 +57,46 @@ var Expensive fieldFuncOptionFunc = func(m *method) {
 	m.Expensive = true
 }
 
+// Cost is an option that can be passed to a FieldFunc to set the cost of the
+// field for query complexity limits. Fields without a cost count as 1.
+func Cost(cost int) FieldFuncOption {
+	var fieldFuncCost fieldFuncOptionFunc = func(m *method) {
+		m.Cost = cost
+	}
+	return fieldFuncCost
+}
+
+// Description is an option that can be passed to a FieldFunc to document the
+// field in introspection.
+func Description(description string) FieldFuncOption {
+	var fieldFuncDescription fieldFuncOptionFunc = func(m *method) {
+		m.Description = description
+	}
+	return fieldFuncDescription
+}
+
+// Deprecated is an option that can be passed to a FieldFunc to mark the field
+// as deprecated in introspection. If reason is empty, the field is deprecated
+// with the default reason "No longer supported".
+func Deprecated(reason string) FieldFuncOption {
+	var fieldFuncDeprecated fieldFuncOptionFunc = func(m *method) {
+		m.Deprecated = true
+		m.DeprecationReason = deprecationReason(reason)
+	}
+	return fieldFuncDeprecated
+}
+
+// defaultDeprecationReason is the reason reported for deprecations without
+// one, as in the GraphQL specification.
+const defaultDeprecationReason = "No longer supported"
+
+func deprecationReason(reason string) string {
+	if reason == "" {
+		return defaultDeprecationReason
+	}
+	return reason
+}
+
 // FilterFunc is an option that can be passed to a FieldFunc to specify
 // custom string matching algorithms for filtering FieldFunc results.
 //
@@ -337,6 +380,23 @@ type method struct {
 	// Whether or not the FieldFunc has been marked as expensive.
 	Expensive bool
 
+	// Cost is the cost of the FieldFunc used by query complexity limits.
+	Cost int
+
+	// CacheTTL is how long results of the FieldFunc are cached across
+	// requests, if it is Cached.
+	CacheTTL time.Duration
+	// CacheHint is the cache hint of the FieldFunc for HTTP responses.
+	CacheHint *graphql.CacheHint
+
+	// Directives are the directives applied to the FieldFunc.
+	Directives []appliedDirective
+
+	// Description and deprecation of the FieldFunc shown in introspection.
+	Description       string
+	Deprecated        bool
+	DeprecationReason string
+
 	// Custom filter methods for determining whether a field matches a search query.
 	FilterMethods map[string]func(string, []string) bool
 
@@ -413,3 +473,37 @@ type Methods map[string]*method
 type Union struct{}
 
 var unionType = reflect.TypeOf(Union{})
+
+// OneOf is a special marker struct that can be embedded into an input struct
+// to denote that it should be treated as a one-of input object, of which
+// exactly one field must be set.
+//
+// For example, a filter by either id or name might look like:
+//   type UserFilter struct {
+//     schemabuilder.OneOf
+//     Id   *int64
+//     Name *string
+//   }
+//
+// Every field of a one-of input object must be a pointer or optional, and
+// the parsed struct has exactly one field set. One-of input objects cannot
+// be used as the args struct of a FieldFunc itself, only as its arguments.
+type OneOf struct{}
+
+var oneOfType = reflect.TypeOf(OneOf{})
+
+// An Interface represents a Go interface type to be converted into an
+// Interface in a GraphQL schema, along with the Go types implementing it.
+type Interface struct {
+	Name        string
+	Description string
+	Type        interface{}
+	Types       []interface{}
+}
+
+// Implementation registers typ as one of the possible types of an Interface.
+// typ should be a struct registered as an Object (or a pointer to one), and
+// it (or a pointer to it) must implement the Go interface.
+func (i *Interface) Implementation(typ interface{}) {
+	i.Types = append(i.Types, typ)
+}
@@ -0,0 +1,362 @@
+package schemabuilder
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Input fields and arguments can be validated with a `validate` tag, whose
+// comma-separated rules are checked when arguments are parsed, before the
+// FieldFunc is called:
+//
+//	required     the value must not be empty or zero, and must be set
+//	min=N        numbers must be at least N, and strings and lists must have
+//	             at least N characters or items
+//	max=N        numbers must be at most N, and strings and lists must have
+//	             at most N characters or items
+//	len=N        strings and lists must have exactly N characters or items
+//	oneof=a b c  strings, numbers and enums must be one of the space-separated
+//	             values
+//	regex=re     strings must match the regular expression re, which extends
+//	             to the end of the tag so that it may contain commas
+//
+// For example:
+//
+//	type searchArgs struct {
+//	  Query string `validate:"required,max=100"`
+//	  Limit int64  `validate:"min=1,max=1000"`
+//	  Sort  *string `validate:"oneof=name date"`
+//	}
+//
+// Rules other than required are only checked for values that are set, so an
+// optional field that is not set or a nil pointer passes them. Arguments that
+// fail validation are rejected with a client error that includes their path.
+//
+// See Schema.DescribeValidations to document the rules in introspection.
+const validateTag = "validate"
+
+// DescribeValidations appends the rules of the `validate` tags of arguments
+// and input fields to their descriptions in introspection, e.g. "Must be at
+// least 1. Must be at most 1000."
+func (s *Schema) DescribeValidations() {
+	s.describeValidations = true
+}
+
+// validationKind is the kind of value a fieldValidator checks, which decides
+// the rules it supports.
+type validationKind int
+
+const (
+	validateOpaque validationKind = iota
+	validateNumber
+	validateString
+	validateList
+	validateEnum
+)
+
+// fieldValidator checks the value of an input field against the rules of its
+// `validate` tag.
+type fieldValidator struct {
+	kind validationKind
+	enum *EnumMapping
+
+	required bool
+	min, max *float64
+	length   *int
+	oneOf    []string
+	regex    *regexp.Regexp
+
+	// rules are the rules other than required, built once the tag is parsed.
+	rules []validationRule
+}
+
+// makeFieldValidator parses the `validate` tag of field, or returns nil if it
+// has none.
+func (sb *schemaBuilder) makeFieldValidator(field reflect.StructField) (*fieldValidator, error) {
+	tag, ok := field.Tag.Lookup(validateTag)
+	if !ok {
+		return nil, nil
+	}
+
+	typ := field.Type
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	v := &fieldValidator{kind: sb.getValidationKind(typ), enum: sb.enumMappings[typ]}
+
+	for tag != "" {
+		var rule string
+		if strings.HasPrefix(tag, "regex=") {
+			rule, tag = tag, ""
+		} else if i := strings.Index(tag, ","); i >= 0 {
+			rule, tag = tag[:i], tag[i+1:]
+		} else {
+			rule, tag = tag, ""
+		}
+
+		name, arg := rule, ""
+		if i := strings.Index(rule, "="); i >= 0 {
+			name, arg = rule[:i], rule[i+1:]
+		}
+		if err := v.addRule(name, arg); err != nil {
+			return nil, fmt.Errorf("bad validate tag on %s: %s", field.Name, err)
+		}
+	}
+
+	if v.enum != nil {
+		for _, value := range v.oneOf {
+			if _, ok := v.enum.Map[value]; !ok {
+				return nil, fmt.Errorf("bad validate tag on %s: unknown enum value %s", field.Name, value)
+			}
+		}
+	}
+	v.rules = v.buildRules()
+	return v, nil
+}
+
+// getValidationKind returns the kind of value of typ, which is not a pointer.
+func (sb *schemaBuilder) getValidationKind(typ reflect.Type) validationKind {
+	if sb.enumMappings[typ] != nil {
+		return validateEnum
+	}
+	if _, ok := sb.scalars[typ]; ok {
+		return validateOpaque
+	}
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return validateNumber
+	case reflect.String:
+		return validateString
+	case reflect.Slice:
+		return validateList
+	default:
+		return validateOpaque
+	}
+}
+
+// addRule adds the rule name with argument arg to v.
+func (v *fieldValidator) addRule(name string, arg string) error {
+	supported := map[string][]validationKind{
+		"min":   {validateNumber, validateString, validateList},
+		"max":   {validateNumber, validateString, validateList},
+		"len":   {validateString, validateList},
+		"oneof": {validateNumber, validateString, validateEnum},
+		"regex": {validateString},
+	}
+	if name != "required" {
+		kinds, ok := supported[name]
+		if !ok {
+			return fmt.Errorf("unknown rule %s", name)
+		}
+		found := false
+		for _, kind := range kinds {
+			found = found || kind == v.kind
+		}
+		if !found {
+			return fmt.Errorf("rule %s is not supported on this type", name)
+		}
+	}
+
+	switch name {
+	case "required":
+		v.required = true
+
+	case "min", "max":
+		bound, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return fmt.Errorf("rule %s must have a number", name)
+		}
+		if name == "min" {
+			v.min = &bound
+		} else {
+			v.max = &bound
+		}
+
+	case "len":
+		length, err := strconv.Atoi(arg)
+		if err != nil {
+			return fmt.Errorf("rule len must have an integer")
+		}
+		v.length = &length
+
+	case "oneof":
+		v.oneOf = strings.Fields(arg)
+		if len(v.oneOf) == 0 {
+			return fmt.Errorf("rule oneof must have values")
+		}
+		if v.kind == validateNumber {
+			for _, value := range v.oneOf {
+				if _, err := strconv.ParseFloat(value, 64); err != nil {
+					return fmt.Errorf("rule oneof must have numbers")
+				}
+			}
+		}
+
+	case "regex":
+		regex, err := regexp.Compile(arg)
+		if err != nil {
+			return fmt.Errorf("rule regex must have a valid regular expression: %s", err)
+		}
+		v.regex = regex
+	}
+	return nil
+}
+
+// validate checks value, which was parsed from an input that is set if set is
+// true.
+func (v *fieldValidator) validate(set bool, value reflect.Value) error {
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			set = false
+		} else {
+			value = value.Elem()
+		}
+	}
+	if !set || value.IsZero() {
+		if v.required {
+			return errors.New("must not be empty")
+		}
+		if !set {
+			return nil
+		}
+	}
+
+	// amount is the number or the length the min and max rules apply to.
+	var amount float64
+	switch v.kind {
+	case validateNumber:
+		amount = numberValue(value)
+	case validateString:
+		amount = float64(utf8.RuneCountInString(value.String()))
+	case validateList:
+		amount = float64(value.Len())
+	}
+
+	for _, rule := range v.rules {
+		if !rule.ok(v, value, amount) {
+			return errors.New(rule.message)
+		}
+	}
+	return nil
+}
+
+// validationRule is a rule of a fieldValidator other than required. ok
+// checks a value, whose number or length is amount.
+type validationRule struct {
+	message string
+	ok      func(v *fieldValidator, value reflect.Value, amount float64) bool
+}
+
+// buildRules returns the rules of v, other than required.
+func (v *fieldValidator) buildRules() []validationRule {
+	unit := ""
+	switch v.kind {
+	case validateString:
+		unit = " characters"
+	case validateList:
+		unit = " items"
+	}
+	verb := "must be"
+	if unit != "" {
+		verb = "must have"
+	}
+
+	var rules []validationRule
+	if v.min != nil {
+		min := *v.min
+		rules = append(rules, validationRule{
+			message: fmt.Sprintf("%s at least %s%s", verb, formatBound(min), unit),
+			ok:      func(_ *fieldValidator, _ reflect.Value, amount float64) bool { return amount >= min },
+		})
+	}
+	if v.max != nil {
+		max := *v.max
+		rules = append(rules, validationRule{
+			message: fmt.Sprintf("%s at most %s%s", verb, formatBound(max), unit),
+			ok:      func(_ *fieldValidator, _ reflect.Value, amount float64) bool { return amount <= max },
+		})
+	}
+	if v.length != nil {
+		length := float64(*v.length)
+		rules = append(rules, validationRule{
+			message: fmt.Sprintf("must have exactly %d%s", *v.length, unit),
+			ok:      func(_ *fieldValidator, _ reflect.Value, amount float64) bool { return amount == length },
+		})
+	}
+	if len(v.oneOf) > 0 {
+		rules = append(rules, validationRule{
+			message: fmt.Sprintf("must be one of %s", strings.Join(v.oneOf, ", ")),
+			ok:      (*fieldValidator).isOneOf,
+		})
+	}
+	if v.regex != nil {
+		rules = append(rules, validationRule{
+			message: fmt.Sprintf("must match %s", v.regex),
+			ok: func(v *fieldValidator, value reflect.Value, _ float64) bool {
+				return v.regex.MatchString(value.String())
+			},
+		})
+	}
+	return rules
+}
+
+// isOneOf returns if value is one of the values of the oneof rule of v.
+func (v *fieldValidator) isOneOf(value reflect.Value, _ float64) bool {
+	var actual string
+	switch v.kind {
+	case validateEnum:
+		actual = v.enum.ReverseMap[value.Interface()]
+	case validateNumber:
+		number := numberValue(value)
+		for _, option := range v.oneOf {
+			if parsed, _ := strconv.ParseFloat(option, 64); parsed == number {
+				return true
+			}
+		}
+		return false
+	default:
+		actual = value.String()
+	}
+	for _, option := range v.oneOf {
+		if option == actual {
+			return true
+		}
+	}
+	return false
+}
+
+// describe returns the rules of v as sentences, for descriptions.
+func (v *fieldValidator) describe() string {
+	var sentences []string
+	if v.required {
+		sentences = append(sentences, "Must not be empty.")
+	}
+	for _, rule := range v.rules {
+		sentences = append(sentences, strings.ToUpper(rule.message[:1])+rule.message[1:]+".")
+	}
+	return strings.Join(sentences, " ")
+}
+
+// numberValue returns the value of a number as a float64.
+func numberValue(value reflect.Value) float64 {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint())
+	default:
+		return value.Float()
+	}
+}
+
+// formatBound formats the argument of a min or max rule.
+func formatBound(bound float64) string {
+	return strconv.FormatFloat(bound, 'f', -1, 64)
+}
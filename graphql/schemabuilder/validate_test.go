@@ -0,0 +1,146 @@
+package schemabuilder_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/samsarahq/thunder/graphql"
+	"github.com/samsarahq/thunder/graphql/schemabuilder"
+	"github.com/samsarahq/thunder/internal"
+	"github.com/samsarahq/thunder/internal/testgraphql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type validateColor int32
+
+type validateFilter struct {
+	Name  *string        `validate:"regex=^[a-z]+(,[a-z]+)*$"`
+	Tags  []string       `graphql:",optional" validate:"max=2"`
+	Color *validateColor `validate:"oneof=red green"`
+}
+
+type validateArgs struct {
+	Query  string `validate:"required,max=10" description:"The text to search for."`
+	Limit  int64  `validate:"min=1,max=1000"`
+	Code   string `graphql:",optional" validate:"len=3"`
+	Sort   *int64 `validate:"oneof=1 -1"`
+	Filter *validateFilter
+}
+
+func makeValidateSchema(describe bool) *schemabuilder.Schema {
+	schema := schemabuilder.NewSchema()
+	if describe {
+		schema.DescribeValidations()
+	}
+	schema.Enum(validateColor(0), map[string]validateColor{
+		"red":   0,
+		"green": 1,
+		"blue":  2,
+	})
+	schema.Query().FieldFunc("search", func(args validateArgs) string {
+		return args.Query
+	})
+	return schema
+}
+
+func TestValidateArgs(t *testing.T) {
+	schema := makeValidateSchema(false).MustBuild()
+
+	for _, tc := range []struct {
+		name  string
+		query string
+		err   string
+	}{
+		{"valid", `{ search(query: "a", limit: 1, code: "abc", sort: -1, filter: {name: "a,b", tags: ["x"], color: green}) }`, ""},
+		{"unset optional fields", `{ search(query: "a", limit: 1000) }`, ""},
+		{"required", `{ search(query: "", limit: 1) }`, `error parsing args for "search": query: must not be empty`},
+		{"max length", `{ search(query: "abcdefghijk", limit: 1) }`, `error parsing args for "search": query: must have at most 10 characters`},
+		{"min", `{ search(query: "a", limit: 0) }`, `error parsing args for "search": limit: must be at least 1`},
+		{"max", `{ search(query: "a", limit: 1001) }`, `error parsing args for "search": limit: must be at most 1000`},
+		{"len", `{ search(query: "a", limit: 1, code: "ab") }`, `error parsing args for "search": code: must have exactly 3 characters`},
+		{"oneof number", `{ search(query: "a", limit: 1, sort: 0) }`, `error parsing args for "search": sort: must be one of 1, -1`},
+		{"regex", `{ search(query: "a", limit: 1, filter: {name: "a;b"}) }`, `error parsing args for "search": filter: name: must match ^[a-z]+(,[a-z]+)*$`},
+		{"max items", `{ search(query: "a", limit: 1, filter: {tags: ["x", "y", "z"]}) }`, `error parsing args for "search": filter: tags: must have at most 2 items`},
+		{"oneof enum", `{ search(query: "a", limit: 1, filter: {color: blue}) }`, `error parsing args for "search": filter: color: must be one of red, green`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			q := graphql.MustParse(tc.query, nil)
+			err := graphql.PrepareQuery(context.Background(), schema.Query, q.SelectionSet)
+			if tc.err == "" {
+				require.NoError(t, err)
+				e := testgraphql.NewExecutorWrapper(t)
+				result, err := e.Execute(context.Background(), schema.Query, nil, q)
+				require.NoError(t, err)
+				assert.Equal(t, internal.ParseJSON(`{"search": "a"}`), internal.AsJSON(result))
+				return
+			}
+			require.Error(t, err)
+			assert.Equal(t, tc.err, err.Error())
+			_, ok := err.(graphql.ClientError)
+			assert.True(t, ok, "expected a client error, received %T", err)
+		})
+	}
+}
+
+func TestValidateDescriptions(t *testing.T) {
+	search := makeValidateSchema(false).MustBuild().Query.(*graphql.Object).Fields["search"]
+	assert.Equal(t, map[string]string{"query": "The text to search for."}, search.ArgDescriptions)
+
+	search = makeValidateSchema(true).MustBuild().Query.(*graphql.Object).Fields["search"]
+	assert.Equal(t, map[string]string{
+		"query": "The text to search for. Must not be empty. Must have at most 10 characters.",
+		"limit": "Must be at least 1. Must be at most 1000.",
+		"code":  "Must have exactly 3 characters.",
+		"sort":  "Must be one of 1, -1.",
+	}, search.ArgDescriptions)
+
+	filter := search.Args["filter"].(*graphql.InputObject)
+	assert.Equal(t, map[string]string{
+		"name":  "Must match ^[a-z]+(,[a-z]+)*$.",
+		"tags":  "Must have at most 2 items.",
+		"color": "Must be one of red, green.",
+	}, filter.InputFieldDescriptions)
+}
+
+func TestValidateTagErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		fn   interface{}
+		err  string
+	}{
+		{"unknown rule", func(args struct {
+			A string `validate:"email"`
+		}) string {
+			return ""
+		}, "bad validate tag on A: unknown rule email"},
+		{"unsupported rule", func(args struct {
+			A bool `validate:"min=1"`
+		}) string {
+			return ""
+		}, "bad validate tag on A: rule min is not supported on this type"},
+		{"bad bound", func(args struct {
+			A int64 `validate:"max=many"`
+		}) string {
+			return ""
+		}, "bad validate tag on A: rule max must have a number"},
+		{"bad regex", func(args struct {
+			A string `validate:"regex=("`
+		}) string {
+			return ""
+		}, "bad validate tag on A: rule regex must have a valid regular expression"},
+		{"unknown enum value", func(args struct {
+			A validateColor `validate:"oneof=red purple"`
+		}) string {
+			return ""
+		}, "bad validate tag on A: unknown enum value purple"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			schema := makeValidateSchema(false)
+			schema.Query().FieldFunc("bad", tc.fn)
+			_, err := schema.Build()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.err)
+		})
+	}
+}
@@ -0,0 +1,488 @@
+// Package schemadiff compares two versions of a GraphQL schema, and
+// classifies each change by how it affects existing clients.
+//
+// Schemas can be built with schemabuilder, or loaded from SDL and
+// introspection results with the sdl package.
+package schemadiff
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/samsarahq/thunder/graphql"
+	"github.com/samsarahq/thunder/graphql/sdl"
+)
+
+// Criticality classifies how a change affects existing clients.
+type Criticality int
+
+const (
+	// Safe changes do not affect existing clients.
+	Safe Criticality = iota
+	// Dangerous changes do not break existing queries, but might change how
+	// existing clients behave, such as adding a value to an enum that
+	// clients switch over.
+	Dangerous
+	// Breaking changes make existing queries invalid, or change the type of
+	// their results.
+	Breaking
+)
+
+func (c Criticality) String() string {
+	switch c {
+	case Safe:
+		return "safe"
+	case Dangerous:
+		return "dangerous"
+	case Breaking:
+		return "breaking"
+	default:
+		return fmt.Sprintf("Criticality(%d)", int(c))
+	}
+}
+
+// A Change is a difference between two schemas.
+type Change struct {
+	Criticality Criticality
+	// Path is the schema coordinate of the changed element, such as "User",
+	// "User.name", "User.friends(first:)" or "@auth".
+	Path    string
+	Message string
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %s", c.Criticality, c.Message)
+}
+
+// MaxCriticality returns the highest criticality of changes, or Safe if
+// there are none.
+func MaxCriticality(changes []Change) Criticality {
+	max := Safe
+	for _, change := range changes {
+		if change.Criticality > max {
+			max = change.Criticality
+		}
+	}
+	return max
+}
+
+// Diff returns the changes from oldSchema to newSchema, ordered by the
+// elements they change.
+func Diff(oldSchema, newSchema *graphql.Schema) []Change {
+	d := &differ{}
+	d.diffRoots(oldSchema, newSchema)
+	d.diffDirectives(oldSchema.Directives, newSchema.Directives)
+
+	oldTypes, newTypes := sdl.CollectTypes(oldSchema), sdl.CollectTypes(newSchema)
+	for _, name := range unionKeys(oldTypes, newTypes) {
+		oldType, inOld := oldTypes[name]
+		newType, inNew := newTypes[name]
+		switch {
+		case !inNew:
+			d.add(Breaking, name, "Type %s was removed.", name)
+		case !inOld:
+			d.add(Safe, name, "Type %s was added.", name)
+		default:
+			d.diffType(name, oldType, newType)
+		}
+	}
+	return d.changes
+}
+
+type differ struct {
+	changes []Change
+}
+
+func (d *differ) add(criticality Criticality, path string, format string, args ...interface{}) {
+	d.changes = append(d.changes, Change{
+		Criticality: criticality,
+		Path:        path,
+		Message:     fmt.Sprintf(format, args...),
+	})
+}
+
+func (d *differ) diffRoots(oldSchema, newSchema *graphql.Schema) {
+	for _, root := range []struct {
+		operation string
+		old, new  graphql.Type
+	}{
+		{"query", oldSchema.Query, newSchema.Query},
+		{"mutation", oldSchema.Mutation, newSchema.Mutation},
+		{"subscription", oldSchema.Subscription, newSchema.Subscription},
+	} {
+		oldName, newName := rootName(root.old), rootName(root.new)
+		switch {
+		case oldName == newName:
+		case newName == "":
+			d.add(Breaking, "schema", "Schema %s type %s was removed.", root.operation, oldName)
+		case oldName == "":
+			d.add(Safe, "schema", "Schema %s type %s was added.", root.operation, newName)
+		default:
+			d.add(Breaking, "schema", "Schema %s type changed from %s to %s.", root.operation, oldName, newName)
+		}
+	}
+}
+
+func rootName(typ graphql.Type) string {
+	if typ == nil {
+		return ""
+	}
+	return typ.String()
+}
+
+func (d *differ) diffDirectives(oldDirectives, newDirectives map[string]*graphql.DirectiveDefinition) {
+	for _, name := range unionKeys(oldDirectives, newDirectives) {
+		path := "@" + name
+		oldDirective, inOld := oldDirectives[name]
+		newDirective, inNew := newDirectives[name]
+		switch {
+		case !inNew:
+			d.add(Breaking, path, "Directive %s was removed.", path)
+			continue
+		case !inOld:
+			d.add(Safe, path, "Directive %s was added.", path)
+			continue
+		}
+
+		d.diffArgs(path, "directive "+path, oldDirective.Args, newDirective.Args)
+
+		newLocations := make(map[graphql.DirectiveLocation]bool)
+		for _, location := range newDirective.Locations {
+			newLocations[location] = true
+		}
+		oldLocations := make(map[graphql.DirectiveLocation]bool)
+		for _, location := range oldDirective.Locations {
+			oldLocations[location] = true
+			if !newLocations[location] {
+				d.add(Breaking, path, "Location %s was removed from directive %s.", location, path)
+			}
+		}
+		for _, location := range newDirective.Locations {
+			if !oldLocations[location] {
+				d.add(Safe, path, "Location %s was added to directive %s.", location, path)
+			}
+		}
+	}
+}
+
+func (d *differ) diffType(name string, oldType, newType graphql.Type) {
+	if kindOf(oldType) != kindOf(newType) {
+		d.add(Breaking, name, "%s changed from %s to %s.", name, kindOf(oldType), kindOf(newType))
+		return
+	}
+
+	switch oldType := oldType.(type) {
+	case *graphql.Object:
+		newType := newType.(*graphql.Object)
+		d.diffDescription(name, "type "+name, oldType.Description, newType.Description)
+		d.diffMembers(name, keys(oldType.Interfaces), keys(newType.Interfaces),
+			"Type %s no longer implements interface %s.", "Type %s now implements interface %s.")
+		d.diffFields(name, oldType.Fields, newType.Fields)
+
+	case *graphql.Interface:
+		newType := newType.(*graphql.Interface)
+		d.diffDescription(name, "interface "+name, oldType.Description, newType.Description)
+		d.diffFields(name, oldType.Fields, newType.Fields)
+
+	case *graphql.Union:
+		newType := newType.(*graphql.Union)
+		d.diffDescription(name, "union "+name, oldType.Description, newType.Description)
+		d.diffMembers(name, keys(oldType.Types), keys(newType.Types),
+			"Type %[2]s was removed from union %[1]s.", "Type %[2]s was added to union %[1]s.")
+
+	case *graphql.Enum:
+		d.diffEnum(name, oldType, newType.(*graphql.Enum))
+
+	case *graphql.InputObject:
+		d.diffInputObject(name, oldType, newType.(*graphql.InputObject))
+	}
+}
+
+// kindOf returns a description of the kind of a named type.
+func kindOf(typ graphql.Type) string {
+	switch typ.(type) {
+	case *graphql.Scalar:
+		return "a scalar type"
+	case *graphql.Object:
+		return "an object type"
+	case *graphql.Interface:
+		return "an interface type"
+	case *graphql.Union:
+		return "a union type"
+	case *graphql.Enum:
+		return "an enum type"
+	case *graphql.InputObject:
+		return "an input object type"
+	default:
+		return "an unknown type"
+	}
+}
+
+func (d *differ) diffDescription(path, what, oldDescription, newDescription string) {
+	if oldDescription != newDescription {
+		d.add(Safe, path, "Description of %s changed.", what)
+	}
+}
+
+// diffMembers reports the members of a union or interfaces of an object that
+// were removed, which is breaking, or added, which is dangerous. The formats
+// are passed the name of the type and of the member.
+func (d *differ) diffMembers(name string, oldMembers, newMembers []string, removedFormat, addedFormat string) {
+	for _, member := range difference(oldMembers, newMembers) {
+		d.add(Breaking, name, removedFormat, name, member)
+	}
+	for _, member := range difference(newMembers, oldMembers) {
+		d.add(Dangerous, name, addedFormat, name, member)
+	}
+}
+
+func (d *differ) diffFields(typeName string, oldFields, newFields map[string]*graphql.Field) {
+	for _, name := range unionKeys(oldFields, newFields) {
+		if strings.HasPrefix(name, "__") {
+			// Introspection fields are not part of the schema.
+			continue
+		}
+		path := typeName + "." + name
+		oldField, inOld := oldFields[name]
+		newField, inNew := newFields[name]
+		switch {
+		case !inNew:
+			d.add(Breaking, path, "Field %s was removed.", path)
+			continue
+		case !inOld:
+			d.add(Safe, path, "Field %s was added.", path)
+			continue
+		}
+
+		if oldField.Type.String() != newField.Type.String() {
+			criticality := Breaking
+			if isSafeOutputTypeChange(oldField.Type, newField.Type) {
+				criticality = Safe
+			}
+			d.add(criticality, path, "Field %s changed type from %s to %s.", path, oldField.Type, newField.Type)
+		}
+		d.diffArgs(path, "field "+path, oldField.Args, newField.Args)
+		d.diffDescription(path, "field "+path, oldField.Description, newField.Description)
+		d.diffDeprecation(path, "Field "+path, oldField.IsDeprecated, newField.IsDeprecated, newField.DeprecationReason)
+	}
+}
+
+func (d *differ) diffDeprecation(path, what string, oldDeprecated, newDeprecated bool, reason string) {
+	switch {
+	case newDeprecated && !oldDeprecated:
+		d.add(Safe, path, "%s was deprecated: %s", what, reason)
+	case oldDeprecated && !newDeprecated:
+		d.add(Safe, path, "%s is no longer deprecated.", what)
+	}
+}
+
+// diffArgs compares the arguments of a field or directive. owner describes
+// the field or directive for messages.
+func (d *differ) diffArgs(path, owner string, oldArgs, newArgs map[string]graphql.Type) {
+	for _, name := range unionKeys(oldArgs, newArgs) {
+		argPath := path + "(" + name + ":)"
+		oldArg, inOld := oldArgs[name]
+		newArg, inNew := newArgs[name]
+		switch {
+		case !inNew:
+			d.add(Breaking, argPath, "Argument %s was removed from %s.", name, owner)
+		case !inOld:
+			if _, required := newArg.(*graphql.NonNull); required {
+				d.add(Breaking, argPath, "Required argument %s was added to %s.", name, owner)
+			} else {
+				d.add(Dangerous, argPath, "Optional argument %s was added to %s.", name, owner)
+			}
+		case oldArg.String() != newArg.String():
+			criticality := Breaking
+			if isSafeInputTypeChange(oldArg, newArg) {
+				criticality = Safe
+			}
+			d.add(criticality, argPath, "Argument %s of %s changed type from %s to %s.", name, owner, oldArg, newArg)
+		}
+	}
+}
+
+func (d *differ) diffEnum(name string, oldEnum, newEnum *graphql.Enum) {
+	oldValues := make(map[string]bool)
+	for _, value := range oldEnum.Values {
+		oldValues[value] = true
+	}
+	newValues := make(map[string]bool)
+	for _, value := range newEnum.Values {
+		newValues[value] = true
+	}
+
+	for _, value := range unionKeys(oldValues, newValues) {
+		path := name + "." + value
+		switch {
+		case !newValues[value]:
+			d.add(Breaking, path, "Enum value %s was removed from enum %s.", value, name)
+		case !oldValues[value]:
+			d.add(Dangerous, path, "Enum value %s was added to enum %s.", value, name)
+		default:
+			_, oldDeprecated := oldEnum.DeprecatedValues[value]
+			reason, newDeprecated := newEnum.DeprecatedValues[value]
+			d.diffDeprecation(path, "Enum value "+path, oldDeprecated, newDeprecated, reason)
+			d.diffDescription(path, "enum value "+path, oldEnum.ValueDescriptions[value], newEnum.ValueDescriptions[value])
+		}
+	}
+}
+
+func (d *differ) diffInputObject(name string, oldInput, newInput *graphql.InputObject) {
+	switch {
+	case !oldInput.OneOf && newInput.OneOf:
+		d.add(Breaking, name, "Input object %s became a one-of input object.", name)
+	case oldInput.OneOf && !newInput.OneOf:
+		d.add(Safe, name, "Input object %s is no longer a one-of input object.", name)
+	}
+	for _, field := range unionKeys(oldInput.InputFields, newInput.InputFields) {
+		path := name + "." + field
+		oldField, inOld := oldInput.InputFields[field]
+		newField, inNew := newInput.InputFields[field]
+		switch {
+		case !inNew:
+			d.add(Breaking, path, "Input field %s was removed.", path)
+		case !inOld:
+			if _, required := newField.(*graphql.NonNull); required {
+				d.add(Breaking, path, "Required input field %s was added.", path)
+			} else {
+				d.add(Dangerous, path, "Optional input field %s was added.", path)
+			}
+		default:
+			if oldField.String() != newField.String() {
+				criticality := Breaking
+				if isSafeInputTypeChange(oldField, newField) {
+					criticality = Safe
+				}
+				d.add(criticality, path, "Input field %s changed type from %s to %s.", path, oldField, newField)
+			}
+			d.diffDescription(path, "input field "+path, oldInput.InputFieldDescriptions[field], newInput.InputFieldDescriptions[field])
+		}
+	}
+}
+
+// isSafeOutputTypeChange returns whether the type of a field can change from
+// oldType to newType without breaking clients, which is the case if newType
+// is the same type, or only more strictly non-null.
+func isSafeOutputTypeChange(oldType, newType graphql.Type) bool {
+	switch oldType := oldType.(type) {
+	case *graphql.List:
+		switch newType := newType.(type) {
+		case *graphql.List:
+			return isSafeOutputTypeChange(oldType.Type, newType.Type)
+		case *graphql.NonNull:
+			return isSafeOutputTypeChange(oldType, newType.Type)
+		}
+		return false
+	case *graphql.NonNull:
+		if newType, ok := newType.(*graphql.NonNull); ok {
+			return isSafeOutputTypeChange(oldType.Type, newType.Type)
+		}
+		return false
+	default:
+		if newType, ok := newType.(*graphql.NonNull); ok {
+			return isSafeOutputTypeChange(oldType, newType.Type)
+		}
+		return isNamed(newType) && oldType.String() == newType.String()
+	}
+}
+
+// isSafeInputTypeChange returns whether the type of an argument or input
+// field can change from oldType to newType without breaking clients, which
+// is the case if newType is the same type, or only less strictly non-null.
+func isSafeInputTypeChange(oldType, newType graphql.Type) bool {
+	switch oldType := oldType.(type) {
+	case *graphql.List:
+		if newType, ok := newType.(*graphql.List); ok {
+			return isSafeInputTypeChange(oldType.Type, newType.Type)
+		}
+		return false
+	case *graphql.NonNull:
+		if newType, ok := newType.(*graphql.NonNull); ok {
+			return isSafeInputTypeChange(oldType.Type, newType.Type)
+		}
+		return isSafeInputTypeChange(oldType.Type, newType)
+	default:
+		return isNamed(newType) && oldType.String() == newType.String()
+	}
+}
+
+func isNamed(typ graphql.Type) bool {
+	switch typ.(type) {
+	case *graphql.List, *graphql.NonNull:
+		return false
+	default:
+		return true
+	}
+}
+
+// unionKeys returns the keys of two maps with the same key type, sorted.
+func unionKeys(a, b interface{}) []string {
+	return mergeSorted(keys(a), keys(b))
+}
+
+// keys returns the sorted keys of a map with string keys.
+func keys(m interface{}) []string {
+	var result []string
+	switch m := m.(type) {
+	case map[string]graphql.Type:
+		for key := range m {
+			result = append(result, key)
+		}
+	case map[string]*graphql.Field:
+		for key := range m {
+			result = append(result, key)
+		}
+	case map[string]*graphql.Object:
+		for key := range m {
+			result = append(result, key)
+		}
+	case map[string]*graphql.Interface:
+		for key := range m {
+			result = append(result, key)
+		}
+	case map[string]*graphql.DirectiveDefinition:
+		for key := range m {
+			result = append(result, key)
+		}
+	case map[string]bool:
+		for key := range m {
+			result = append(result, key)
+		}
+	default:
+		panic(fmt.Sprintf("unexpected map %T", m))
+	}
+	sort.Strings(result)
+	return result
+}
+
+func mergeSorted(a, b []string) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	var result []string
+	for _, list := range [][]string{a, b} {
+		for _, key := range list {
+			if !seen[key] {
+				seen[key] = true
+				result = append(result, key)
+			}
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// difference returns the elements of a that are not in b.
+func difference(a, b []string) []string {
+	inB := make(map[string]bool, len(b))
+	for _, key := range b {
+		inB[key] = true
+	}
+	var result []string
+	for _, key := range a {
+		if !inB[key] {
+			result = append(result, key)
+		}
+	}
+	return result
+}
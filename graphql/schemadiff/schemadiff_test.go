@@ -0,0 +1,157 @@
+package schemadiff_test
+
+import (
+	"testing"
+
+	"github.com/samsarahq/thunder/graphql/schemadiff"
+	"github.com/samsarahq/thunder/graphql/sdl"
+	"github.com/stretchr/testify/assert"
+)
+
+const oldSDL = `
+directive @auth(role: String!) on FIELD_DEFINITION | OBJECT
+
+type Query {
+  user(id: ID!): User
+  users(first: Int): [User!]!
+  search(query: String!): SearchResult
+  legacy: String
+}
+
+type User {
+  id: ID!
+  name: String
+  email: String!
+  status: Status!
+}
+
+type Post {
+  id: ID!
+}
+
+type Comment {
+  id: ID!
+}
+
+union SearchResult = User | Post
+
+enum Status {
+  ACTIVE
+  DISABLED
+  BANNED
+}
+
+input UserFilter {
+  name: String
+  status: Status!
+}
+
+type Mutation {
+  updateUser(filter: UserFilter): User
+}
+`
+
+const newSDL = `
+directive @auth(role: String!) on FIELD_DEFINITION
+
+type Query {
+  user(id: ID!, locale: String): User
+  users(first: Int!): [User!]!
+  search(query: String!): SearchResult
+  """Old things."""
+  legacy: String @deprecated(reason: "Use user.")
+}
+
+type User {
+  id: ID!
+  name: String!
+  email: String
+  status: Status!
+  createdAt: String
+}
+
+type Post {
+  id: ID!
+}
+
+type Comment {
+  id: ID!
+}
+
+union SearchResult = User | Comment
+
+enum Status {
+  ACTIVE
+  DISABLED
+  INVITED
+}
+
+input UserFilter {
+  name: String
+  status: Status
+  role: String!
+}
+
+type Mutation {
+  updateUser(filter: UserFilter): User
+}
+`
+
+func TestDiff(t *testing.T) {
+	changes := schemadiff.Diff(sdl.MustParse(oldSDL), sdl.MustParse(newSDL))
+
+	var messages []string
+	for _, change := range changes {
+		messages = append(messages, change.String())
+	}
+	assert.Equal(t, []string{
+		"breaking: Location OBJECT was removed from directive @auth.",
+		// Comment and Post are only reachable through SearchResult.
+		"safe: Type Comment was added.",
+		"breaking: Type Post was removed.",
+		"safe: Description of field Query.legacy changed.",
+		"safe: Field Query.legacy was deprecated: Use user.",
+		"dangerous: Optional argument locale was added to field Query.user.",
+		"breaking: Argument first of field Query.users changed type from Int to Int!.",
+		"breaking: Type Post was removed from union SearchResult.",
+		"dangerous: Type Comment was added to union SearchResult.",
+		"breaking: Enum value BANNED was removed from enum Status.",
+		"dangerous: Enum value INVITED was added to enum Status.",
+		"safe: Field User.createdAt was added.",
+		"breaking: Field User.email changed type from String! to String.",
+		"safe: Field User.name changed type from String to String!.",
+		"breaking: Required input field UserFilter.role was added.",
+		"safe: Input field UserFilter.status changed type from Status! to Status.",
+	}, messages)
+	assert.Equal(t, schemadiff.Breaking, schemadiff.MaxCriticality(changes))
+}
+
+func TestDiffIdentical(t *testing.T) {
+	changes := schemadiff.Diff(sdl.MustParse(oldSDL), sdl.MustParse(oldSDL))
+	assert.Empty(t, changes)
+	assert.Equal(t, schemadiff.Safe, schemadiff.MaxCriticality(changes))
+}
+
+func TestDiffKindChange(t *testing.T) {
+	changes := schemadiff.Diff(
+		sdl.MustParse("type Query { status: Status }\nenum Status { ON }"),
+		sdl.MustParse("type Query { status: Status }\ntype Status { on: Status }"),
+	)
+	assert.Equal(t, []schemadiff.Change{{
+		Criticality: schemadiff.Breaking,
+		Path:        "Status",
+		Message:     "Status changed from an enum type to an object type.",
+	}}, changes)
+}
+
+func TestDiffOneOf(t *testing.T) {
+	changes := schemadiff.Diff(
+		sdl.MustParse("type Query { user(by: UserBy): String }\ninput UserBy { id: ID name: String }"),
+		sdl.MustParse("type Query { user(by: UserBy): String }\ninput UserBy @oneOf { id: ID name: String }"),
+	)
+	assert.Equal(t, []schemadiff.Change{{
+		Criticality: schemadiff.Breaking,
+		Path:        "UserBy",
+		Message:     "Input object UserBy became a one-of input object.",
+	}}, changes)
+}
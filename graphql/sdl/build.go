@@ -0,0 +1,339 @@
+package sdl
+
+import (
+	"fmt"
+
+	"github.com/samsarahq/thunder/graphql"
+)
+
+// Parse parses a schema written in SDL. The returned schema describes the
+// types, fields and directives of the SDL, but its fields have no resolvers.
+//
+// Scalars are parsed as *graphql.Scalar with the name they are declared with,
+// and the built-in String, Int, Float, Boolean and ID scalars may be used
+// without being declared. The root types are those of the schema definition,
+// or else the types named Query, Mutation and Subscription.
+func Parse(source string) (*graphql.Schema, error) {
+	doc, err := parseDocument(source)
+	if err != nil {
+		return nil, err
+	}
+	return doc.build()
+}
+
+// MustParse parses a schema written in SDL, and panics on errors.
+func MustParse(source string) *graphql.Schema {
+	schema, err := Parse(source)
+	if err != nil {
+		panic(err)
+	}
+	return schema
+}
+
+// build builds the schema described by doc.
+func (doc *document) build() (*graphql.Schema, error) {
+	b := &builder{types: make(map[string]graphql.Type)}
+
+	// Declare every named type before building fields, so that types may
+	// reference each other in any order.
+	for _, definition := range doc.types {
+		if _, ok := b.types[definition.name]; ok || isBuiltinScalar(definition.name) {
+			return nil, fmt.Errorf("%d:%d: duplicate type %s", definition.line, definition.col, definition.name)
+		}
+		b.types[definition.name] = declareType(definition)
+	}
+
+	for _, definition := range doc.types {
+		if err := b.buildType(definition); err != nil {
+			return nil, fmt.Errorf("%d:%d: bad type %s: %s", definition.line, definition.col, definition.name, err)
+		}
+	}
+
+	schema := &graphql.Schema{}
+	operationTypes := doc.operationTypes
+	if operationTypes == nil {
+		operationTypes = make(map[string]string)
+		for operation, name := range map[string]string{"query": "Query", "mutation": "Mutation", "subscription": "Subscription"} {
+			if _, ok := b.types[name]; ok {
+				operationTypes[operation] = name
+			}
+		}
+	}
+	for operation, name := range operationTypes {
+		object, ok := b.types[name].(*graphql.Object)
+		if !ok {
+			return nil, fmt.Errorf("%s type %s must be an object type", operation, name)
+		}
+		switch operation {
+		case "query":
+			schema.Query = object
+		case "mutation":
+			schema.Mutation = object
+		case "subscription":
+			schema.Subscription = object
+		}
+	}
+	if schema.Query == nil {
+		return nil, fmt.Errorf("schema has no query type")
+	}
+
+	if len(doc.directives) > 0 {
+		schema.Directives = make(map[string]*graphql.DirectiveDefinition)
+	}
+	for _, definition := range doc.directives {
+		if _, ok := schema.Directives[definition.name]; ok {
+			return nil, fmt.Errorf("duplicate directive @%s", definition.name)
+		}
+		directive, err := b.buildDirective(definition)
+		if err != nil {
+			return nil, fmt.Errorf("bad directive @%s: %s", definition.name, err)
+		}
+		schema.Directives[definition.name] = directive
+	}
+
+	return schema, nil
+}
+
+// builder resolves the type references of a document.
+type builder struct {
+	types map[string]graphql.Type
+}
+
+// declareType returns the named type of definition, without any fields.
+func declareType(definition *typeDefinition) graphql.Type {
+	switch definition.kind {
+	case "scalar":
+		return &graphql.Scalar{
+			Type:           definition.name,
+			Description:    definition.description,
+			SpecifiedByURL: definition.specifiedByURL,
+		}
+	case "type":
+		return &graphql.Object{
+			Name:        definition.name,
+			Description: definition.description,
+			Fields:      make(map[string]*graphql.Field),
+		}
+	case "interface":
+		return &graphql.Interface{
+			Name:        definition.name,
+			Description: definition.description,
+			Fields:      make(map[string]*graphql.Field),
+			Types:       make(map[string]*graphql.Object),
+		}
+	case "union":
+		return &graphql.Union{
+			Name:        definition.name,
+			Description: definition.description,
+			Types:       make(map[string]*graphql.Object),
+		}
+	case "enum":
+		return &graphql.Enum{Type: definition.name}
+	case "input":
+		return &graphql.InputObject{
+			Name:                   definition.name,
+			InputFields:            make(map[string]graphql.Type),
+			InputFieldDescriptions: make(map[string]string),
+			OneOf:                  definition.oneOf,
+		}
+	default:
+		panic("unknown type definition " + definition.kind)
+	}
+}
+
+func (b *builder) buildType(definition *typeDefinition) error {
+	switch typ := b.types[definition.name].(type) {
+	case *graphql.Object:
+		if err := b.buildFields(definition.fields, typ.Fields); err != nil {
+			return err
+		}
+		for _, name := range definition.interfaces {
+			iface, ok := b.types[name].(*graphql.Interface)
+			if !ok {
+				return fmt.Errorf("cannot implement %s, which is not an interface", name)
+			}
+			if typ.Interfaces == nil {
+				typ.Interfaces = make(map[string]*graphql.Interface)
+			}
+			typ.Interfaces[name] = iface
+			iface.Types[typ.Name] = typ
+		}
+
+	case *graphql.Interface:
+		if err := b.buildFields(definition.fields, typ.Fields); err != nil {
+			return err
+		}
+
+	case *graphql.Union:
+		for _, name := range definition.members {
+			object, ok := b.types[name].(*graphql.Object)
+			if !ok {
+				return fmt.Errorf("member %s is not an object type", name)
+			}
+			typ.Types[name] = object
+		}
+
+	case *graphql.Enum:
+		typ.ReverseMap = make(map[interface{}]string)
+		for _, value := range definition.values {
+			if _, ok := typ.ReverseMap[value.name]; ok {
+				return fmt.Errorf("duplicate value %s", value.name)
+			}
+			typ.Values = append(typ.Values, value.name)
+			typ.ReverseMap[value.name] = value.name
+			if value.description != "" {
+				if typ.ValueDescriptions == nil {
+					typ.ValueDescriptions = make(map[string]string)
+				}
+				typ.ValueDescriptions[value.name] = value.description
+			}
+			if value.deprecated {
+				if typ.DeprecatedValues == nil {
+					typ.DeprecatedValues = make(map[string]string)
+				}
+				typ.DeprecatedValues[value.name] = value.deprecationReason
+			}
+		}
+
+	case *graphql.InputObject:
+		for _, field := range definition.inputFields {
+			if _, ok := typ.InputFields[field.name]; ok {
+				return fmt.Errorf("duplicate field %s", field.name)
+			}
+			fieldType, err := b.inputType(field.typ)
+			if err != nil {
+				return fmt.Errorf("bad field %s: %s", field.name, err)
+			}
+			typ.InputFields[field.name] = fieldType
+			if field.description != "" {
+				typ.InputFieldDescriptions[field.name] = field.description
+			}
+		}
+	}
+	return nil
+}
+
+func (b *builder) buildFields(definitions []*fieldDefinition, fields map[string]*graphql.Field) error {
+	for _, definition := range definitions {
+		if _, ok := fields[definition.name]; ok {
+			return fmt.Errorf("duplicate field %s", definition.name)
+		}
+		typ, err := b.outputType(definition.typ)
+		if err != nil {
+			return fmt.Errorf("bad field %s: %s", definition.name, err)
+		}
+		args, err := b.buildArgs(definition.args)
+		if err != nil {
+			return fmt.Errorf("bad field %s: %s", definition.name, err)
+		}
+		fields[definition.name] = &graphql.Field{
+			Type:              typ,
+			Args:              args,
+			Description:       definition.description,
+			IsDeprecated:      definition.deprecated,
+			DeprecationReason: definition.deprecationReason,
+		}
+	}
+	return nil
+}
+
+func (b *builder) buildArgs(definitions []*inputValueDefinition) (map[string]graphql.Type, error) {
+	args := make(map[string]graphql.Type)
+	for _, definition := range definitions {
+		if _, ok := args[definition.name]; ok {
+			return nil, fmt.Errorf("duplicate argument %s", definition.name)
+		}
+		typ, err := b.inputType(definition.typ)
+		if err != nil {
+			return nil, fmt.Errorf("bad argument %s: %s", definition.name, err)
+		}
+		args[definition.name] = typ
+	}
+	return args, nil
+}
+
+func (b *builder) buildDirective(definition *directiveDefinition) (*graphql.DirectiveDefinition, error) {
+	args, err := b.buildArgs(definition.args)
+	if err != nil {
+		return nil, err
+	}
+	locations := make([]graphql.DirectiveLocation, 0, len(definition.locations))
+	for _, location := range definition.locations {
+		locations = append(locations, graphql.DirectiveLocation(location))
+	}
+	return &graphql.DirectiveDefinition{
+		Name:        definition.name,
+		Description: definition.description,
+		Locations:   locations,
+		Args:        args,
+	}, nil
+}
+
+// outputType resolves a reference to the type of a field.
+func (b *builder) outputType(ref *typeRef) (graphql.Type, error) {
+	typ, err := b.resolve(ref)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := namedType(typ).(*graphql.InputObject); ok {
+		return nil, fmt.Errorf("input type %s cannot be the type of a field", typ)
+	}
+	return typ, nil
+}
+
+// inputType resolves a reference to the type of an argument or input field.
+func (b *builder) inputType(ref *typeRef) (graphql.Type, error) {
+	typ, err := b.resolve(ref)
+	if err != nil {
+		return nil, err
+	}
+	switch namedType(typ).(type) {
+	case *graphql.Scalar, *graphql.Enum, *graphql.InputObject:
+		return typ, nil
+	default:
+		return nil, fmt.Errorf("output type %s cannot be the type of an argument or input field", typ)
+	}
+}
+
+func (b *builder) resolve(ref *typeRef) (graphql.Type, error) {
+	switch {
+	case ref.list:
+		inner, err := b.resolve(ref.ofType)
+		if err != nil {
+			return nil, err
+		}
+		return &graphql.List{Type: inner}, nil
+
+	case ref.nonNull:
+		inner, err := b.resolve(ref.ofType)
+		if err != nil {
+			return nil, err
+		}
+		return &graphql.NonNull{Type: inner}, nil
+
+	default:
+		if typ, ok := b.types[ref.name]; ok {
+			return typ, nil
+		}
+		if isBuiltinScalar(ref.name) {
+			typ := &graphql.Scalar{Type: ref.name}
+			b.types[ref.name] = typ
+			return typ, nil
+		}
+		return nil, fmt.Errorf("unknown type %s", ref.name)
+	}
+}
+
+// namedType returns typ without its list and non-null wrappers.
+func namedType(typ graphql.Type) graphql.Type {
+	for {
+		switch inner := typ.(type) {
+		case *graphql.List:
+			typ = inner.Type
+		case *graphql.NonNull:
+			typ = inner.Type
+		default:
+			return typ
+		}
+	}
+}
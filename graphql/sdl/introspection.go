@@ -0,0 +1,248 @@
+package sdl
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/samsarahq/thunder/graphql"
+)
+
+type introspectionTypeRef struct {
+	Kind   string                `json:"kind"`
+	Name   string                `json:"name"`
+	OfType *introspectionTypeRef `json:"ofType"`
+}
+
+type introspectionInputValue struct {
+	Name        string                `json:"name"`
+	Description string                `json:"description"`
+	Type        *introspectionTypeRef `json:"type"`
+}
+
+type introspectionField struct {
+	Name              string                    `json:"name"`
+	Description       string                    `json:"description"`
+	Args              []introspectionInputValue `json:"args"`
+	Type              *introspectionTypeRef     `json:"type"`
+	IsDeprecated      bool                      `json:"isDeprecated"`
+	DeprecationReason string                    `json:"deprecationReason"`
+}
+
+type introspectionEnumValue struct {
+	Name              string `json:"name"`
+	Description       string `json:"description"`
+	IsDeprecated      bool   `json:"isDeprecated"`
+	DeprecationReason string `json:"deprecationReason"`
+}
+
+type introspectionType struct {
+	Kind           string                    `json:"kind"`
+	Name           string                    `json:"name"`
+	Description    string                    `json:"description"`
+	Fields         []introspectionField      `json:"fields"`
+	InputFields    []introspectionInputValue `json:"inputFields"`
+	IsOneOf        bool                      `json:"isOneOf"`
+	SpecifiedByURL string                    `json:"specifiedByURL"`
+	Interfaces     []introspectionTypeRef    `json:"interfaces"`
+	EnumValues     []introspectionEnumValue  `json:"enumValues"`
+	PossibleTypes  []introspectionTypeRef    `json:"possibleTypes"`
+}
+
+type introspectionDirective struct {
+	Name        string                    `json:"name"`
+	Description string                    `json:"description"`
+	Locations   []string                  `json:"locations"`
+	Args        []introspectionInputValue `json:"args"`
+}
+
+type introspectionSchema struct {
+	QueryType        *introspectionTypeRef    `json:"queryType"`
+	MutationType     *introspectionTypeRef    `json:"mutationType"`
+	SubscriptionType *introspectionTypeRef    `json:"subscriptionType"`
+	Types            []introspectionType      `json:"types"`
+	Directives       []introspectionDirective `json:"directives"`
+}
+
+type introspectionResult struct {
+	Schema *introspectionSchema `json:"__schema"`
+	Data   *struct {
+		Schema *introspectionSchema `json:"__schema"`
+	} `json:"data"`
+}
+
+// builtinDirectives are the directives every Thunder schema has, which are
+// not part of the parsed schema.
+var builtinDirectives = map[string]bool{
+	graphql.SKIP:             true,
+	graphql.INCLUDE:          true,
+	graphql.TYPE_AS_OPTIONAL: true,
+	graphql.ONE_OF:           true,
+	graphql.SPECIFIED_BY:     true,
+	"deprecated":             true,
+}
+
+// ParseIntrospection parses the JSON result of an introspection query, such
+// as the output of introspection.ComputeSchemaJSON. The result may also be
+// wrapped in a "data" key, as returned by a GraphQL server. Like Parse, it
+// returns a schema whose fields have no resolvers, and omits introspection
+// types and fields.
+func ParseIntrospection(data []byte) (*graphql.Schema, error) {
+	var result introspectionResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	source := result.Schema
+	if source == nil && result.Data != nil {
+		source = result.Data.Schema
+	}
+	if source == nil {
+		return nil, fmt.Errorf("missing __schema in introspection result")
+	}
+
+	// Convert the introspection result to a document, and build it as if it
+	// was parsed from SDL.
+	doc := &document{operationTypes: make(map[string]string)}
+	for operation, root := range map[string]*introspectionTypeRef{
+		"query":        source.QueryType,
+		"mutation":     source.MutationType,
+		"subscription": source.SubscriptionType,
+	} {
+		if root != nil && root.Name != "" {
+			doc.operationTypes[operation] = root.Name
+		}
+	}
+
+	for _, typ := range source.Types {
+		if isIntrospectionName(typ.Name) || isBuiltinScalar(typ.Name) {
+			continue
+		}
+		definition, err := convertIntrospectionType(typ)
+		if err != nil {
+			return nil, fmt.Errorf("bad type %s: %s", typ.Name, err)
+		}
+		doc.types = append(doc.types, definition)
+	}
+
+	for _, directive := range source.Directives {
+		if builtinDirectives[directive.Name] {
+			continue
+		}
+		args, err := convertIntrospectionInputValues(directive.Args)
+		if err != nil {
+			return nil, fmt.Errorf("bad directive @%s: %s", directive.Name, err)
+		}
+		doc.directives = append(doc.directives, &directiveDefinition{
+			name:        directive.Name,
+			description: directive.Description,
+			args:        args,
+			locations:   directive.Locations,
+		})
+	}
+
+	return doc.build()
+}
+
+func convertIntrospectionType(typ introspectionType) (*typeDefinition, error) {
+	definition := &typeDefinition{name: typ.Name, description: typ.Description}
+
+	switch typ.Kind {
+	case "SCALAR":
+		definition.kind = "scalar"
+		definition.specifiedByURL = typ.SpecifiedByURL
+
+	case "OBJECT", "INTERFACE":
+		definition.kind = "type"
+		if typ.Kind == "INTERFACE" {
+			definition.kind = "interface"
+		}
+		for _, iface := range typ.Interfaces {
+			definition.interfaces = append(definition.interfaces, iface.Name)
+		}
+		for _, field := range typ.Fields {
+			if isIntrospectionName(field.Name) {
+				continue
+			}
+			fieldType, err := convertIntrospectionTypeRef(field.Type)
+			if err != nil {
+				return nil, fmt.Errorf("bad field %s: %s", field.Name, err)
+			}
+			args, err := convertIntrospectionInputValues(field.Args)
+			if err != nil {
+				return nil, fmt.Errorf("bad field %s: %s", field.Name, err)
+			}
+			definition.fields = append(definition.fields, &fieldDefinition{
+				name:              field.Name,
+				description:       field.Description,
+				args:              args,
+				typ:               fieldType,
+				deprecated:        field.IsDeprecated,
+				deprecationReason: field.DeprecationReason,
+			})
+		}
+
+	case "UNION":
+		definition.kind = "union"
+		for _, member := range typ.PossibleTypes {
+			definition.members = append(definition.members, member.Name)
+		}
+
+	case "ENUM":
+		definition.kind = "enum"
+		for _, value := range typ.EnumValues {
+			definition.values = append(definition.values, &enumValueDefinition{
+				name:              value.Name,
+				description:       value.Description,
+				deprecated:        value.IsDeprecated,
+				deprecationReason: value.DeprecationReason,
+			})
+		}
+
+	case "INPUT_OBJECT":
+		definition.kind = "input"
+		inputFields, err := convertIntrospectionInputValues(typ.InputFields)
+		if err != nil {
+			return nil, err
+		}
+		definition.inputFields = inputFields
+		definition.oneOf = typ.IsOneOf
+
+	default:
+		return nil, fmt.Errorf("unknown kind %s", typ.Kind)
+	}
+	return definition, nil
+}
+
+func convertIntrospectionInputValues(values []introspectionInputValue) ([]*inputValueDefinition, error) {
+	definitions := make([]*inputValueDefinition, 0, len(values))
+	for _, value := range values {
+		typ, err := convertIntrospectionTypeRef(value.Type)
+		if err != nil {
+			return nil, fmt.Errorf("bad input value %s: %s", value.Name, err)
+		}
+		definitions = append(definitions, &inputValueDefinition{
+			name:        value.Name,
+			description: value.Description,
+			typ:         typ,
+		})
+	}
+	return definitions, nil
+}
+
+func convertIntrospectionTypeRef(ref *introspectionTypeRef) (*typeRef, error) {
+	if ref == nil {
+		return nil, fmt.Errorf("missing type")
+	}
+	switch ref.Kind {
+	case "LIST", "NON_NULL":
+		inner, err := convertIntrospectionTypeRef(ref.OfType)
+		if err != nil {
+			return nil, err
+		}
+		return &typeRef{list: ref.Kind == "LIST", nonNull: ref.Kind == "NON_NULL", ofType: inner}, nil
+	default:
+		if ref.Name == "" {
+			return nil, fmt.Errorf("missing type name")
+		}
+		return &typeRef{name: ref.Name}, nil
+	}
+}
@@ -0,0 +1,299 @@
+package sdl
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenPunctuator
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+// A token is a lexical token of SDL. The value of a string token is the
+// string it denotes, with escapes and block string indentation removed.
+type token struct {
+	kind  tokenKind
+	value string
+	line  int
+	col   int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of input"
+	case tokenString:
+		return strconv.Quote(t.value)
+	default:
+		return fmt.Sprintf("%q", t.value)
+	}
+}
+
+// lexer splits SDL source into tokens, skipping whitespace, commas and
+// comments.
+type lexer struct {
+	source string
+	pos    int
+	line   int
+	col    int
+}
+
+func newLexer(source string) *lexer {
+	return &lexer{source: source, line: 1, col: 1}
+}
+
+// errorf returns an error at the current position of the lexer.
+func (l *lexer) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%d:%d: %s", l.line, l.col, fmt.Sprintf(format, args...))
+}
+
+func (l *lexer) peekByte(offset int) byte {
+	if l.pos+offset >= len(l.source) {
+		return 0
+	}
+	return l.source[l.pos+offset]
+}
+
+// advance moves past n bytes, keeping track of lines and columns.
+func (l *lexer) advance(n int) {
+	for i := 0; i < n && l.pos < len(l.source); i++ {
+		if l.source[l.pos] == '\n' {
+			l.line++
+			l.col = 1
+		} else {
+			l.col++
+		}
+		l.pos++
+	}
+}
+
+func (l *lexer) skipIgnored() {
+	for l.pos < len(l.source) {
+		switch c := l.source[l.pos]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			l.advance(1)
+		case c == '#':
+			for l.pos < len(l.source) && l.source[l.pos] != '\n' {
+				l.advance(1)
+			}
+		case strings.HasPrefix(l.source[l.pos:], "\ufeff"):
+			l.pos += len("\ufeff")
+		default:
+			return
+		}
+	}
+}
+
+// next returns the next token of the source.
+func (l *lexer) next() (token, error) {
+	l.skipIgnored()
+	tok := token{line: l.line, col: l.col}
+	if l.pos >= len(l.source) {
+		tok.kind = tokenEOF
+		return tok, nil
+	}
+
+	c := l.source[l.pos]
+	switch {
+	case strings.HasPrefix(l.source[l.pos:], "..."):
+		l.advance(3)
+		tok.kind, tok.value = tokenPunctuator, "..."
+		return tok, nil
+
+	case strings.IndexByte("!$&():=@[]{|}", c) >= 0:
+		l.advance(1)
+		tok.kind, tok.value = tokenPunctuator, string(c)
+		return tok, nil
+
+	case c == '_' || isLetter(c):
+		start := l.pos
+		for l.pos < len(l.source) && (l.source[l.pos] == '_' || isLetter(l.source[l.pos]) || isDigit(l.source[l.pos])) {
+			l.advance(1)
+		}
+		tok.kind, tok.value = tokenName, l.source[start:l.pos]
+		return tok, nil
+
+	case c == '-' || isDigit(c):
+		return l.readNumber(tok)
+
+	case strings.HasPrefix(l.source[l.pos:], `"""`):
+		value, err := l.readBlockString()
+		if err != nil {
+			return tok, err
+		}
+		tok.kind, tok.value = tokenString, value
+		return tok, nil
+
+	case c == '"':
+		value, err := l.readString()
+		if err != nil {
+			return tok, err
+		}
+		tok.kind, tok.value = tokenString, value
+		return tok, nil
+
+	default:
+		r, _ := utf8.DecodeRuneInString(l.source[l.pos:])
+		return tok, l.errorf("unexpected character %q", r)
+	}
+}
+
+func (l *lexer) readNumber(tok token) (token, error) {
+	start := l.pos
+	tok.kind = tokenInt
+	if l.peekByte(0) == '-' {
+		l.advance(1)
+	}
+	if !isDigit(l.peekByte(0)) {
+		return tok, l.errorf("invalid number")
+	}
+	l.readDigits()
+	if l.peekByte(0) == '.' {
+		tok.kind = tokenFloat
+		l.advance(1)
+		if !isDigit(l.peekByte(0)) {
+			return tok, l.errorf("invalid number")
+		}
+		l.readDigits()
+	}
+	if c := l.peekByte(0); c == 'e' || c == 'E' {
+		tok.kind = tokenFloat
+		l.advance(1)
+		if c := l.peekByte(0); c == '+' || c == '-' {
+			l.advance(1)
+		}
+		if !isDigit(l.peekByte(0)) {
+			return tok, l.errorf("invalid number")
+		}
+		l.readDigits()
+	}
+	tok.value = l.source[start:l.pos]
+	return tok, nil
+}
+
+func (l *lexer) readDigits() {
+	for isDigit(l.peekByte(0)) {
+		l.advance(1)
+	}
+}
+
+func (l *lexer) readString() (string, error) {
+	l.advance(1)
+	var b strings.Builder
+	for {
+		if l.pos >= len(l.source) || l.source[l.pos] == '\n' {
+			return "", l.errorf("unterminated string")
+		}
+		c := l.source[l.pos]
+		switch c {
+		case '"':
+			l.advance(1)
+			return b.String(), nil
+		case '\\':
+			escape := l.peekByte(1)
+			switch escape {
+			case '"', '\\', '/':
+				b.WriteByte(escape)
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case 'u':
+				if l.pos+6 > len(l.source) {
+					return "", l.errorf("invalid unicode escape")
+				}
+				code, err := strconv.ParseUint(l.source[l.pos+2:l.pos+6], 16, 32)
+				if err != nil {
+					return "", l.errorf("invalid unicode escape")
+				}
+				b.WriteRune(rune(code))
+				l.advance(6)
+				continue
+			default:
+				return "", l.errorf("invalid escape \\%c", escape)
+			}
+			l.advance(2)
+		default:
+			r, size := utf8.DecodeRuneInString(l.source[l.pos:])
+			b.WriteRune(r)
+			l.advance(size)
+		}
+	}
+}
+
+func (l *lexer) readBlockString() (string, error) {
+	l.advance(3)
+	var b strings.Builder
+	for {
+		if l.pos >= len(l.source) {
+			return "", l.errorf("unterminated block string")
+		}
+		rest := l.source[l.pos:]
+		switch {
+		case strings.HasPrefix(rest, `"""`):
+			l.advance(3)
+			return blockStringValue(b.String()), nil
+		case strings.HasPrefix(rest, `\"""`):
+			b.WriteString(`"""`)
+			l.advance(4)
+		default:
+			r, size := utf8.DecodeRuneInString(rest)
+			b.WriteRune(r)
+			l.advance(size)
+		}
+	}
+}
+
+// blockStringValue removes the common indentation and leading and trailing
+// blank lines of a block string, as described in the GraphQL specification.
+func blockStringValue(raw string) string {
+	lines := strings.Split(strings.Replace(raw, "\r\n", "\n", -1), "\n")
+
+	common := -1
+	for _, line := range lines[1:] {
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+		if indent < len(line) && (common == -1 || indent < common) {
+			common = indent
+		}
+	}
+	if common > 0 {
+		for i := 1; i < len(lines); i++ {
+			if len(lines[i]) >= common {
+				lines[i] = lines[i][common:]
+			} else {
+				lines[i] = ""
+			}
+		}
+	}
+
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
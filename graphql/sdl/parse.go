@@ -0,0 +1,718 @@
+package sdl
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// The parser reads SDL into the definitions below, which are then built into
+// a *graphql.Schema by build.go.
+
+type document struct {
+	// operationTypes maps "query", "mutation" and "subscription" to the name
+	// of their root type, if the document has a schema definition.
+	operationTypes map[string]string
+	types          []*typeDefinition
+	directives     []*directiveDefinition
+}
+
+type typeDefinition struct {
+	// kind is the keyword of the definition: "scalar", "type", "interface",
+	// "union", "enum" or "input".
+	kind        string
+	name        string
+	description string
+	line, col   int
+
+	interfaces  []string
+	fields      []*fieldDefinition
+	members     []string
+	values      []*enumValueDefinition
+	inputFields []*inputValueDefinition
+	// oneOf is set for input objects with the @oneOf directive.
+	oneOf bool
+	// specifiedByURL is the url of the @specifiedBy directive of a scalar.
+	specifiedByURL string
+}
+
+type fieldDefinition struct {
+	name              string
+	description       string
+	args              []*inputValueDefinition
+	typ               *typeRef
+	deprecated        bool
+	deprecationReason string
+}
+
+type inputValueDefinition struct {
+	name        string
+	description string
+	typ         *typeRef
+}
+
+type enumValueDefinition struct {
+	name              string
+	description       string
+	deprecated        bool
+	deprecationReason string
+}
+
+type directiveDefinition struct {
+	name        string
+	description string
+	args        []*inputValueDefinition
+	locations   []string
+}
+
+// A typeRef is a reference to a type: a named type if name is set, or else a
+// list or non-null wrapper of ofType.
+type typeRef struct {
+	name    string
+	list    bool
+	nonNull bool
+	ofType  *typeRef
+}
+
+// directive is a directive applied to a definition.
+type directive struct {
+	name string
+	args map[string]interface{}
+}
+
+type parser struct {
+	lexer *lexer
+	tok   token
+}
+
+// parseDocument parses SDL source into a document.
+func parseDocument(source string) (*document, error) {
+	p := &parser{lexer: newLexer(source)}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	doc := &document{}
+	for p.tok.kind != tokenEOF {
+		var description string
+		if p.tok.kind == tokenString {
+			description = p.tok.value
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+		}
+
+		if p.tok.kind != tokenName {
+			return nil, p.unexpected()
+		}
+		line, col := p.tok.line, p.tok.col
+		switch keyword := p.tok.value; keyword {
+		case "schema":
+			if doc.operationTypes != nil {
+				return nil, p.errorf("duplicate schema definition")
+			}
+			operationTypes, err := p.parseSchemaDefinition()
+			if err != nil {
+				return nil, err
+			}
+			doc.operationTypes = operationTypes
+
+		case "directive":
+			definition, err := p.parseDirectiveDefinition()
+			if err != nil {
+				return nil, err
+			}
+			definition.description = description
+			doc.directives = append(doc.directives, definition)
+
+		case "scalar", "type", "interface", "union", "enum", "input":
+			definition, err := p.parseTypeDefinition()
+			if err != nil {
+				return nil, err
+			}
+			definition.description = description
+			definition.line, definition.col = line, col
+			doc.types = append(doc.types, definition)
+
+		case "extend":
+			return nil, p.errorf("type extensions are not supported")
+
+		default:
+			return nil, p.unexpected()
+		}
+	}
+	return doc, nil
+}
+
+func (p *parser) advance() error {
+	tok, err := p.lexer.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%d:%d: %s", p.tok.line, p.tok.col, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) unexpected() error {
+	return p.errorf("unexpected %s", p.tok)
+}
+
+// peek returns whether the current token is the punctuator punctuator.
+func (p *parser) peek(punctuator string) bool {
+	return p.tok.kind == tokenPunctuator && p.tok.value == punctuator
+}
+
+// skip advances past the current token if it is the punctuator punctuator,
+// and returns whether it did.
+func (p *parser) skip(punctuator string) (bool, error) {
+	if !p.peek(punctuator) {
+		return false, nil
+	}
+	return true, p.advance()
+}
+
+func (p *parser) expect(punctuator string) error {
+	if !p.peek(punctuator) {
+		return p.errorf("expected %q, got %s", punctuator, p.tok)
+	}
+	return p.advance()
+}
+
+func (p *parser) expectKeyword(keyword string) error {
+	if p.tok.kind != tokenName || p.tok.value != keyword {
+		return p.errorf("expected %q, got %s", keyword, p.tok)
+	}
+	return p.advance()
+}
+
+func (p *parser) expectName() (string, error) {
+	if p.tok.kind != tokenName {
+		return "", p.errorf("expected name, got %s", p.tok)
+	}
+	name := p.tok.value
+	return name, p.advance()
+}
+
+// parseSchemaDefinition parses
+//
+//	schema Directives? { (OperationType: Name)+ }
+func (p *parser) parseSchemaDefinition() (map[string]string, error) {
+	if err := p.expectKeyword("schema"); err != nil {
+		return nil, err
+	}
+	if _, err := p.parseDirectives(); err != nil {
+		return nil, err
+	}
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+
+	operationTypes := make(map[string]string)
+	for !p.peek("}") {
+		operation, err := p.expectName()
+		if err != nil {
+			return nil, err
+		}
+		switch operation {
+		case "query", "mutation", "subscription":
+		default:
+			return nil, p.errorf("unknown operation type %s", operation)
+		}
+		if _, ok := operationTypes[operation]; ok {
+			return nil, p.errorf("duplicate %s type", operation)
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		name, err := p.expectName()
+		if err != nil {
+			return nil, err
+		}
+		operationTypes[operation] = name
+	}
+	return operationTypes, p.advance()
+}
+
+// parseDirectiveDefinition parses
+//
+//	directive @Name ArgumentsDefinition? repeatable? on |? Location (| Location)*
+func (p *parser) parseDirectiveDefinition() (*directiveDefinition, error) {
+	if err := p.expectKeyword("directive"); err != nil {
+		return nil, err
+	}
+	if err := p.expect("@"); err != nil {
+		return nil, err
+	}
+	name, err := p.expectName()
+	if err != nil {
+		return nil, err
+	}
+	args, err := p.parseArgumentsDefinition()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind == tokenName && p.tok.value == "repeatable" {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.expectKeyword("on"); err != nil {
+		return nil, err
+	}
+	if _, err := p.skip("|"); err != nil {
+		return nil, err
+	}
+
+	definition := &directiveDefinition{name: name, args: args}
+	for {
+		location, err := p.expectName()
+		if err != nil {
+			return nil, err
+		}
+		definition.locations = append(definition.locations, location)
+		if ok, err := p.skip("|"); err != nil {
+			return nil, err
+		} else if !ok {
+			return definition, nil
+		}
+	}
+}
+
+// parseTypeDefinition parses a scalar, object, interface, union, enum or
+// input object type definition.
+func (p *parser) parseTypeDefinition() (*typeDefinition, error) {
+	definition := &typeDefinition{kind: p.tok.value}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	name, err := p.expectName()
+	if err != nil {
+		return nil, err
+	}
+	definition.name = name
+
+	switch definition.kind {
+	case "scalar":
+		directives, err := p.parseDirectives()
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range directives {
+			if d.name != "specifiedBy" {
+				continue
+			}
+			url, ok := d.args["url"].(string)
+			if !ok {
+				return nil, p.errorf("@specifiedBy on scalar %s must have a url", name)
+			}
+			definition.specifiedByURL = url
+		}
+
+	case "type", "interface":
+		if p.tok.kind == tokenName && p.tok.value == "implements" {
+			if definition.interfaces, err = p.parseImplementsInterfaces(); err != nil {
+				return nil, err
+			}
+		}
+		if _, err := p.parseDirectives(); err != nil {
+			return nil, err
+		}
+		if definition.fields, err = p.parseFieldsDefinition(); err != nil {
+			return nil, err
+		}
+
+	case "union":
+		if _, err := p.parseDirectives(); err != nil {
+			return nil, err
+		}
+		if ok, err := p.skip("="); err != nil {
+			return nil, err
+		} else if ok {
+			if definition.members, err = p.parseUnionMembers(); err != nil {
+				return nil, err
+			}
+		}
+
+	case "enum":
+		if _, err := p.parseDirectives(); err != nil {
+			return nil, err
+		}
+		if definition.values, err = p.parseEnumValuesDefinition(); err != nil {
+			return nil, err
+		}
+
+	case "input":
+		directives, err := p.parseDirectives()
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range directives {
+			if d.name == "oneOf" {
+				definition.oneOf = true
+			}
+		}
+		if ok, err := p.skip("{"); err != nil {
+			return nil, err
+		} else if ok {
+			for !p.peek("}") {
+				value, err := p.parseInputValueDefinition()
+				if err != nil {
+					return nil, err
+				}
+				definition.inputFields = append(definition.inputFields, value)
+			}
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return definition, nil
+}
+
+// parseImplementsInterfaces parses
+//
+//	implements &? Name (& Name)*
+func (p *parser) parseImplementsInterfaces() ([]string, error) {
+	if err := p.expectKeyword("implements"); err != nil {
+		return nil, err
+	}
+	if _, err := p.skip("&"); err != nil {
+		return nil, err
+	}
+
+	var interfaces []string
+	for {
+		name, err := p.expectName()
+		if err != nil {
+			return nil, err
+		}
+		interfaces = append(interfaces, name)
+		if ok, err := p.skip("&"); err != nil {
+			return nil, err
+		} else if !ok {
+			return interfaces, nil
+		}
+	}
+}
+
+// parseUnionMembers parses
+//
+//	|? Name (| Name)*
+func (p *parser) parseUnionMembers() ([]string, error) {
+	if _, err := p.skip("|"); err != nil {
+		return nil, err
+	}
+
+	var members []string
+	for {
+		name, err := p.expectName()
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, name)
+		if ok, err := p.skip("|"); err != nil {
+			return nil, err
+		} else if !ok {
+			return members, nil
+		}
+	}
+}
+
+// parseFieldsDefinition parses an optional
+//
+//	{ (Description? Name ArgumentsDefinition? : Type Directives?)+ }
+func (p *parser) parseFieldsDefinition() ([]*fieldDefinition, error) {
+	if ok, err := p.skip("{"); err != nil || !ok {
+		return nil, err
+	}
+
+	var fields []*fieldDefinition
+	for !p.peek("}") {
+		description, err := p.parseDescription()
+		if err != nil {
+			return nil, err
+		}
+		name, err := p.expectName()
+		if err != nil {
+			return nil, err
+		}
+		args, err := p.parseArgumentsDefinition()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		typ, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+		directives, err := p.parseDirectives()
+		if err != nil {
+			return nil, err
+		}
+
+		field := &fieldDefinition{name: name, description: description, args: args, typ: typ}
+		field.deprecated, field.deprecationReason = deprecation(directives)
+		fields = append(fields, field)
+	}
+	return fields, p.advance()
+}
+
+// parseEnumValuesDefinition parses an optional
+//
+//	{ (Description? Name Directives?)+ }
+func (p *parser) parseEnumValuesDefinition() ([]*enumValueDefinition, error) {
+	if ok, err := p.skip("{"); err != nil || !ok {
+		return nil, err
+	}
+
+	var values []*enumValueDefinition
+	for !p.peek("}") {
+		description, err := p.parseDescription()
+		if err != nil {
+			return nil, err
+		}
+		name, err := p.expectName()
+		if err != nil {
+			return nil, err
+		}
+		switch name {
+		case "true", "false", "null":
+			return nil, p.errorf("enum value cannot be %s", name)
+		}
+		directives, err := p.parseDirectives()
+		if err != nil {
+			return nil, err
+		}
+
+		value := &enumValueDefinition{name: name, description: description}
+		value.deprecated, value.deprecationReason = deprecation(directives)
+		values = append(values, value)
+	}
+	return values, p.advance()
+}
+
+// parseArgumentsDefinition parses an optional
+//
+//	( InputValueDefinition+ )
+func (p *parser) parseArgumentsDefinition() ([]*inputValueDefinition, error) {
+	if ok, err := p.skip("("); err != nil || !ok {
+		return nil, err
+	}
+
+	var args []*inputValueDefinition
+	for !p.peek(")") {
+		arg, err := p.parseInputValueDefinition()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	return args, p.advance()
+}
+
+// parseInputValueDefinition parses
+//
+//	Description? Name : Type DefaultValue? Directives?
+//
+// Default values are parsed but not kept, as graphql.Schema does not
+// describe them.
+func (p *parser) parseInputValueDefinition() (*inputValueDefinition, error) {
+	description, err := p.parseDescription()
+	if err != nil {
+		return nil, err
+	}
+	name, err := p.expectName()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	typ, err := p.parseType()
+	if err != nil {
+		return nil, err
+	}
+	if ok, err := p.skip("="); err != nil {
+		return nil, err
+	} else if ok {
+		if _, err := p.parseValue(); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := p.parseDirectives(); err != nil {
+		return nil, err
+	}
+	return &inputValueDefinition{name: name, description: description, typ: typ}, nil
+}
+
+func (p *parser) parseDescription() (string, error) {
+	if p.tok.kind != tokenString {
+		return "", nil
+	}
+	description := p.tok.value
+	return description, p.advance()
+}
+
+// parseType parses
+//
+//	Name | [ Type ] | Type !
+func (p *parser) parseType() (*typeRef, error) {
+	var typ *typeRef
+	if ok, err := p.skip("["); err != nil {
+		return nil, err
+	} else if ok {
+		inner, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		typ = &typeRef{list: true, ofType: inner}
+	} else {
+		name, err := p.expectName()
+		if err != nil {
+			return nil, err
+		}
+		typ = &typeRef{name: name}
+	}
+
+	if ok, err := p.skip("!"); err != nil {
+		return nil, err
+	} else if ok {
+		typ = &typeRef{nonNull: true, ofType: typ}
+	}
+	return typ, nil
+}
+
+// parseDirectives parses
+//
+//	(@ Name Arguments?)*
+func (p *parser) parseDirectives() ([]*directive, error) {
+	var directives []*directive
+	for p.peek("@") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		name, err := p.expectName()
+		if err != nil {
+			return nil, err
+		}
+
+		d := &directive{name: name, args: make(map[string]interface{})}
+		if ok, err := p.skip("("); err != nil {
+			return nil, err
+		} else if ok {
+			for !p.peek(")") {
+				argName, err := p.expectName()
+				if err != nil {
+					return nil, err
+				}
+				if err := p.expect(":"); err != nil {
+					return nil, err
+				}
+				value, err := p.parseValue()
+				if err != nil {
+					return nil, err
+				}
+				d.args[argName] = value
+			}
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+		}
+		directives = append(directives, d)
+	}
+	return directives, nil
+}
+
+// parseValue parses a constant value, and returns it like json.Unmarshal
+// would. Enum values are returned as strings.
+func (p *parser) parseValue() (interface{}, error) {
+	tok := p.tok
+	switch tok.kind {
+	case tokenInt, tokenFloat:
+		value, err := strconv.ParseFloat(tok.value, 64)
+		if err != nil {
+			return nil, p.errorf("invalid number %s", tok.value)
+		}
+		return value, p.advance()
+
+	case tokenString:
+		return tok.value, p.advance()
+
+	case tokenName:
+		switch tok.value {
+		case "true":
+			return true, p.advance()
+		case "false":
+			return false, p.advance()
+		case "null":
+			return nil, p.advance()
+		default:
+			return tok.value, p.advance()
+		}
+
+	case tokenPunctuator:
+		switch tok.value {
+		case "[":
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			list := []interface{}{}
+			for !p.peek("]") {
+				value, err := p.parseValue()
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, value)
+			}
+			return list, p.advance()
+
+		case "{":
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			object := map[string]interface{}{}
+			for !p.peek("}") {
+				name, err := p.expectName()
+				if err != nil {
+					return nil, err
+				}
+				if err := p.expect(":"); err != nil {
+					return nil, err
+				}
+				value, err := p.parseValue()
+				if err != nil {
+					return nil, err
+				}
+				object[name] = value
+			}
+			return object, p.advance()
+
+		case "$":
+			return nil, p.errorf("variables are not allowed in SDL")
+		}
+	}
+	return nil, p.unexpected()
+}
+
+// deprecation returns whether directives contain @deprecated, and its
+// reason.
+func deprecation(directives []*directive) (bool, string) {
+	for _, d := range directives {
+		if d.name != "deprecated" {
+			continue
+		}
+		if reason, ok := d.args["reason"].(string); ok {
+			return true, reason
+		}
+		return true, defaultDeprecationReason
+	}
+	return false, ""
+}
@@ -0,0 +1,367 @@
+// Package sdl prints and parses GraphQL schemas in the GraphQL schema
+// definition language (SDL).
+//
+// Print writes a *graphql.Schema, such as one built by schemabuilder, as SDL.
+// Parse and ParseIntrospection read SDL and introspection query results back
+// into a *graphql.Schema that describes the types of the schema, but has no
+// resolvers.
+package sdl
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/samsarahq/thunder/graphql"
+)
+
+// defaultDeprecationReason is the reason of a @deprecated directive without
+// a reason argument.
+const defaultDeprecationReason = "No longer supported"
+
+// Print returns the SDL of schema. Types, fields, arguments and enum values
+// are sorted by name so the output is stable, and introspection types and
+// fields (named "__...") are omitted.
+func Print(schema *graphql.Schema) string {
+	types := CollectTypes(schema)
+
+	var blocks []string
+	if block := printSchemaDefinition(schema); block != "" {
+		blocks = append(blocks, block)
+	}
+
+	directiveNames := make([]string, 0, len(schema.Directives))
+	for name := range schema.Directives {
+		directiveNames = append(directiveNames, name)
+	}
+	sort.Strings(directiveNames)
+	for _, name := range directiveNames {
+		blocks = append(blocks, printDirectiveDefinition(schema.Directives[name]))
+	}
+
+	names := make([]string, 0, len(types))
+	for name := range types {
+		if isIntrospectionName(name) || isBuiltinScalar(name) {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		blocks = append(blocks, printType(types[name]))
+	}
+
+	return strings.Join(blocks, "\n\n") + "\n"
+}
+
+// CollectTypes returns the named types of schema by name: the types
+// reachable from its root types and from the arguments of its directives.
+func CollectTypes(schema *graphql.Schema) map[string]graphql.Type {
+	types := make(map[string]graphql.Type)
+	for _, root := range []graphql.Type{schema.Query, schema.Mutation, schema.Subscription} {
+		if root != nil {
+			collectTypes(root, types)
+		}
+	}
+	for _, directive := range schema.Directives {
+		for _, arg := range directive.Args {
+			collectTypes(arg, types)
+		}
+	}
+	return types
+}
+
+func collectTypes(typ graphql.Type, types map[string]graphql.Type) {
+	switch typ := typ.(type) {
+	case *graphql.List:
+		collectTypes(typ.Type, types)
+		return
+	case *graphql.NonNull:
+		collectTypes(typ.Type, types)
+		return
+	}
+
+	name := typeName(typ)
+	if _, ok := types[name]; ok {
+		return
+	}
+	types[name] = typ
+
+	switch typ := typ.(type) {
+	case *graphql.Object:
+		collectFields(typ.Fields, types)
+		for _, iface := range typ.Interfaces {
+			collectTypes(iface, types)
+		}
+	case *graphql.Interface:
+		collectFields(typ.Fields, types)
+		for _, obj := range typ.Types {
+			collectTypes(obj, types)
+		}
+	case *graphql.Union:
+		for _, obj := range typ.Types {
+			collectTypes(obj, types)
+		}
+	case *graphql.InputObject:
+		for _, field := range typ.InputFields {
+			collectTypes(field, types)
+		}
+	}
+}
+
+func collectFields(fields map[string]*graphql.Field, types map[string]graphql.Type) {
+	for name, field := range fields {
+		if isIntrospectionName(name) {
+			continue
+		}
+		collectTypes(field.Type, types)
+		for _, arg := range field.Args {
+			collectTypes(arg, types)
+		}
+	}
+}
+
+// typeName returns the name of a named type.
+func typeName(typ graphql.Type) string {
+	switch typ := typ.(type) {
+	case *graphql.Scalar:
+		return typ.Type
+	case *graphql.Enum:
+		return typ.Type
+	case *graphql.Object:
+		return typ.Name
+	case *graphql.Interface:
+		return typ.Name
+	case *graphql.Union:
+		return typ.Name
+	case *graphql.InputObject:
+		return typ.Name
+	default:
+		panic(fmt.Sprintf("%s is not a named type", typ))
+	}
+}
+
+func isIntrospectionName(name string) bool {
+	return strings.HasPrefix(name, "__")
+}
+
+// builtinScalars are the scalars every GraphQL schema has, which are not
+// printed.
+var builtinScalars = map[string]bool{
+	"String":  true,
+	"Int":     true,
+	"Float":   true,
+	"Boolean": true,
+	"ID":      true,
+}
+
+func isBuiltinScalar(name string) bool {
+	return builtinScalars[name]
+}
+
+// printSchemaDefinition returns the schema definition of schema, or "" if
+// its root types have the default names.
+func printSchemaDefinition(schema *graphql.Schema) string {
+	roots := []struct {
+		operation   string
+		typ         graphql.Type
+		defaultName string
+	}{
+		{"query", schema.Query, "Query"},
+		{"mutation", schema.Mutation, "Mutation"},
+		{"subscription", schema.Subscription, "Subscription"},
+	}
+
+	custom := false
+	var lines []string
+	for _, root := range roots {
+		if root.typ == nil {
+			continue
+		}
+		name := typeName(root.typ)
+		if name != root.defaultName {
+			custom = true
+		}
+		lines = append(lines, fmt.Sprintf("  %s: %s", root.operation, name))
+	}
+	if !custom {
+		return ""
+	}
+	return "schema {\n" + strings.Join(lines, "\n") + "\n}"
+}
+
+func printDirectiveDefinition(directive *graphql.DirectiveDefinition) string {
+	locations := make([]string, 0, len(directive.Locations))
+	for _, location := range directive.Locations {
+		locations = append(locations, string(location))
+	}
+	return printDescription(directive.Description, "") +
+		fmt.Sprintf("directive @%s%s on %s", directive.Name, printArgs(directive.Args), strings.Join(locations, " | "))
+}
+
+func printType(typ graphql.Type) string {
+	switch typ := typ.(type) {
+	case *graphql.Scalar:
+		header := "scalar " + typ.Type
+		if typ.SpecifiedByURL != "" {
+			header += " @" + graphql.SPECIFIED_BY + "(url: " + printString(typ.SpecifiedByURL) + ")"
+		}
+		return printDescription(typ.Description, "") + header
+
+	case *graphql.Enum:
+		values := append([]string(nil), typ.Values...)
+		sort.Strings(values)
+		lines := make([]string, 0, len(values))
+		for _, value := range values {
+			line := printDescription(typ.ValueDescriptions[value], "  ") + "  " + value
+			if reason, ok := typ.DeprecatedValues[value]; ok {
+				line += printDeprecated(reason)
+			}
+			lines = append(lines, line)
+		}
+		return "enum " + typ.Type + printBlock(lines)
+
+	case *graphql.Object:
+		var interfaces []string
+		for name := range typ.Interfaces {
+			interfaces = append(interfaces, name)
+		}
+		sort.Strings(interfaces)
+		header := "type " + typ.Name
+		if len(interfaces) > 0 {
+			header += " implements " + strings.Join(interfaces, " & ")
+		}
+		return printDescription(typ.Description, "") + header + printBlock(printFields(typ.Fields))
+
+	case *graphql.Interface:
+		return printDescription(typ.Description, "") + "interface " + typ.Name + printBlock(printFields(typ.Fields))
+
+	case *graphql.Union:
+		var members []string
+		for name := range typ.Types {
+			members = append(members, name)
+		}
+		sort.Strings(members)
+		return printDescription(typ.Description, "") + "union " + typ.Name + " = " + strings.Join(members, " | ")
+
+	case *graphql.InputObject:
+		var names []string
+		for name := range typ.InputFields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		lines := make([]string, 0, len(names))
+		for _, name := range names {
+			lines = append(lines, printDescription(typ.InputFieldDescriptions[name], "  ")+"  "+name+": "+typ.InputFields[name].String())
+		}
+		header := "input " + typ.Name
+		if typ.OneOf {
+			header += " @" + graphql.ONE_OF
+		}
+		return header + printBlock(lines)
+
+	default:
+		panic(fmt.Sprintf("cannot print type %s", typ))
+	}
+}
+
+func printFields(fields map[string]*graphql.Field) []string {
+	var names []string
+	for name := range fields {
+		if isIntrospectionName(name) {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	lines := make([]string, 0, len(names))
+	for _, name := range names {
+		field := fields[name]
+		line := printDescription(field.Description, "  ") + "  " + name + printArgs(field.Args) + ": " + field.Type.String()
+		if field.IsDeprecated {
+			line += printDeprecated(field.DeprecationReason)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func printArgs(args map[string]graphql.Type) string {
+	if len(args) == 0 {
+		return ""
+	}
+	var names []string
+	for name := range args {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	printed := make([]string, 0, len(names))
+	for _, name := range names {
+		printed = append(printed, name+": "+args[name].String())
+	}
+	return "(" + strings.Join(printed, ", ") + ")"
+}
+
+// printBlock returns lines wrapped in braces, or "" if there are none.
+func printBlock(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	return " {\n" + strings.Join(lines, "\n") + "\n}"
+}
+
+func printDeprecated(reason string) string {
+	if reason == "" || reason == defaultDeprecationReason {
+		return " @deprecated"
+	}
+	return " @deprecated(reason: " + printString(reason) + ")"
+}
+
+// printDescription returns description as a block string on its own lines
+// indented by indent, or "" if it is empty.
+func printDescription(description string, indent string) string {
+	if description == "" {
+		return ""
+	}
+	escaped := strings.Replace(description, `"""`, `\"""`, -1)
+	if !strings.Contains(escaped, "\n") {
+		return indent + `"""` + escaped + `"""` + "\n"
+	}
+	lines := strings.Split(escaped, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = indent + line
+		}
+	}
+	return indent + `"""` + "\n" + strings.Join(lines, "\n") + "\n" + indent + `"""` + "\n"
+}
+
+// printString returns s as a GraphQL string literal.
+func printString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(&b, `\u%04x`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,217 @@
+package sdl_test
+
+import (
+	"testing"
+
+	"github.com/samsarahq/thunder/graphql"
+	"github.com/samsarahq/thunder/graphql/introspection"
+	"github.com/samsarahq/thunder/graphql/schemabuilder"
+	"github.com/samsarahq/thunder/graphql/sdl"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type user struct {
+	Name     string `description:"The full name of the user."`
+	Nickname string `deprecated:"Use name instead."`
+}
+
+type status int32
+
+type filter struct {
+	Status *status
+	Limit  int64
+}
+
+type cents int64
+
+type userLookup struct {
+	schemabuilder.OneOf
+	Name     *string
+	Nickname *string
+}
+
+func makeSchema() *schemabuilder.Schema {
+	schema := schemabuilder.NewSchema()
+	object := schema.Object("User", user{})
+	object.Description = "A user of the app."
+	var s status
+	schema.Enum(s, map[string]status{"active": 1, "disabled": 2},
+		schemabuilder.EnumValueDescription("active", "The user can log in."),
+		schemabuilder.EnumValueDescription("disabled", "The user cannot log in."),
+		schemabuilder.EnumValueDeprecated("disabled", "Use inactive instead."))
+	directive := schema.Directive("auth", struct{ Role string }{}, graphql.DirectiveLocationFieldDefinition)
+	directive.Description = "Restricts a field to users with a role."
+	scalar := schema.Scalar("Cents", cents(0),
+		func(value interface{}) (interface{}, error) { return int64(value.(cents)), nil },
+		func(value interface{}) (interface{}, error) { return cents(value.(float64)), nil })
+	scalar.Description = "An amount of money in cents."
+	scalar.SpecifiedByURL = "https://example.com/cents"
+
+	query := schema.Query()
+	query.FieldFunc("users", func(args struct{ Filter *filter }) []*user { return nil },
+		schemabuilder.Description("Users matching filter."))
+	query.FieldFunc("me", func() *user { return nil }, schemabuilder.ApplyDirective("auth", struct{ Role string }{Role: "user"}))
+	query.FieldFunc("user", func(args struct{ By userLookup }) *user { return nil })
+	query.FieldFunc("balance", func() cents { return 0 })
+	schema.Mutation().FieldFunc("ping", func() string { return "pong" })
+	return schema
+}
+
+const expectedSDL = `"""Restricts a field to users with a role."""
+directive @auth(role: string!) on FIELD_DEFINITION
+
+"""An amount of money in cents."""
+scalar Cents @specifiedBy(url: "https://example.com/cents")
+
+type Mutation {
+  ping: string!
+}
+
+type Query {
+  balance: Cents!
+  me: User
+  user(by: userLookup_InputObject!): User
+  """Users matching filter."""
+  users(filter: filter_InputObject): [User]!
+}
+
+"""A user of the app."""
+type User {
+  """The full name of the user."""
+  name: string!
+  nickname: string! @deprecated(reason: "Use name instead.")
+}
+
+input filter_InputObject {
+  limit: int64!
+  status: status
+}
+
+scalar int64
+
+enum status {
+  """The user can log in."""
+  active
+  """The user cannot log in."""
+  disabled @deprecated(reason: "Use inactive instead.")
+}
+
+scalar string
+
+input userLookup_InputObject @oneOf {
+  name: string
+  nickname: string
+}
+`
+
+func TestPrint(t *testing.T) {
+	assert.Equal(t, expectedSDL, sdl.Print(makeSchema().MustBuild()))
+}
+
+func TestParseRoundTrip(t *testing.T) {
+	schema, err := sdl.Parse(expectedSDL)
+	require.NoError(t, err)
+	assert.Equal(t, expectedSDL, sdl.Print(schema))
+
+	user := schema.Query.(*graphql.Object).Fields["me"].Type.(*graphql.Object)
+	assert.Equal(t, "A user of the app.", user.Description)
+	assert.True(t, user.Fields["nickname"].IsDeprecated)
+	assert.Equal(t, "Use name instead.", user.Fields["nickname"].DeprecationReason)
+}
+
+func TestParseIntrospection(t *testing.T) {
+	data, err := introspection.ComputeSchemaJSON(*makeSchema())
+	require.NoError(t, err)
+
+	schema, err := sdl.ParseIntrospection(data)
+	require.NoError(t, err)
+	assert.Equal(t, expectedSDL, sdl.Print(schema))
+}
+
+func TestParse(t *testing.T) {
+	schema, err := sdl.Parse(`
+		# Comments and commas are ignored.
+		schema { query: Root, mutation: Root }
+
+		"""
+		  Something with a name.
+		"""
+		interface Node { id: ID! }
+
+		type Root implements Node {
+		  id: ID!
+		  search(query: String!, first: Int): [Result!]! @deprecated
+		}
+
+		type Post implements Node { id: ID! title: String }
+		union Result = | Root | Post
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, `schema {
+  query: Root
+  mutation: Root
+}
+
+"""Something with a name."""
+interface Node {
+  id: ID!
+}
+
+type Post implements Node {
+  id: ID!
+  title: String
+}
+
+union Result = Post | Root
+
+type Root implements Node {
+  id: ID!
+  search(first: Int, query: String!): [Result!]! @deprecated
+}
+`, sdl.Print(schema))
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, testCase := range []struct {
+		name   string
+		source string
+		err    string
+	}{
+		{
+			name:   "unknown type",
+			source: "type Query { user: User }",
+			err:    "1:1: bad type Query: bad field user: unknown type User",
+		},
+		{
+			name:   "duplicate type",
+			source: "type Query { a: Int }\ntype Query { b: Int }",
+			err:    "2:1: duplicate type Query",
+		},
+		{
+			name:   "input type as output",
+			source: "input Filter { a: Int }\ntype Query { f: Filter }",
+			err:    "2:1: bad type Query: bad field f: input type Filter cannot be the type of a field",
+		},
+		{
+			name:   "unterminated string",
+			source: "\"\"\"Docs\ntype Query { a: Int }",
+			err:    "2:22: unterminated block string",
+		},
+		{
+			name:   "missing brace",
+			source: "type Query { a: Int",
+			err:    "1:20: expected name, got end of input",
+		},
+		{
+			name:   "extensions",
+			source: "extend type Query { a: Int }",
+			err:    "1:1: type extensions are not supported",
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := sdl.Parse(testCase.source)
+			assert.EqualError(t, err, testCase.err)
+		})
+	}
+}
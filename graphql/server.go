@@ -4,8 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"io"
 	"log"
 	"net/http"
+	"reflect"
 	"sync"
 	"time"
 
@@ -61,46 +63,97 @@ type conn struct {
 
 	executor ExecutorRunner
 
+	persistedQueries PersistedQueryStore
+	complexityLimits ComplexityLimits
+
 	logger             GraphqlLogger
 	subscriptionLogger SubscriptionLogger
 
 	url string
 
+	// protocol is the websocket subprotocol spoken by the conn; empty for
+	// Thunder's own protocol.
+	protocol string
+	// initialized is set once a graphql-transport-ws client has sent its
+	// connection_init message.
+	initialized bool
+
 	mutateMu sync.Mutex
 
 	mu            sync.Mutex
-	subscriptions map[string]*reactive.Rerunner
+	subscriptions map[string]subscriptionRunner
 
 	alwaysSpawnGoroutineFunc AlwaysSpawnGoroutineFunc
 	minRerunIntervalFunc     RerunIntervalFunc
 	maxSubscriptions         int
 }
 
+// A subscriptionRunner runs a subscription or mutation on a conn.
+type subscriptionRunner interface {
+	Stop()
+	RerunImmediately()
+}
+
+// eventStreamRunner runs a subscription operation, which is executed once for
+// every event of its stream.
+type eventStreamRunner struct {
+	cancel context.CancelFunc
+}
+
+func (r *eventStreamRunner) Stop() {
+	r.cancel()
+}
+
+// RerunImmediately does nothing, as subscription operations only execute when
+// an event arrives.
+func (r *eventStreamRunner) RerunImmediately() {}
+
 type inEnvelope struct {
 	ID         string                 `json:"id"`
 	Type       string                 `json:"type"`
 	Message    json.RawMessage        `json:"message"`
 	Extensions map[string]interface{} `json:"extensions,omitempty"`
+
+	// Payload holds the message of graphql-transport-ws envelopes.
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// operation returns the query and operation name of a subscribe or mutate
+// envelope, which describe the errors of the request. Persisted queries sent
+// without their text have no query.
+func (e *inEnvelope) operation() (query string, operationName string) {
+	message := e.Message
+	if e.Payload != nil {
+		message = e.Payload
+	}
+	var subscribe subscribeMessage
+	if err := json.Unmarshal(message, &subscribe); err != nil {
+		return "", ""
+	}
+	return subscribe.Query, subscribe.OperationName
 }
 
 type outEnvelope struct {
 	ID       string                 `json:"id,omitempty"`
 	Type     string                 `json:"type"`
 	Message  interface{}            `json:"message,omitempty"`
+	Errors   []ResponseError        `json:"errors,omitempty"`
 	Metadata map[string]interface{} `json:"metadata,omitempty"`
 }
 
 type subscribeMessage struct {
-	Query     string                 `json:"query"`
-	Variables map[string]interface{} `json:"variables"`
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables"`
+	OperationName string                 `json:"operationName,omitempty"`
 }
 
 type mutateMessage struct {
-	Query     string                 `json:"query"`
-	Variables map[string]interface{} `json:"variables"`
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables"`
+	OperationName string                 `json:"operationName,omitempty"`
 }
 
-func (c *conn) writeOrClose(out outEnvelope) {
+func (c *conn) writeOrClose(out interface{}) {
 	c.writeMu.Lock()
 	defer c.writeMu.Unlock()
 
@@ -112,6 +165,109 @@ func (c *conn) writeOrClose(out outEnvelope) {
 	}
 }
 
+// writeUpdate sends a new result of the live query id, if it is the initial
+// result or if its data or errors changed.
+func (c *conn) writeUpdate(id string, previous, current interface{}, initial bool, previousErrs, errs []ResponseError, metadata map[string]interface{}) {
+	d := diff.Diff(previous, current)
+	if d == nil && !initial && sameResponseErrors(previousErrs, errs) {
+		return
+	}
+
+	if c.protocol == GraphQLTransportWSProtocol {
+		c.writeOrClose(transportWSNext(id, current, errs, metadata))
+		return
+	}
+
+	if d == nil {
+		// When a client first subscribes, they expect a response with the new diff (even if the diff is unchanged).
+		d = struct{}{} // This is an empty diff for any message, rather than nil which means the new message is empty.
+	}
+	c.writeOrClose(outEnvelope{
+		ID:       id,
+		Type:     "update",
+		Message:  d,
+		Errors:   errs,
+		Metadata: metadata,
+	})
+}
+
+// sameResponseErrors returns if a and b hold the same errors.
+func sameResponseErrors(a, b []ResponseError) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return len(a) == len(b)
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// writeEvent sends the result of an event of the subscription operation id.
+func (c *conn) writeEvent(id string, current interface{}, errs []ResponseError, metadata map[string]interface{}) {
+	if c.protocol == GraphQLTransportWSProtocol {
+		c.writeOrClose(transportWSNext(id, current, errs, metadata))
+		return
+	}
+
+	// Events are independent of each other, so every update replaces the
+	// previous result instead of diffing against it.
+	c.writeOrClose(outEnvelope{
+		ID:       id,
+		Type:     "update",
+		Message:  diff.Diff(nil, current),
+		Errors:   errs,
+		Metadata: metadata,
+	})
+}
+
+// writeResult sends the result of the mutation id.
+func (c *conn) writeResult(id string, current interface{}, errs []ResponseError, metadata map[string]interface{}) {
+	if c.protocol == GraphQLTransportWSProtocol {
+		c.writeOrClose(transportWSNext(id, current, errs, metadata))
+		c.writeOrClose(transportWSMessage{ID: id, Type: "complete"})
+		return
+	}
+
+	c.writeOrClose(outEnvelope{
+		ID:       id,
+		Type:     "result",
+		Message:  diff.Diff(nil, current),
+		Errors:   errs,
+		Metadata: metadata,
+	})
+}
+
+// writeError sends the errors that ended the operation id, as built by
+// NewResponseErrors.
+func (c *conn) writeError(id string, errs []ResponseError, metadata map[string]interface{}) {
+	if c.protocol == GraphQLTransportWSProtocol {
+		c.writeOrClose(transportWSMessage{
+			ID:      id,
+			Type:    "error",
+			Payload: errs,
+		})
+		return
+	}
+
+	c.writeOrClose(outEnvelope{
+		ID:       id,
+		Type:     "error",
+		Message:  errs[0].Message,
+		Errors:   errs,
+		Metadata: metadata,
+	})
+}
+
+// writeComplete tells the client that the operation id has finished.
+func (c *conn) writeComplete(id string) {
+	if c.protocol == GraphQLTransportWSProtocol {
+		c.writeOrClose(transportWSMessage{ID: id, Type: "complete"})
+		return
+	}
+
+	c.writeOrClose(outEnvelope{
+		ID:   id,
+		Type: "complete",
+	})
+}
+
 func mustMarshalJson(v interface{}) string {
 	bytes, err := json.Marshal(v)
 	if err != nil {
@@ -126,6 +282,11 @@ func (c *conn) handleSubscribe(in *inEnvelope) error {
 	if err := json.Unmarshal(in.Message, &subscribe); err != nil {
 		return oops.Wrapf(err, "failed to parse subscribe message: %s", in.Message)
 	}
+	queryText, err := resolvePersistedQuery(c.ctx, c.persistedQueries, subscribe.Query, in.Extensions)
+	if err != nil {
+		return err
+	}
+	subscribe.Query = queryText
 
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -140,7 +301,7 @@ func (c *conn) handleSubscribe(in *inEnvelope) error {
 
 	tags := map[string]string{"url": c.url, "query": subscribe.Query, "queryVariables": mustMarshalJson(subscribe.Variables), "id": id}
 
-	query, err := Parse(subscribe.Query, subscribe.Variables)
+	query, err := ParseOperation(subscribe.Query, subscribe.Variables, subscribe.OperationName)
 	if query != nil {
 		tags["queryType"] = query.Kind
 		tags["queryName"] = query.Name
@@ -149,12 +310,25 @@ func (c *conn) handleSubscribe(in *inEnvelope) error {
 		c.logger.Error(c.ctx, err, tags)
 		return err
 	}
-	if err := PrepareQuery(context.Background(), c.schema.Query, query.SelectionSet); err != nil {
+	if query.Kind == "subscription" {
+		return c.subscribeEventStream(in, &subscribe, query, tags)
+	}
+	if query.Kind == "mutation" && c.protocol == GraphQLTransportWSProtocol {
+		// graphql-transport-ws sends all operations as subscribe messages.
+		mutate := mutateMessage(subscribe)
+		return c.runMutation(in, &mutate, query, tags)
+	}
+	if err := PrepareQuery(context.Background(), c.schema.Query, query.SelectionSet, c.schema.PrepareOptions()...); err != nil {
+		c.logger.Error(c.ctx, err, tags)
+		return err
+	}
+	if err := CheckComplexity(c.schema.Query, query.SelectionSet, c.complexityLimits); err != nil {
 		c.logger.Error(c.ctx, err, tags)
 		return err
 	}
 
 	var previous interface{}
+	var previousErrs []ResponseError
 
 	e := c.executor
 
@@ -183,6 +357,7 @@ func (c *conn) handleSubscribe(in *inEnvelope) error {
 			Previous:             previous,
 			IsInitialComputation: initial,
 			Query:                subscribe.Query,
+			OperationName:        subscribe.OperationName,
 			Variables:            subscribe.Variables,
 			Extensions:           in.Extensions,
 		}
@@ -192,6 +367,23 @@ func (c *conn) handleSubscribe(in *inEnvelope) error {
 
 		c.logger.FinishExecution(ctx, tags, time.Since(start))
 
+		if partial, ok := err.(*PartialResultError); ok {
+			errs := NewResponseErrors(partial, subscribe.Query, subscribe.OperationName)
+			if initial || !sameResponseErrors(previousErrs, errs) {
+				c.logger.Error(ctx, partial, tags)
+			}
+			c.writeUpdate(id, computationInput.Previous, current, initial, previousErrs, errs, output.Metadata)
+			previous, previousErrs = current, errs
+			initial = false
+
+			// The partial result stands until one of its dependencies
+			// changes, like a complete result. The cache holds the failed
+			// fields without their errors, so purge it to resolve them
+			// again on the next rerun.
+			reactive.PurgeCache(ctx)
+			return nil, nil
+		}
+
 		if err != nil {
 			if ErrorCause(err) == context.Canceled {
 				go c.closeSubscription(id)
@@ -214,12 +406,7 @@ func (c *conn) handleSubscribe(in *inEnvelope) error {
 				return nil, reactive.RetrySentinelError
 			}
 
-			c.writeOrClose(outEnvelope{
-				ID:       id,
-				Type:     "error",
-				Message:  SanitizeError(err),
-				Metadata: output.Metadata,
-			})
+			c.writeError(id, NewResponseErrors(err, subscribe.Query, subscribe.OperationName), output.Metadata)
 			go c.closeSubscription(id)
 
 			if _, ok := err.(SanitizedError); !ok {
@@ -228,25 +415,8 @@ func (c *conn) handleSubscribe(in *inEnvelope) error {
 			return nil, err
 		}
 
-		d := diff.Diff(computationInput.Previous, current)
-		previous = current
-
-		if d != nil {
-			c.writeOrClose(outEnvelope{
-				ID:       id,
-				Type:     "update",
-				Message:  d,
-				Metadata: output.Metadata,
-			})
-		} else if initial {
-			// When a client first subscribes, they expect a response with the new diff (even if the diff is unchanged).
-			c.writeOrClose(outEnvelope{
-				ID:       id,
-				Type:     "update",
-				Message:  struct{}{}, // This is an empty diff for any message, rather than nil which means the new message is empty.
-				Metadata: output.Metadata,
-			})
-		}
+		c.writeUpdate(id, computationInput.Previous, current, initial, previousErrs, nil, output.Metadata)
+		previous, previousErrs = current, nil
 
 		initial = false
 		return nil, nil
@@ -255,6 +425,130 @@ func (c *conn) handleSubscribe(in *inEnvelope) error {
 	return nil
 }
 
+// subscribeEventStream starts a subscription operation, sending an "update"
+// with the result of every event of its stream and a "complete" once the
+// stream ends. c.mu must be held.
+func (c *conn) subscribeEventStream(in *inEnvelope, subscribe *subscribeMessage, query *Query, tags map[string]string) error {
+	id := in.ID
+
+	if c.schema.Subscription == nil {
+		err := NewClientError("schema does not support subscriptions")
+		c.logger.Error(c.ctx, err, tags)
+		return err
+	}
+	if err := PrepareQuery(c.ctx, c.schema.Subscription, query.SelectionSet, c.schema.PrepareOptions()...); err != nil {
+		c.logger.Error(c.ctx, err, tags)
+		return err
+	}
+	if err := CheckComplexity(c.schema.Subscription, query.SelectionSet, c.complexityLimits); err != nil {
+		c.logger.Error(c.ctx, err, tags)
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(c.ctx)
+	ctx = c.makeCtx(ctx)
+	stream, err := Subscribe(ctx, c.schema.Subscription, query)
+	if err != nil {
+		cancel()
+		c.logger.Error(c.ctx, err, tags)
+		return err
+	}
+
+	c.subscriptionLogger.Subscribe(c.ctx, id, tags)
+	c.subscriptions[id] = &eventStreamRunner{cancel: cancel}
+
+	go func() {
+		initial := true
+		for {
+			event, err := stream.Next(ctx)
+			if ctx.Err() != nil {
+				// The subscription has already been closed.
+				return
+			}
+			if err == io.EOF {
+				c.writeComplete(id)
+				c.closeSubscription(id)
+				return
+			}
+			if err != nil {
+				c.writeError(id, NewResponseErrors(err, subscribe.Query, subscribe.OperationName), nil)
+				if _, ok := err.(SanitizedError); !ok {
+					c.logger.Error(ctx, err, tags)
+				}
+				c.closeSubscription(id)
+				return
+			}
+
+			if !c.executeEvent(ctx, in, subscribe, query, tags, event, initial) {
+				if ctx.Err() == nil {
+					c.closeSubscription(id)
+				}
+				return
+			}
+			initial = false
+		}
+	}()
+
+	return nil
+}
+
+// executeEvent executes a subscription operation for one event of its stream
+// and sends the result as an "update". It returns false if the execution
+// failed, in which case an "error" has been sent instead.
+func (c *conn) executeEvent(ctx context.Context, in *inEnvelope, subscribe *subscribeMessage, query *Query, tags map[string]string, event interface{}, initial bool) bool {
+	ctx = batch.WithBatching(ctx)
+
+	start := time.Now()
+	c.logger.StartExecution(ctx, tags, initial)
+
+	e := c.executor
+	var middlewares []MiddlewareFunc
+	middlewares = append(middlewares, c.middlewares...)
+	middlewares = append(middlewares, func(input *ComputationInput, next MiddlewareNextFunc) *ComputationOutput {
+		output := next(input)
+		output.Current, output.Error = e.Execute(input.Ctx, c.schema.Subscription, event, input.ParsedQuery)
+		return output
+	})
+
+	computationInput := &ComputationInput{
+		Ctx:                  ctx,
+		Id:                   in.ID,
+		ParsedQuery:          query,
+		Previous:             nil,
+		IsInitialComputation: initial,
+		Query:                subscribe.Query,
+		OperationName:        subscribe.OperationName,
+		Variables:            subscribe.Variables,
+		Extensions:           in.Extensions,
+	}
+
+	output := RunMiddlewares(middlewares, computationInput)
+	current, err := output.Current, output.Error
+
+	c.logger.FinishExecution(ctx, tags, time.Since(start))
+
+	if partial, ok := err.(*PartialResultError); ok {
+		c.writeEvent(in.ID, current, NewResponseErrors(partial, subscribe.Query, subscribe.OperationName), output.Metadata)
+		c.logger.Error(ctx, partial, tags)
+		return true
+	}
+
+	if err != nil {
+		if ErrorCause(err) == context.Canceled {
+			return false
+		}
+
+		c.writeError(in.ID, NewResponseErrors(err, subscribe.Query, subscribe.OperationName), output.Metadata)
+		if _, ok := err.(SanitizedError); !ok {
+			c.logger.Error(ctx, err, tags)
+		}
+		return false
+	}
+
+	c.writeEvent(in.ID, current, nil, output.Metadata)
+	return true
+}
+
 func (c *conn) handleMutate(in *inEnvelope) error {
 	// TODO: deduplicate code
 	id := in.ID
@@ -262,13 +556,18 @@ func (c *conn) handleMutate(in *inEnvelope) error {
 	if err := json.Unmarshal(in.Message, &mutate); err != nil {
 		return oops.Wrapf(err, "failed to parse mutate message: %s", in.Message)
 	}
+	queryText, err := resolvePersistedQuery(c.ctx, c.persistedQueries, mutate.Query, in.Extensions)
+	if err != nil {
+		return err
+	}
+	mutate.Query = queryText
 
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	tags := map[string]string{"url": c.url, "query": mutate.Query, "queryVariables": mustMarshalJson(mutate.Variables), "id": id}
 
-	query, err := Parse(mutate.Query, mutate.Variables)
+	query, err := ParseOperation(mutate.Query, mutate.Variables, mutate.OperationName)
 	if query != nil {
 		tags["queryType"] = query.Kind
 		tags["queryName"] = query.Name
@@ -277,7 +576,18 @@ func (c *conn) handleMutate(in *inEnvelope) error {
 		c.logger.Error(c.ctx, err, tags)
 		return err
 	}
-	if err := PrepareQuery(c.ctx, c.mutationSchema.Mutation, query.SelectionSet); err != nil {
+	return c.runMutation(in, &mutate, query, tags)
+}
+
+// runMutation executes a mutation, sending its result and rerunning all
+// subscriptions once it has finished. c.mu must be held.
+func (c *conn) runMutation(in *inEnvelope, mutate *mutateMessage, query *Query, tags map[string]string) error {
+	id := in.ID
+	if err := PrepareQuery(c.ctx, c.mutationSchema.Mutation, query.SelectionSet, c.mutationSchema.PrepareOptions()...); err != nil {
+		c.logger.Error(c.ctx, err, tags)
+		return err
+	}
+	if err := CheckComplexity(c.mutationSchema.Mutation, query.SelectionSet, c.complexityLimits); err != nil {
 		c.logger.Error(c.ctx, err, tags)
 		return err
 	}
@@ -310,6 +620,7 @@ func (c *conn) handleMutate(in *inEnvelope) error {
 			Previous:             nil,
 			IsInitialComputation: initial,
 			Query:                mutate.Query,
+			OperationName:        mutate.OperationName,
 			Variables:            mutate.Variables,
 			Extensions:           in.Extensions,
 		}
@@ -319,13 +630,15 @@ func (c *conn) handleMutate(in *inEnvelope) error {
 
 		c.logger.FinishExecution(ctx, tags, time.Since(start))
 
+		var errs []ResponseError
+		if partial, ok := err.(*PartialResultError); ok {
+			errs = NewResponseErrors(partial, mutate.Query, mutate.OperationName)
+			c.logger.Error(ctx, partial, tags)
+			err = nil
+		}
+
 		if err != nil {
-			c.writeOrClose(outEnvelope{
-				ID:       id,
-				Type:     "error",
-				Message:  SanitizeError(err),
-				Metadata: output.Metadata,
-			})
+			c.writeError(id, NewResponseErrors(err, mutate.Query, mutate.OperationName), output.Metadata)
 
 			go c.closeSubscription(id)
 
@@ -339,12 +652,7 @@ func (c *conn) handleMutate(in *inEnvelope) error {
 			return nil, err
 		}
 
-		c.writeOrClose(outEnvelope{
-			ID:       id,
-			Type:     "result",
-			Message:  diff.Diff(nil, current),
-			Metadata: output.Metadata,
-		})
+		c.writeResult(id, current, errs, output.Metadata)
 
 		go c.rerunSubscriptionsImmediately()
 
@@ -387,6 +695,10 @@ func (c *conn) closeSubscriptions() {
 }
 
 func (c *conn) handle(e *inEnvelope) error {
+	if c.protocol == GraphQLTransportWSProtocol {
+		return c.handleTransportWS(e)
+	}
+
 	switch e.Type {
 	case "subscribe":
 		return c.handleSubscribe(e)
@@ -438,6 +750,9 @@ func Handler(schema *Schema) http.Handler {
 		CheckOrigin: func(r *http.Request) bool {
 			return true
 		},
+		// Clients asking for graphql-transport-ws get it; all others speak
+		// Thunder's own protocol.
+		Subprotocols: []string{GraphQLTransportWSProtocol},
 	}
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -452,7 +767,8 @@ func Handler(schema *Schema) http.Handler {
 			return ctx
 		}
 
-		ServeJSONSocket(r.Context(), socket, schema, makeCtx, &simpleLogger{})
+		conn := CreateConnection(r.Context(), socket, schema, WithMakeCtx(makeCtx), WithExecutionLogger(&simpleLogger{}), WithProtocol(socket.Subprotocol()))
+		conn.ServeJSONSocket()
 	})
 }
 
@@ -498,7 +814,7 @@ func CreateConnection(ctx context.Context, socket JSONSocket, schema *Schema, op
 		schema:             schema,
 		mutationSchema:     schema,
 		executor:           NewExecutor(NewImmediateGoroutineScheduler()),
-		subscriptions:      make(map[string]*reactive.Rerunner),
+		subscriptions:      make(map[string]subscriptionRunner),
 		subscriptionLogger: &nopSubscriptionLogger{},
 		logger:             &nopGraphqlLogger{},
 		makeCtx: func(ctx context.Context) context.Context {
@@ -557,6 +873,22 @@ func WithMakeCtx(makeCtx MakeCtxFunc) ConnectionOption {
 	}
 }
 
+// WithPersistedQueryStore enables automatic persisted queries for subscribe
+// and mutate messages, storing them in store.
+func WithPersistedQueryStore(store PersistedQueryStore) ConnectionOption {
+	return func(c *conn) {
+		c.persistedQueries = store
+	}
+}
+
+// WithComplexityLimits rejects subscribe and mutate messages whose queries
+// exceed limits.
+func WithComplexityLimits(limits ComplexityLimits) ConnectionOption {
+	return func(c *conn) {
+		c.complexityLimits = limits
+	}
+}
+
 func WithSubscriptionLogger(logger SubscriptionLogger) ConnectionOption {
 	return func(c *conn) {
 		c.subscriptionLogger = logger
@@ -573,6 +905,11 @@ func WithMinRerunIntervalFunc(fn RerunIntervalFunc) ConnectionOption {
 func (c *conn) ServeJSONSocket() {
 	defer c.closeSubscriptions()
 
+	if c.protocol == GraphQLTransportWSProtocol {
+		timer := time.AfterFunc(ConnectionInitWaitTimeout, c.closeInitTimeout)
+		defer timer.Stop()
+	}
+
 	for {
 		var envelope inEnvelope
 		if err := c.socket.ReadJSON(&envelope); err != nil {
@@ -584,12 +921,8 @@ func (c *conn) ServeJSONSocket() {
 
 		if err := c.handle(&envelope); err != nil {
 			log.Println("c.handle:", err)
-			c.writeOrClose(outEnvelope{
-				ID:       envelope.ID,
-				Type:     "error",
-				Message:  SanitizeError(err),
-				Metadata: nil,
-			})
+			query, operationName := envelope.operation()
+			c.writeError(envelope.ID, NewResponseErrors(err, query, operationName), nil)
 		}
 	}
 }
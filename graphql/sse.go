@@ -0,0 +1,245 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// sseKeepAliveInterval is the interval between comments sent on idle event
+// streams, so that proxies do not time them out.
+var sseKeepAliveInterval = 30 * time.Second
+
+// sseOperationID is the id of the single operation run by an SSE request.
+const sseOperationID = "0"
+
+// SSEHandler returns an http.Handler that serves Thunder's live queries over
+// Server-Sent Events, for clients that cannot open websockets.
+//
+// A GET request with query, variables, operationName and extensions URL
+// parameters subscribes to a live query, or to a subscription operation. The
+// response is an event stream of the messages a websocket client would
+// receive: "update" events with the initial result and the diffs of every
+// change, and a final "error" or "complete" event. Every event's data is the
+// JSON message, so clients can merge updates as they do over websockets.
+//
+// A POST request with a JSON body runs a mutation, and responds with its
+// "result" or "error" message.
+//
+// Every request runs on its own connection created with opts, as by
+// CreateConnection.
+func SSEHandler(schema *Schema, opts ...ConnectionOption) http.Handler {
+	return &sseHandler{schema: schema, opts: opts}
+}
+
+type sseHandler struct {
+	schema *Schema
+	opts   []ConnectionOption
+}
+
+func (h *sseHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		h.serveSubscribe(w, r)
+	case "POST":
+		h.serveMutate(w, r)
+	default:
+		http.Error(w, "request must be a GET or POST", http.StatusMethodNotAllowed)
+	}
+}
+
+// serveSubscribe streams the updates of a live query until it ends or the
+// client goes away.
+func (h *sseHandler) serveSubscribe(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	// Ask nginx-style proxies not to buffer the stream.
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	socket := newSSESocket(w, flusher, true)
+	defer socket.Close()
+
+	var params httpPostBody
+	if err := readHTTPGetParams(r, &params); err != nil {
+		socket.writeError(err)
+		return
+	}
+
+	c := h.run(r.Context(), socket, "subscribe", &params)
+	defer c.closeSubscriptions()
+
+	ticker := time.NewTicker(sseKeepAliveInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-socket.done:
+			return
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+			socket.keepAlive()
+		}
+	}
+}
+
+// serveMutate runs a mutation and responds with its result.
+func (h *sseHandler) serveMutate(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	socket := newSSESocket(w, nil, false)
+	defer socket.Close()
+
+	var params httpPostBody
+	if r.Body == nil {
+		socket.writeError(NewClientError("request must include a query"))
+		return
+	}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		socket.writeError(NewClientError("request body must be JSON: %s", err))
+		return
+	}
+
+	c := h.run(r.Context(), socket, "mutate", &params)
+	defer c.closeSubscriptions()
+
+	select {
+	case <-socket.done:
+	case <-r.Context().Done():
+	}
+}
+
+// run creates a connection writing to socket, and starts the operation of
+// params on it as a message of messageType.
+func (h *sseHandler) run(ctx context.Context, socket *sseSocket, messageType string, params *httpPostBody) *conn {
+	c := CreateConnection(ctx, socket, h.schema, h.opts...)
+
+	message, err := json.Marshal(subscribeMessage{
+		Query:         params.Query,
+		Variables:     params.Variables,
+		OperationName: params.OperationName,
+	})
+	if err != nil {
+		c.writeError(sseOperationID, NewResponseErrors(err, params.Query, params.OperationName), nil)
+		return c
+	}
+	in := &inEnvelope{
+		ID:         sseOperationID,
+		Type:       messageType,
+		Message:    message,
+		Extensions: params.Extensions,
+	}
+	if err := c.handle(in); err != nil {
+		c.writeError(in.ID, NewResponseErrors(err, params.Query, params.OperationName), nil)
+	}
+	return c
+}
+
+// sseSocket is a write-only JSONSocket that writes the messages of a conn to
+// an HTTP response, either as events of an event stream or as a single JSON
+// response.
+type sseSocket struct {
+	mu      sync.Mutex
+	w       io.Writer
+	flusher http.Flusher
+	stream  bool
+	closed  bool
+	// done is closed once the operation has ended, or the response has
+	// failed.
+	done chan struct{}
+}
+
+func newSSESocket(w io.Writer, flusher http.Flusher, stream bool) *sseSocket {
+	return &sseSocket{
+		w:       w,
+		flusher: flusher,
+		stream:  stream,
+		done:    make(chan struct{}),
+	}
+}
+
+func (s *sseSocket) ReadJSON(value interface{}) error {
+	return errors.New("cannot read from an SSE socket")
+}
+
+// WriteJSON writes an outEnvelope. Messages written after the operation has
+// ended or the client has gone away are dropped.
+func (s *sseSocket) WriteJSON(value interface{}) error {
+	envelope, ok := value.(outEnvelope)
+	if !ok {
+		return fmt.Errorf("unexpected message %T", value)
+	}
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+
+	if s.stream {
+		_, err = fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", envelope.Type, data)
+		s.flusher.Flush()
+	} else {
+		_, err = s.w.Write(data)
+	}
+
+	// Every message but "update" ends the operation.
+	if err != nil || !s.stream || envelope.Type != "update" {
+		s.closeLocked()
+	}
+	return nil
+}
+
+// writeError ends the operation with an error about the request.
+func (s *sseSocket) writeError(err error) {
+	s.WriteJSON(outEnvelope{
+		ID:      sseOperationID,
+		Type:    "error",
+		Message: SanitizeError(err),
+		Errors:  NewResponseErrors(err, "", ""),
+	})
+}
+
+// keepAlive writes a comment to the event stream.
+func (s *sseSocket) keepAlive() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	if _, err := io.WriteString(s.w, ": keep-alive\n\n"); err != nil {
+		s.closeLocked()
+		return
+	}
+	s.flusher.Flush()
+}
+
+func (s *sseSocket) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closeLocked()
+	return nil
+}
+
+func (s *sseSocket) closeLocked() {
+	if !s.closed {
+		s.closed = true
+		close(s.done)
+	}
+}
@@ -0,0 +1,176 @@
+package graphql_test
+
+import (
+	"bufio"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+
+	"github.com/samsarahq/thunder/graphql"
+	"github.com/samsarahq/thunder/graphql/schemabuilder"
+	"github.com/samsarahq/thunder/internal"
+	"github.com/samsarahq/thunder/reactive"
+)
+
+func testSSESchema() *graphql.Schema {
+	var mu sync.Mutex
+	var value int64
+	resource := reactive.NewResource()
+
+	schema := schemabuilder.NewSchema()
+	schema.Query().FieldFunc("counter", func(ctx context.Context) int64 {
+		reactive.AddDependency(ctx, resource, nil)
+		mu.Lock()
+		defer mu.Unlock()
+		return value
+	})
+	schema.Mutation().FieldFunc("increment", func() int64 {
+		mu.Lock()
+		defer mu.Unlock()
+		value++
+		resource.Strobe()
+		return value
+	})
+	return schema.MustBuild()
+}
+
+// readEvent reads the next event of an event stream, skipping comments.
+func readEvent(t *testing.T, r *bufio.Reader) (string, string) {
+	var event, data string
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("reading event: %s", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "":
+			if event != "" {
+				return event, data
+			}
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func TestSSEHandler(t *testing.T) {
+	server := httptest.NewServer(graphql.SSEHandler(testSSESchema(), graphql.WithMinRerunInterval(0)))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "?" + url.Values{"query": {"{ counter }"}}.Encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if contentType := resp.Header.Get("Content-Type"); contentType != "text/event-stream" {
+		t.Errorf("expected an event stream, received %q", contentType)
+	}
+	events := bufio.NewReader(resp.Body)
+
+	for _, step := range []struct {
+		mutate   bool
+		event    string
+		expected string
+	}{
+		{event: "update", expected: `{"id": "0", "type": "update", "message": [{"counter": 0}]}`},
+		{mutate: true, event: "update", expected: `{"id": "0", "type": "update", "message": {"counter": 1}}`},
+		{mutate: true, event: "update", expected: `{"id": "0", "type": "update", "message": {"counter": 2}}`},
+	} {
+		if step.mutate {
+			mutateResp, err := http.Post(server.URL, "application/json", strings.NewReader(`{"query": "mutation { increment }"}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, err := ioutil.ReadAll(mutateResp.Body)
+			mutateResp.Body.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(body), `"type":"result"`) {
+				t.Errorf("expected a result, received %s", body)
+			}
+		}
+
+		event, data := readEvent(t, events)
+		if event != step.event {
+			t.Errorf("expected %q event, received %q", step.event, event)
+		}
+		if d := pretty.Compare(internal.ParseJSON(data), internal.ParseJSON(step.expected)); d != "" {
+			t.Errorf("unexpected event data: %s", d)
+		}
+	}
+}
+
+func TestSSEHandlerMutate(t *testing.T) {
+	server := httptest.NewServer(graphql.SSEHandler(testSSESchema()))
+	defer server.Close()
+
+	for _, testCase := range []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{
+			name:     "mutation",
+			body:     `{"query": "mutation { increment }"}`,
+			expected: `{"id": "0", "type": "result", "message": [{"increment": 1}]}`,
+		},
+		{
+			name: "bad body",
+			body: `{`,
+			expected: `{"id": "0", "type": "error", "message": "request body must be JSON: unexpected EOF",
+				"errors": [{"message": "request body must be JSON: unexpected EOF"}]}`,
+		},
+		{
+			name: "unknown field",
+			body: `{"query": "mutation { decrement }"}`,
+			expected: `{"id": "0", "type": "error", "message": "unknown field \"decrement\"",
+				"errors": [{"message": "unknown field \"decrement\""}]}`,
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			resp, err := http.Post(server.URL, "application/json", strings.NewReader(testCase.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			body, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d := pretty.Compare(internal.ParseJSON(string(body)), internal.ParseJSON(testCase.expected)); d != "" {
+				t.Errorf("unexpected response: %s", d)
+			}
+		})
+	}
+}
+
+func TestSSEHandlerError(t *testing.T) {
+	server := httptest.NewServer(graphql.SSEHandler(testSSESchema()))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "?" + url.Values{"query": {"{ countr }"}}.Encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	// The stream ends after the error.
+	event, data := readEvent(t, bufio.NewReader(resp.Body))
+	if event != "error" || !strings.Contains(data, `unknown field \"countr\"`) {
+		t.Errorf("expected an unknown field error, received %s: %s", event, data)
+	}
+	if rest, err := ioutil.ReadAll(resp.Body); err != nil || len(rest) != 0 {
+		t.Errorf("expected the stream to end, received %q, %v", rest, err)
+	}
+}
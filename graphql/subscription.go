@@ -0,0 +1,41 @@
+package graphql
+
+import (
+	"context"
+)
+
+// An EventStream is the stream of events of a subscription operation.
+type EventStream interface {
+	// Next blocks until the next event is available. It returns io.EOF once
+	// the stream has ended, and ctx.Err() if ctx is canceled first.
+	Next(ctx context.Context) (interface{}, error)
+}
+
+// Subscribe starts the event stream for a subscription query that has been
+// prepared against the subscription root typ.
+//
+// A subscription must select exactly one field of the subscription root.
+// Every event of the returned stream is executed by running the query against
+// typ with the event as the source.
+func Subscribe(ctx context.Context, typ Type, query *Query) (EventStream, error) {
+	obj, ok := typ.(*Object)
+	if !ok {
+		return nil, NewClientError("schema does not support subscriptions")
+	}
+
+	selections, err := Flatten(query.SelectionSet)
+	if err != nil {
+		return nil, err
+	}
+	if len(selections) != 1 {
+		return nil, NewClientError("subscription must select exactly one field")
+	}
+
+	selection := selections[0]
+	field, ok := obj.Fields[selection.Name]
+	if !ok || field.Subscribe == nil {
+		return nil, NewClientError(`field "%s" is not a subscription`, selection.Name)
+	}
+
+	return field.Subscribe(ctx, selection.Args, selection.SelectionSet)
+}
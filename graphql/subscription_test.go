@@ -0,0 +1,355 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/samsarahq/thunder/graphql"
+	"github.com/samsarahq/thunder/graphql/schemabuilder"
+	"github.com/samsarahq/thunder/internal"
+	"github.com/samsarahq/thunder/internal/testgraphql"
+	"github.com/samsarahq/thunder/reactive"
+)
+
+type Alert struct {
+	Message string
+}
+
+type alertSource struct {
+	alerts []*Alert
+}
+
+func (s *alertSource) Next(ctx context.Context) (*Alert, error) {
+	if len(s.alerts) == 0 {
+		return nil, io.EOF
+	}
+	alert := s.alerts[0]
+	s.alerts = s.alerts[1:]
+	return alert, nil
+}
+
+func makeSubscriptionSchema() *graphql.Schema {
+	schema := schemabuilder.NewSchema()
+	schema.Object("Alert", Alert{})
+	schema.Query().FieldFunc("version", func() string { return "1" })
+
+	subscription := schema.Subscription()
+	subscription.FieldFunc("alerts", func(ctx context.Context, args struct{ Prefix string }) <-chan *Alert {
+		ch := make(chan *Alert)
+		go func() {
+			defer close(ch)
+			for _, message := range []string{"a", "b"} {
+				select {
+				case ch <- &Alert{Message: args.Prefix + message}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+		return ch
+	})
+	subscription.FieldFunc("sourced", func() *alertSource {
+		return &alertSource{alerts: []*Alert{{Message: "c"}}}
+	})
+	subscription.FieldFunc("failing", func() (<-chan *Alert, error) {
+		return nil, errors.New("failing")
+	})
+	subscription.FieldFunc("forbidden", func() (<-chan *Alert, error) {
+		return nil, graphql.WithErrorCode(graphql.NewClientError("forbidden"), "FORBIDDEN")
+	})
+	return schema.MustBuild()
+}
+
+func TestSubscribe(t *testing.T) {
+	builtSchema := makeSubscriptionSchema()
+	ctx := context.Background()
+
+	for _, tc := range []struct {
+		query    string
+		expected []string
+	}{
+		{`subscription { alerts(prefix: "x") { message } }`, []string{`{"alerts": {"message": "xa"}}`, `{"alerts": {"message": "xb"}}`}},
+		{`subscription { renamed: sourced { message } }`, []string{`{"renamed": {"message": "c"}}`}},
+	} {
+		q := graphql.MustParse(tc.query, nil)
+		if err := graphql.PrepareQuery(ctx, builtSchema.Subscription, q.SelectionSet); err != nil {
+			t.Fatal(err)
+		}
+
+		stream, err := graphql.Subscribe(ctx, builtSchema.Subscription, q)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		e := testgraphql.NewExecutorWrapper(t)
+		for _, expected := range tc.expected {
+			event, err := stream.Next(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := e.Execute(ctx, builtSchema.Subscription, event, q)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d := pretty.Compare(internal.AsJSON(result), internal.ParseJSON(expected)); d != "" {
+				t.Errorf("%s: expected did not match result: %s", tc.query, d)
+			}
+		}
+
+		if _, err := stream.Next(ctx); err != io.EOF {
+			t.Errorf("%s: expected io.EOF, received %v", tc.query, err)
+		}
+	}
+}
+
+func TestSubscribeErrors(t *testing.T) {
+	builtSchema := makeSubscriptionSchema()
+	ctx := context.Background()
+
+	for _, tc := range []struct {
+		query string
+		err   string
+	}{
+		{`subscription { alerts(prefix: "") { message } sourced { message } }`, "subscription must select exactly one field"},
+		{`subscription { failing { message } }`, "failing"},
+	} {
+		q := graphql.MustParse(tc.query, nil)
+		if err := graphql.PrepareQuery(ctx, builtSchema.Subscription, q.SelectionSet); err != nil {
+			t.Fatal(err)
+		}
+		_, err := graphql.Subscribe(ctx, builtSchema.Subscription, q)
+		if err == nil || err.Error() != tc.err {
+			t.Errorf("%s: expected error %q, received %v", tc.query, tc.err, err)
+		}
+	}
+
+	q := graphql.MustParse(`subscription { alerts { message } }`, nil)
+	if _, err := graphql.Subscribe(ctx, schemabuilder.NewSchema().MustBuild().Subscription, q); err == nil || err.Error() != "schema does not support subscriptions" {
+		t.Errorf("expected unsupported subscriptions error, received %v", err)
+	}
+}
+
+func TestSubscriptionBadReturnType(t *testing.T) {
+	schema := schemabuilder.NewSchema()
+	schema.Subscription().FieldFunc("alerts", func() *Alert { return nil })
+
+	_, err := schema.Build()
+	if err == nil || !strings.Contains(err.Error(), "should return a channel or event source") {
+		t.Errorf("expected bad return type error, received %v", err)
+	}
+}
+
+// jsonSocket is an in-memory graphql.JSONSocket.
+type jsonSocket struct {
+	in  chan string
+	out chan string
+}
+
+func (s *jsonSocket) ReadJSON(value interface{}) error {
+	message, ok := <-s.in
+	if !ok {
+		return io.EOF
+	}
+	return json.Unmarshal([]byte(message), value)
+}
+
+func (s *jsonSocket) WriteJSON(value interface{}) error {
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	s.out <- string(bytes)
+	return nil
+}
+
+func (s *jsonSocket) Close() error {
+	return nil
+}
+
+func TestConnSubscription(t *testing.T) {
+	socket := &jsonSocket{in: make(chan string, 1), out: make(chan string, 10)}
+	conn := graphql.CreateConnection(context.Background(), socket, makeSubscriptionSchema())
+	go conn.ServeJSONSocket()
+	defer close(socket.in)
+
+	socket.in <- `{"id": "1", "type": "subscribe", "message": {"query": "subscription { alerts(prefix: \"x\") { message } }"}}`
+
+	for _, expected := range []string{
+		`{"id": "1", "type": "update", "message": [{"alerts": {"message": "xa"}}]}`,
+		`{"id": "1", "type": "update", "message": [{"alerts": {"message": "xb"}}]}`,
+		`{"id": "1", "type": "complete"}`,
+	} {
+		if d := pretty.Compare(internal.ParseJSON(<-socket.out), internal.ParseJSON(expected)); d != "" {
+			t.Errorf("expected did not match message: %s", d)
+		}
+	}
+}
+
+func TestConnPersistedQuery(t *testing.T) {
+	socket := &jsonSocket{in: make(chan string, 1), out: make(chan string, 10)}
+	conn := graphql.CreateConnection(context.Background(), socket, makeSubscriptionSchema(), graphql.WithPersistedQueryStore(graphql.NewMemoryPersistedQueryStore()))
+	go conn.ServeJSONSocket()
+	defer close(socket.in)
+
+	// sha256 of "{ version }".
+	extensions := `{"persistedQuery": {"version": 1, "sha256Hash": "1dee97279832c351624025387be36873845c282288b1f0a51ccf63e6b5f7549f"}}`
+
+	for _, tc := range []struct {
+		message  string
+		expected string
+	}{
+		{
+			`{"id": "1", "type": "subscribe", "message": {}, "extensions": ` + extensions + `}`,
+			`{"id": "1", "type": "error", "message": "PersistedQueryNotFound", "errors": [{"message": "PersistedQueryNotFound", "extensions": {"code": "PERSISTED_QUERY_NOT_FOUND"}}]}`,
+		},
+		{
+			`{"id": "2", "type": "subscribe", "message": {"query": "{ version }"}, "extensions": ` + extensions + `}`,
+			`{"id": "2", "type": "update", "message": [{"version": "1"}]}`,
+		},
+		{
+			`{"id": "3", "type": "subscribe", "message": {}, "extensions": ` + extensions + `}`,
+			`{"id": "3", "type": "update", "message": [{"version": "1"}]}`,
+		},
+	} {
+		socket.in <- tc.message
+		if d := pretty.Compare(internal.ParseJSON(<-socket.out), internal.ParseJSON(tc.expected)); d != "" {
+			t.Errorf("expected did not match message: %s", d)
+		}
+	}
+}
+
+func TestConnErrors(t *testing.T) {
+	socket := &jsonSocket{in: make(chan string, 1), out: make(chan string, 10)}
+	conn := graphql.CreateConnection(context.Background(), socket, makeSubscriptionSchema())
+	go conn.ServeJSONSocket()
+	defer close(socket.in)
+
+	for _, tc := range []struct {
+		message  string
+		expected string
+	}{
+		{
+			`{"id": "1", "type": "subscribe", "message": {"query": "subscription { forbidden { message } }"}}`,
+			`{"id": "1", "type": "error", "message": "forbidden", "errors": [{"message": "forbidden", "extensions": {"code": "FORBIDDEN"}}]}`,
+		},
+		{
+			`{"id": "2", "type": "subscribe", "message": {"query": "subscription { failing { message } }"}}`,
+			`{"id": "2", "type": "error", "message": "Internal server error", "errors": [{"message": "Internal server error"}]}`,
+		},
+		{
+			`{"id": "3", "type": "subscribe", "message": {"query": "{ missing }"}}`,
+			`{"id": "3", "type": "error", "message": "unknown field \"missing\"", "errors": [{"message": "unknown field \"missing\""}]}`,
+		},
+		{
+			`{"id": "4", "type": "unknown"}`,
+			`{"id": "4", "type": "error", "message": "unknown message type", "errors": [{"message": "unknown message type"}]}`,
+		},
+	} {
+		socket.in <- tc.message
+		if d := pretty.Compare(internal.ParseJSON(<-socket.out), internal.ParseJSON(tc.expected)); d != "" {
+			t.Errorf("expected did not match message: %s", d)
+		}
+	}
+}
+
+func TestConnPartialResultUpdates(t *testing.T) {
+	var mu sync.Mutex
+	var value int64
+	resource := reactive.NewResource()
+
+	schema := schemabuilder.NewSchema()
+	schema.Query().FieldFunc("counter", func(ctx context.Context) int64 {
+		reactive.AddDependency(ctx, resource, nil)
+		mu.Lock()
+		defer mu.Unlock()
+		return value
+	})
+	schema.Query().FieldFunc("failing", func() (*int64, error) {
+		return nil, errors.New("failing")
+	})
+
+	socket := &jsonSocket{in: make(chan string, 1), out: make(chan string, 10)}
+	conn := graphql.CreateConnection(context.Background(), socket, schema.MustBuild(),
+		graphql.WithExecutor(graphql.NewExecutor(graphql.NewImmediateGoroutineScheduler(), graphql.WithPartialResults())),
+		graphql.WithMinRerunInterval(time.Millisecond))
+	go conn.ServeJSONSocket()
+	defer close(socket.in)
+
+	errs := `"errors": [{"message": "Internal server error", "path": ["failing"], "locations": [{"line": 1, "column": 11}]}]`
+	expectMessage := func(expected string) {
+		if d := pretty.Compare(internal.ParseJSON(<-socket.out), internal.ParseJSON(expected)); d != "" {
+			t.Errorf("expected did not match message: %s", d)
+		}
+	}
+
+	socket.in <- `{"id": "1", "type": "subscribe", "message": {"query": "{ counter failing }"}}`
+	expectMessage(`{"id": "1", "type": "update", "message": [{"counter": 0, "failing": null}], ` + errs + `}`)
+
+	// A rerun with the same data and errors sends nothing, so the next
+	// update is the one with the new counter.
+	resource.Strobe()
+	time.Sleep(50 * time.Millisecond)
+	mu.Lock()
+	value = 1
+	mu.Unlock()
+	resource.Strobe()
+	expectMessage(`{"id": "1", "type": "update", "message": {"counter": 1}, ` + errs + `}`)
+}
+
+func TestConnPartialResultRetriesCachedFailures(t *testing.T) {
+	var mu sync.Mutex
+	var value, calls int64
+	resource := reactive.NewResource()
+
+	schema := schemabuilder.NewSchema()
+	schema.Query().FieldFunc("counter", func(ctx context.Context) int64 {
+		reactive.AddDependency(ctx, resource, nil)
+		mu.Lock()
+		defer mu.Unlock()
+		return value
+	})
+	schema.Query().FieldFunc("failing", func() (*int64, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		return nil, errors.New("failing")
+	}, schemabuilder.Expensive)
+
+	socket := &jsonSocket{in: make(chan string, 1), out: make(chan string, 10)}
+	conn := graphql.CreateConnection(context.Background(), socket, schema.MustBuild(),
+		graphql.WithExecutor(graphql.NewExecutor(graphql.NewImmediateGoroutineScheduler(), graphql.WithPartialResults())),
+		graphql.WithMinRerunInterval(time.Millisecond))
+	go conn.ServeJSONSocket()
+	defer close(socket.in)
+
+	errs := `"errors": [{"message": "Internal server error", "path": ["failing"], "locations": [{"line": 1, "column": 11}]}]`
+	expectMessage := func(expected string) {
+		if d := pretty.Compare(internal.ParseJSON(<-socket.out), internal.ParseJSON(expected)); d != "" {
+			t.Errorf("expected did not match message: %s", d)
+		}
+	}
+
+	socket.in <- `{"id": "1", "type": "subscribe", "message": {"query": "{ counter failing }"}}`
+	expectMessage(`{"id": "1", "type": "update", "message": [{"counter": 0, "failing": null}], ` + errs + `}`)
+
+	// The failed field is not cached, so the rerun resolves it again and
+	// still reports its error.
+	mu.Lock()
+	value = 1
+	mu.Unlock()
+	resource.Strobe()
+	expectMessage(`{"id": "1", "type": "update", "message": {"counter": 1}, ` + errs + `}`)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if calls != 2 {
+		t.Errorf("expected failing to be resolved twice, but was resolved %d times", calls)
+	}
+}
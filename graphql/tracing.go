@@ -0,0 +1,262 @@
+package graphql
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FieldTrace describes one run of the resolver or batch resolver of a field.
+type FieldTrace struct {
+	// Paths are the paths in the response of the field for each source the
+	// resolver ran for. A batch resolver runs for many sources at once, any
+	// other resolver for a single one.
+	Paths [][]interface{}
+	// ParentType is the name of the object the field belongs to.
+	ParentType string
+	// FieldName is the name of the field in the schema.
+	FieldName string
+	// ReturnType is the GraphQL type of the field.
+	ReturnType string
+	// Batch is whether the batch resolver of the field ran.
+	Batch bool
+
+	// Start is when the resolver started.
+	Start time.Time
+	// Duration and Err are the duration and error of the resolver, and are
+	// set before Tracer.FinishField is called.
+	Duration time.Duration
+	Err      error
+}
+
+// BatchSize returns the number of sources the resolver ran for.
+func (t *FieldTrace) BatchSize() int {
+	return len(t.Paths)
+}
+
+// A Tracer is notified by the Executor around every resolver and batch
+// resolver it runs. Tracers are called concurrently, and must be safe for
+// concurrent use.
+type Tracer interface {
+	// StartField is called before a resolver runs, and returns the context
+	// the resolver runs with.
+	StartField(ctx context.Context, trace *FieldTrace) context.Context
+	// FinishField is called after the resolver returned, with the context
+	// returned by StartField.
+	FinishField(ctx context.Context, trace *FieldTrace)
+}
+
+// WithTracer makes the Executor notify tracer around every resolver it runs.
+func WithTracer(tracer Tracer) ExecutorOption {
+	return func(e *Executor) {
+		e.tracers = append(e.tracers, tracer)
+	}
+}
+
+type tracersKey struct{}
+
+// ContextWithTracer returns a context that makes the Executor notify tracer
+// around every resolver it runs with that context, in addition to any tracers
+// already in ctx. It lets middlewares trace a single computation.
+func ContextWithTracer(ctx context.Context, tracer Tracer) context.Context {
+	existing := tracersFromContext(ctx)
+	tracers := make([]Tracer, 0, len(existing)+1)
+	tracers = append(tracers, existing...)
+	tracers = append(tracers, tracer)
+	return context.WithValue(ctx, tracersKey{}, tracers)
+}
+
+func tracersFromContext(ctx context.Context) []Tracer {
+	tracers, _ := ctx.Value(tracersKey{}).([]Tracer)
+	return tracers
+}
+
+// newFieldTrace returns the trace of running the resolver of a work unit for
+// the sources of destinations.
+func newFieldTrace(unit *WorkUnit, destinations []*outputNode, batch bool) *FieldTrace {
+	paths := make([][]interface{}, 0, len(destinations))
+	for _, dest := range destinations {
+		paths = append(paths, dest.pathTracker.getResponsePath())
+	}
+	return &FieldTrace{
+		Paths:      paths,
+		ParentType: unit.objectName,
+		FieldName:  unit.selection.Name,
+		ReturnType: unit.field.Type.String(),
+		Batch:      batch,
+	}
+}
+
+// startTrace notifies tracers that the resolver of trace is starting.
+func startTrace(ctx context.Context, tracers []Tracer, trace *FieldTrace) context.Context {
+	trace.Start = time.Now()
+	for _, tracer := range tracers {
+		ctx = tracer.StartField(ctx, trace)
+	}
+	return ctx
+}
+
+// finishTrace notifies tracers that the resolver of trace has returned err.
+func finishTrace(ctx context.Context, tracers []Tracer, trace *FieldTrace, err error) {
+	trace.Duration = time.Since(trace.Start)
+	trace.Err = err
+	for i := len(tracers) - 1; i >= 0; i-- {
+		tracers[i].FinishField(ctx, trace)
+	}
+}
+
+// Span is a span of a span-based tracing system, such as OpenTelemetry.
+type Span interface {
+	SetAttributes(attributes map[string]interface{})
+	RecordError(err error)
+	End()
+}
+
+// StartSpanFunc starts a span named name, as a child of any span in ctx.
+type StartSpanFunc func(ctx context.Context, name string) (context.Context, Span)
+
+// NewSpanTracer returns a Tracer that starts a span named
+// "<ParentType>.<FieldName>" for every resolver run. It adapts span-based
+// tracing systems; for OpenTelemetry, start can wrap a trace.Tracer as
+//
+//	func(ctx context.Context, name string) (context.Context, graphql.Span) {
+//		ctx, span := tracer.Start(ctx, name)
+//		return ctx, otelSpan{span}
+//	}
+//
+// where otelSpan converts the attributes to attribute.KeyValues.
+func NewSpanTracer(start StartSpanFunc) Tracer {
+	return &spanTracer{start: start}
+}
+
+type spanTracer struct {
+	start StartSpanFunc
+}
+
+type spanKey struct{}
+
+func (t *spanTracer) StartField(ctx context.Context, trace *FieldTrace) context.Context {
+	ctx, span := t.start(ctx, trace.ParentType+"."+trace.FieldName)
+
+	attributes := map[string]interface{}{
+		"graphql.field.name":       trace.FieldName,
+		"graphql.field.parentType": trace.ParentType,
+		"graphql.field.type":       trace.ReturnType,
+		"graphql.field.batchSize":  trace.BatchSize(),
+	}
+	if len(trace.Paths) > 0 {
+		attributes["graphql.field.path"] = formatTracePath(trace.Paths[0])
+	}
+	span.SetAttributes(attributes)
+
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+func (t *spanTracer) FinishField(ctx context.Context, trace *FieldTrace) {
+	span, ok := ctx.Value(spanKey{}).(Span)
+	if !ok {
+		return
+	}
+	if trace.Err != nil {
+		span.RecordError(trace.Err)
+	}
+	span.End()
+}
+
+// formatTracePath formats a response path as "a.0.b".
+func formatTracePath(path []interface{}) string {
+	parts := make([]string, 0, len(path))
+	for _, elem := range path {
+		switch elem := elem.(type) {
+		case string:
+			parts = append(parts, elem)
+		case int:
+			parts = append(parts, strconv.Itoa(elem))
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// ApolloTracing is the Apollo tracing extension, as described in
+// https://github.com/apollographql/apollo-tracing.
+type ApolloTracing struct {
+	Version   int                    `json:"version"`
+	StartTime time.Time              `json:"startTime"`
+	EndTime   time.Time              `json:"endTime"`
+	Duration  int64                  `json:"duration"`
+	Execution ApolloTracingExecution `json:"execution"`
+}
+
+// ApolloTracingExecution holds the resolvers run by an execution.
+type ApolloTracingExecution struct {
+	Resolvers []ApolloTracingResolver `json:"resolvers"`
+}
+
+// ApolloTracingResolver is the timing of the resolver of a single field.
+// Offsets and durations are in nanoseconds.
+type ApolloTracingResolver struct {
+	Path        []interface{} `json:"path"`
+	ParentType  string        `json:"parentType"`
+	FieldName   string        `json:"fieldName"`
+	ReturnType  string        `json:"returnType"`
+	StartOffset int64         `json:"startOffset"`
+	Duration    int64         `json:"duration"`
+}
+
+// ApolloTracingMiddleware traces the resolvers of every computation, and
+// returns them in the "tracing" key of ComputationOutput.Metadata and
+// ComputationOutput.Extensions as an *ApolloTracing. A batch resolver is
+// reported once for every field it resolved.
+func ApolloTracingMiddleware(input *ComputationInput, next MiddlewareNextFunc) *ComputationOutput {
+	tracer := &apolloTracer{start: time.Now()}
+	input.Ctx = ContextWithTracer(input.Ctx, tracer)
+
+	output := next(input)
+
+	end := time.Now()
+	tracer.mu.Lock()
+	resolvers := tracer.resolvers
+	tracer.mu.Unlock()
+	if resolvers == nil {
+		resolvers = []ApolloTracingResolver{}
+	}
+	tracing := &ApolloTracing{
+		Version:   1,
+		StartTime: tracer.start,
+		EndTime:   end,
+		Duration:  end.Sub(tracer.start).Nanoseconds(),
+		Execution: ApolloTracingExecution{Resolvers: resolvers},
+	}
+	output.Metadata["tracing"] = tracing
+	output.Extensions["tracing"] = tracing
+	return output
+}
+
+// apolloTracer records resolvers for ApolloTracingMiddleware.
+type apolloTracer struct {
+	start time.Time
+
+	mu        sync.Mutex
+	resolvers []ApolloTracingResolver
+}
+
+func (t *apolloTracer) StartField(ctx context.Context, trace *FieldTrace) context.Context {
+	return ctx
+}
+
+func (t *apolloTracer) FinishField(ctx context.Context, trace *FieldTrace) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, path := range trace.Paths {
+		t.resolvers = append(t.resolvers, ApolloTracingResolver{
+			Path:        path,
+			ParentType:  trace.ParentType,
+			FieldName:   trace.FieldName,
+			ReturnType:  trace.ReturnType,
+			StartOffset: trace.Start.Sub(t.start).Nanoseconds(),
+			Duration:    trace.Duration.Nanoseconds(),
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/samsarahq/thunder/batch"
+	"github.com/samsarahq/thunder/graphql"
+	"github.com/samsarahq/thunder/graphql/schemabuilder"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type tracingItem struct {
+	Id int64
+}
+
+func tracingSchema() *graphql.Schema {
+	schema := schemabuilder.NewSchema()
+
+	query := schema.Query()
+	query.FieldFunc("items", func() []*tracingItem {
+		return []*tracingItem{{Id: 1}, {Id: 2}}
+	})
+	query.FieldFunc("broken", func() (*string, error) {
+		return nil, errors.New("broken")
+	})
+
+	item := schema.Object("item", tracingItem{})
+	item.BatchFieldFunc("name", func(ctx context.Context, items map[batch.Index]*tracingItem) (map[batch.Index]string, error) {
+		names := make(map[batch.Index]string, len(items))
+		for idx := range items {
+			names[idx] = "item"
+		}
+		return names, nil
+	})
+
+	return schema.MustBuild()
+}
+
+type recordingTracer struct {
+	mu     sync.Mutex
+	traces []*graphql.FieldTrace
+}
+
+func (t *recordingTracer) StartField(ctx context.Context, trace *graphql.FieldTrace) context.Context {
+	return ctx
+}
+
+func (t *recordingTracer) FinishField(ctx context.Context, trace *graphql.FieldTrace) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.traces = append(t.traces, trace)
+}
+
+func TestExecutorTracer(t *testing.T) {
+	schema := tracingSchema()
+	q := graphql.MustParse(`{ items { id name } broken }`, nil)
+	require.NoError(t, graphql.PrepareQuery(context.Background(), schema.Query, q.SelectionSet))
+
+	tracer := &recordingTracer{}
+	e := graphql.NewExecutor(graphql.NewImmediateGoroutineScheduler(), graphql.WithPartialResults(), graphql.WithTracer(tracer))
+	_, err := e.Execute(context.Background(), schema.Query, nil, q)
+	require.IsType(t, &graphql.PartialResultError{}, err)
+
+	traces := make(map[string]*graphql.FieldTrace)
+	for _, trace := range tracer.traces {
+		key := trace.ParentType + "." + trace.FieldName
+		if existing, ok := traces[key]; ok {
+			existing.Paths = append(existing.Paths, trace.Paths...)
+			continue
+		}
+		traces[key] = trace
+	}
+	keys := make([]string, 0, len(traces))
+	for key := range traces {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	assert.Equal(t, []string{"Query.broken", "Query.items", "item.id", "item.name"}, keys)
+
+	assert.Equal(t, [][]interface{}{{"items"}}, traces["Query.items"].Paths)
+	assert.Equal(t, "[item]!", traces["Query.items"].ReturnType)
+	assert.NoError(t, traces["Query.items"].Err)
+
+	assert.EqualError(t, traces["Query.broken"].Err, "broken")
+
+	assert.True(t, traces["item.name"].Batch)
+	assert.Equal(t, 2, traces["item.name"].BatchSize())
+	assert.Equal(t, [][]interface{}{{"items", 0, "name"}, {"items", 1, "name"}}, traces["item.name"].Paths)
+
+	assert.False(t, traces["item.id"].Batch)
+	assert.Len(t, traces["item.id"].Paths, 2)
+}
+
+type fakeSpan struct {
+	name       string
+	attributes map[string]interface{}
+	err        error
+	ended      bool
+}
+
+func (s *fakeSpan) SetAttributes(attributes map[string]interface{}) { s.attributes = attributes }
+func (s *fakeSpan) RecordError(err error)                           { s.err = err }
+func (s *fakeSpan) End()                                            { s.ended = true }
+
+func TestSpanTracer(t *testing.T) {
+	schema := tracingSchema()
+	q := graphql.MustParse(`{ items { name } broken }`, nil)
+	require.NoError(t, graphql.PrepareQuery(context.Background(), schema.Query, q.SelectionSet))
+
+	var mu sync.Mutex
+	spans := make(map[string]*fakeSpan)
+	tracer := graphql.NewSpanTracer(func(ctx context.Context, name string) (context.Context, graphql.Span) {
+		mu.Lock()
+		defer mu.Unlock()
+		span := &fakeSpan{name: name}
+		spans[name] = span
+		return ctx, span
+	})
+
+	e := graphql.NewExecutor(graphql.NewImmediateGoroutineScheduler(), graphql.WithPartialResults(), graphql.WithTracer(tracer))
+	_, err := e.Execute(context.Background(), schema.Query, nil, q)
+	require.IsType(t, &graphql.PartialResultError{}, err)
+
+	require.Contains(t, spans, "item.name")
+	assert.True(t, spans["item.name"].ended)
+	assert.Equal(t, map[string]interface{}{
+		"graphql.field.name":       "name",
+		"graphql.field.parentType": "item",
+		"graphql.field.type":       "string",
+		"graphql.field.batchSize":  2,
+		"graphql.field.path":       "items.0.name",
+	}, spans["item.name"].attributes)
+
+	require.Contains(t, spans, "Query.broken")
+	assert.True(t, spans["Query.broken"].ended)
+	assert.EqualError(t, spans["Query.broken"].err, "broken")
+}
+
+func TestApolloTracingMiddleware(t *testing.T) {
+	// Metadata is internal to the middlewares, so it is not part of the
+	// response.
+	private := func(input *graphql.ComputationInput, next graphql.MiddlewareNextFunc) *graphql.ComputationOutput {
+		output := next(input)
+		output.Metadata["private"] = "secret"
+		return output
+	}
+	handler := graphql.NewHTTPHandler(tracingSchema(), graphql.WithHTTPMiddlewares(graphql.ApolloTracingMiddleware, private))
+
+	req, err := http.NewRequest("POST", "/graphql", strings.NewReader(`{"query": "{ items { name } }"}`))
+	require.NoError(t, err)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	var response struct {
+		Data       interface{}
+		Extensions struct {
+			Tracing graphql.ApolloTracing
+		}
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.NotContains(t, rr.Body.String(), "secret")
+
+	tracing := response.Extensions.Tracing
+	assert.Equal(t, 1, tracing.Version)
+	assert.False(t, tracing.StartTime.IsZero())
+	assert.False(t, tracing.EndTime.Before(tracing.StartTime))
+
+	var paths []string
+	for _, resolver := range tracing.Execution.Resolvers {
+		data, err := json.Marshal(resolver.Path)
+		require.NoError(t, err)
+		paths = append(paths, string(data))
+		assert.True(t, resolver.StartOffset >= 0)
+		assert.True(t, resolver.Duration >= 0)
+	}
+	sort.Strings(paths)
+	assert.Equal(t, []string{`["items",0,"name"]`, `["items",1,"name"]`, `["items"]`}, paths)
+}
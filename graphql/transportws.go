@@ -0,0 +1,160 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// This file implements the graphql-transport-ws websocket subprotocol used by
+// off-the-shelf GraphQL clients, as described in
+// https://github.com/enisdenjo/graphql-ws/blob/master/PROTOCOL.md.
+//
+// Operations run exactly as they do over Thunder's own protocol: queries are
+// live and rerun by a reactive.Rerunner, mutations run once, and subscription
+// operations run for every event. Instead of diffs, every new result is sent
+// in full as a "next" message.
+
+// GraphQLTransportWSProtocol is the Sec-WebSocket-Protocol name of the
+// graphql-transport-ws subprotocol.
+const GraphQLTransportWSProtocol = "graphql-transport-ws"
+
+// ConnectionInitWaitTimeout is how long a graphql-transport-ws client has to
+// send its connection_init message before the socket is closed.
+const ConnectionInitWaitTimeout = 3 * time.Second
+
+// Close codes of the graphql-transport-ws subprotocol.
+const (
+	transportWSBadRequest            = 4400
+	transportWSUnauthorized          = 4401
+	transportWSInitTimeout           = 4408
+	transportWSSubscriberExists      = 4409
+	transportWSTooManyInitialisation = 4429
+)
+
+type transportWSMessage struct {
+	ID      string      `json:"id,omitempty"`
+	Type    string      `json:"type"`
+	Payload interface{} `json:"payload,omitempty"`
+}
+
+type transportWSSubscribePayload struct {
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Extensions    map[string]interface{} `json:"extensions,omitempty"`
+}
+
+type transportWSResult struct {
+	Data       interface{}            `json:"data"`
+	Errors     []ResponseError        `json:"errors,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// WithProtocol sets the websocket subprotocol spoken by a connection. The
+// empty protocol is Thunder's own protocol, and GraphQLTransportWSProtocol is
+// graphql-transport-ws.
+func WithProtocol(protocol string) ConnectionOption {
+	return func(c *conn) {
+		c.protocol = protocol
+	}
+}
+
+func transportWSNext(id string, current interface{}, errs []ResponseError, metadata map[string]interface{}) transportWSMessage {
+	return transportWSMessage{
+		ID:   id,
+		Type: "next",
+		Payload: transportWSResult{
+			Data:       current,
+			Errors:     errs,
+			Extensions: metadata,
+		},
+	}
+}
+
+// handleTransportWS handles a graphql-transport-ws message.
+func (c *conn) handleTransportWS(e *inEnvelope) error {
+	switch e.Type {
+	case "connection_init":
+		c.mu.Lock()
+		initialized := c.initialized
+		c.initialized = true
+		c.mu.Unlock()
+
+		if initialized {
+			c.closeWithCode(transportWSTooManyInitialisation, "Too many initialisation requests")
+			return nil
+		}
+		c.writeOrClose(transportWSMessage{Type: "connection_ack"})
+		return nil
+
+	case "ping":
+		c.writeOrClose(transportWSMessage{Type: "pong"})
+		return nil
+
+	case "pong":
+		return nil
+
+	case "subscribe":
+		c.mu.Lock()
+		initialized := c.initialized
+		_, exists := c.subscriptions[e.ID]
+		c.mu.Unlock()
+
+		if !initialized {
+			c.closeWithCode(transportWSUnauthorized, "Unauthorized")
+			return nil
+		}
+		if e.ID == "" {
+			c.closeWithCode(transportWSBadRequest, "Subscribe message must have an id")
+			return nil
+		}
+		if exists {
+			c.closeWithCode(transportWSSubscriberExists, fmt.Sprintf("Subscriber for %s already exists", e.ID))
+			return nil
+		}
+
+		var payload transportWSSubscribePayload
+		if err := json.Unmarshal(e.Payload, &payload); err != nil {
+			c.closeWithCode(transportWSBadRequest, "Invalid subscribe payload")
+			return nil
+		}
+
+		return c.handleSubscribe(&inEnvelope{
+			ID:         e.ID,
+			Type:       "subscribe",
+			Message:    e.Payload,
+			Extensions: payload.Extensions,
+		})
+
+	case "complete":
+		c.closeSubscription(e.ID)
+		return nil
+
+	default:
+		c.closeWithCode(transportWSBadRequest, fmt.Sprintf("Unknown message type %q", e.Type))
+		return nil
+	}
+}
+
+// closeInitTimeout closes a graphql-transport-ws connection that has not been
+// initialized yet.
+func (c *conn) closeInitTimeout() {
+	c.mu.Lock()
+	initialized := c.initialized
+	c.mu.Unlock()
+
+	if !initialized {
+		c.closeWithCode(transportWSInitTimeout, "Connection initialisation timeout")
+	}
+}
+
+// closeWithCode closes the socket, telling websocket clients why.
+func (c *conn) closeWithCode(code int, reason string) {
+	if socket, ok := c.socket.(*websocket.Conn); ok {
+		socket.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(time.Second))
+	}
+	c.socket.Close()
+}
@@ -0,0 +1,148 @@
+package graphql_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/samsarahq/thunder/graphql"
+	"github.com/samsarahq/thunder/graphql/schemabuilder"
+	"github.com/samsarahq/thunder/internal"
+)
+
+func dialTransportWS(t *testing.T) *websocket.Conn {
+	schema := schemabuilder.NewSchema()
+	schema.Query().FieldFunc("version", func() string { return "1" })
+	schema.Mutation().FieldFunc("double", func(args struct{ Value int64 }) int64 { return args.Value * 2 })
+	schema.Subscription().FieldFunc("countdown", func(ctx context.Context) <-chan int64 {
+		ch := make(chan int64)
+		go func() {
+			defer close(ch)
+			for i := int64(2); i > 0; i-- {
+				select {
+				case ch <- i:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+		return ch
+	})
+
+	server := httptest.NewServer(graphql.Handler(schema.MustBuild()))
+	t.Cleanup(server.Close)
+
+	dialer := websocket.Dialer{Subprotocols: []string{graphql.GraphQLTransportWSProtocol}}
+	socket, _, err := dialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { socket.Close() })
+
+	if socket.Subprotocol() != graphql.GraphQLTransportWSProtocol {
+		t.Fatalf("expected %s subprotocol, received %q", graphql.GraphQLTransportWSProtocol, socket.Subprotocol())
+	}
+	return socket
+}
+
+func expectTransportWSMessages(t *testing.T, socket *websocket.Conn, expected ...string) {
+	for _, message := range expected {
+		var actual interface{}
+		if err := socket.ReadJSON(&actual); err != nil {
+			t.Fatal(err)
+		}
+		if d := pretty.Compare(internal.AsJSON(actual), internal.ParseJSON(message)); d != "" {
+			t.Errorf("expected did not match message: %s", d)
+		}
+	}
+}
+
+func TestTransportWS(t *testing.T) {
+	socket := dialTransportWS(t)
+
+	for _, message := range []string{
+		`{"type": "connection_init"}`,
+		`{"type": "ping"}`,
+	} {
+		if err := socket.WriteJSON(internal.ParseJSON(message)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expectTransportWSMessages(t, socket,
+		`{"type": "connection_ack"}`,
+		`{"type": "pong"}`,
+	)
+
+	for _, tc := range []struct {
+		message  string
+		expected []string
+	}{
+		{
+			`{"id": "1", "type": "subscribe", "payload": {"query": "{ version }"}}`,
+			[]string{`{"id": "1", "type": "next", "payload": {"data": {"version": "1"}}}`},
+		},
+		{
+			`{"id": "2", "type": "subscribe", "payload": {"query": "mutation M($value: int64!) { double(value: $value) }", "variables": {"value": 2}}}`,
+			[]string{
+				`{"id": "2", "type": "next", "payload": {"data": {"double": 4}}}`,
+				`{"id": "2", "type": "complete"}`,
+			},
+		},
+		{
+			`{"id": "3", "type": "subscribe", "payload": {"query": "subscription { countdown }"}}`,
+			[]string{
+				`{"id": "3", "type": "next", "payload": {"data": {"countdown": 2}}}`,
+				`{"id": "3", "type": "next", "payload": {"data": {"countdown": 1}}}`,
+				`{"id": "3", "type": "complete"}`,
+			},
+		},
+		{
+			`{"id": "4", "type": "subscribe", "payload": {"query": "{ unknown }"}}`,
+			[]string{`{"id": "4", "type": "error", "payload": [{"message": "unknown field \"unknown\""}]}`},
+		},
+	} {
+		if err := socket.WriteJSON(internal.ParseJSON(tc.message)); err != nil {
+			t.Fatal(err)
+		}
+		expectTransportWSMessages(t, socket, tc.expected...)
+	}
+
+	// The live query stays subscribed until the client completes it.
+	if err := socket.WriteJSON(internal.ParseJSON(`{"id": "1", "type": "complete"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := socket.WriteJSON(internal.ParseJSON(`{"id": "1", "type": "subscribe", "payload": {"query": "{ version }"}}`)); err != nil {
+		t.Fatal(err)
+	}
+	expectTransportWSMessages(t, socket, `{"id": "1", "type": "next", "payload": {"data": {"version": "1"}}}`)
+}
+
+func TestTransportWSCloseCodes(t *testing.T) {
+	for _, tc := range []struct {
+		messages []string
+		code     int
+	}{
+		{[]string{`{"id": "1", "type": "subscribe", "payload": {"query": "{ version }"}}`}, 4401},
+		{[]string{`{"type": "connection_init"}`, `{"type": "connection_init"}`}, 4429},
+		{[]string{`{"type": "connection_init"}`, `{"type": "unknown"}`}, 4400},
+	} {
+		socket := dialTransportWS(t)
+		for _, message := range tc.messages {
+			if err := socket.WriteJSON(internal.ParseJSON(message)); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		var err error
+		for err == nil {
+			var message interface{}
+			err = socket.ReadJSON(&message)
+		}
+		if !websocket.IsCloseError(err, tc.code) {
+			t.Errorf("%v: expected close code %d, received %v", tc.messages, tc.code, err)
+		}
+	}
+}
@@ -6,7 +6,7 @@ import (
 )
 
 // Type represents a GraphQL type, and should be either an Object, a Scalar,
-// or a List
+// a List, or one of the other types declared in this file
 type Type interface {
 	String() string
 
@@ -20,6 +20,11 @@ type Type interface {
 type Scalar struct {
 	Type      string
 	Unwrapper func(interface{}) (interface{}, error)
+
+	Description string
+	// SpecifiedByURL links to the specification of the format of a custom
+	// scalar, if any.
+	SpecifiedByURL string
 }
 
 func (s *Scalar) isType() {}
@@ -33,6 +38,12 @@ type Enum struct {
 	Type       string
 	Values     []string
 	ReverseMap map[interface{}]string
+
+	// ValueDescriptions maps values to their description.
+	ValueDescriptions map[string]string
+	// DeprecatedValues maps deprecated values to the reason they are
+	// deprecated.
+	DeprecatedValues map[string]string
 }
 
 func (e *Enum) isType() {}
@@ -51,6 +62,15 @@ type Object struct {
 	Description string
 	KeyField    *Field
 	Fields      map[string]*Field
+
+	// Interfaces are the interfaces implemented by this object.
+	Interfaces map[string]*Interface
+
+	// Transaction, if set on the Mutation object, wraps the execution of a
+	// mutation. It must call execute, which executes the fields of the
+	// mutation with ctx and returns the first error of a field, and return
+	// an error if execute does or if the mutation could not be committed.
+	Transaction func(ctx context.Context, execute func(ctx context.Context) error) error
 }
 
 func (o *Object) isType() {}
@@ -73,6 +93,13 @@ func (l *List) String() string {
 type InputObject struct {
 	Name        string
 	InputFields map[string]Type
+
+	// InputFieldDescriptions maps input fields to their description.
+	InputFieldDescriptions map[string]string
+
+	// OneOf marks a one-of input object, of which exactly one field must be
+	// set to a non-null value.
+	OneOf bool
 }
 
 func (io *InputObject) isType() {}
@@ -105,7 +132,25 @@ func (u *Union) String() string {
 	return u.Name
 }
 
-// Verify *Scalar, *Object, *List, *InputObject, and *NonNull implement Type
+// Interface is an abstract type declaring a set of fields that every one of
+// its possible types implements.  ResolveType is called with a value of the
+// interface type and returns the name of the concrete Object it represents.
+type Interface struct {
+	Name        string
+	Description string
+	Fields      map[string]*Field
+	Types       map[string]*Object
+	ResolveType func(value interface{}) (string, error)
+}
+
+func (*Interface) isType() {}
+
+func (i *Interface) String() string {
+	return i.Name
+}
+
+// Verify *Scalar, *Object, *List, *InputObject, *NonNull, *Enum, *Union and
+// *Interface implement Type
 var _ Type = &Scalar{}
 var _ Type = &Object{}
 var _ Type = &List{}
@@ -113,6 +158,7 @@ var _ Type = &InputObject{}
 var _ Type = &NonNull{}
 var _ Type = &Enum{}
 var _ Type = &Union{}
+var _ Type = &Interface{}
 
 // A Resolver calculates the value of a field of an object
 type Resolver func(ctx context.Context, source, args interface{}, selectionSet *SelectionSet) (interface{}, error)
@@ -129,12 +175,29 @@ type Field struct {
 	Type           Type
 	Args           map[string]Type
 	ParseArguments func(json interface{}) (interface{}, error)
+	// ArgDescriptions maps arguments to their description.
+	ArgDescriptions map[string]string
 
 	UseBatchFunc func(context.Context) bool
 	Batch        bool
 	External     bool
 	Expensive    bool
 
+	// Cost is the cost of resolving the field, used by ComplexityLimits. A
+	// field with a zero Cost counts as 1.
+	Cost int
+
+	// Description documents the field in introspection.
+	Description string
+	// IsDeprecated marks the field as deprecated in introspection, with an
+	// optional DeprecationReason.
+	IsDeprecated      bool
+	DeprecationReason string
+
+	// CacheHint is how long the field's value may be cached, used to compute
+	// the Cache-Control header of HTTP GET responses. See ComputeCacheHint.
+	CacheHint *CacheHint
+
 	// NumParallelInvocationsFunc controls how many goroutines we'll create for a
 	// field execution (batch or non-expensive).  We pass in the number of srcs
 	// we're executing with so implementers can write custom logic.
@@ -142,11 +205,32 @@ type Field struct {
 
 	// FederatedKey tells us which services need this field as federated key.
 	FederatedKey map[string]bool
+
+	// Subscribe starts the stream of events for a field on the subscription
+	// root. Each event is then executed as the source of the subscription
+	// root, and Resolve returns it as the field's value.
+	Subscribe func(ctx context.Context, args interface{}, selectionSet *SelectionSet) (EventStream, error)
+
+	// Directives are the custom directives applied to the field in the
+	// schema.
+	Directives []*Directive
 }
 
 type Schema struct {
 	Query    Type
 	Mutation Type
+
+	// Subscription is the subscription root, or nil if the schema does not
+	// support subscriptions.
+	Subscription Type
+
+	// Directives are the custom directives of the schema by name.
+	Directives map[string]*DirectiveDefinition
+
+	// StrictVariables makes the handlers check the declared types of
+	// variables, as with WithStrictVariables. It is set by
+	// schemabuilder.Schema.StrictVariables.
+	StrictVariables bool
 }
 
 // SelectionSet represents a core GraphQL query
@@ -171,6 +255,11 @@ type Schema struct {
 type SelectionSet struct {
 	Selections []*Selection
 	Fragments  []*Fragment
+
+	// possibleTypes holds, for a selection set on an Interface, the
+	// selections that apply to each of the interface's possible types. It is
+	// computed by PrepareQuery.
+	possibleTypes map[string]*SelectionSet
 }
 
 // ShallowCopy returns a shallow copy of SelectionSet.
@@ -214,13 +303,17 @@ type Selection struct {
 
 	// ParentType is the type that this field hangs off of.
 	ParentType string
+
+	// variables are the declared variables used in UnparsedArgs, which are
+	// validated by PrepareQuery.
+	variables []*variableUsage
 }
 
 // A Fragment represents a reusable part of a GraphQL query
 //
 // The On part of a Fragment represents the type of source object for which
-// this Fragment should be used. That is not currently implemented in this
-// package.
+// this Fragment should be used. It is respected for fragments on Union and
+// Interface types.
 type Fragment struct {
 	On           string
 	SelectionSet *SelectionSet
@@ -238,4 +331,12 @@ type Fragment struct {
 type Directive struct {
 	Name string
 	Args interface{}
+
+	// Definition is the definition of a custom directive. It is set by
+	// PrepareQuery for directives in a query, and by the schema for
+	// directives applied to fields.
+	Definition *DirectiveDefinition
+	// ParsedArgs are the arguments of a custom directive, as parsed by its
+	// definition.
+	ParsedArgs interface{}
 }
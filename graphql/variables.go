@@ -0,0 +1,426 @@
+package graphql
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// This file contains code to validate and coerce query variables. Parse
+// substitutes variables into the arguments of selections and records where
+// each declared variable was used; PrepareQuery then coerces every use to the
+// type of the argument it is passed to, and with WithStrictVariables checks it
+// against the variable's declared type.
+
+// variableUsage is a use of a declared variable inside the arguments of a
+// selection.
+type variableUsage struct {
+	name string
+	typ  ast.Type
+	// hasDefault is set if the variable has a default value.
+	hasDefault bool
+
+	// path is the location of the variable in the selection's arguments,
+	// starting with the argument name. Input object fields are strings and
+	// list positions are ints.
+	path []interface{}
+}
+
+// collectVariableUsages finds all uses of the variables in definitions in a
+// graphql-go argument list.
+func collectVariableUsages(input []*ast.Argument, definitions map[string]*ast.VariableDefinition) []*variableUsage {
+	var usages []*variableUsage
+
+	var visit func(value ast.Value, path []interface{})
+	visit = func(value ast.Value, path []interface{}) {
+		switch value := value.(type) {
+		case *ast.Variable:
+			definition, ok := definitions[value.Name.Value]
+			if !ok {
+				return
+			}
+			usages = append(usages, &variableUsage{
+				name:       value.Name.Value,
+				typ:        definition.Type,
+				hasDefault: definition.DefaultValue != nil,
+				path:       append([]interface{}(nil), path...),
+			})
+		case *ast.ObjectValue:
+			for _, field := range value.Fields {
+				visit(field.Value, append(path, field.Name.Value))
+			}
+		case *ast.ListValue:
+			for i, item := range value.Values {
+				visit(item, append(path, i))
+			}
+		}
+	}
+
+	for _, arg := range input {
+		visit(arg.Value, []interface{}{arg.Name.Value})
+	}
+	return usages
+}
+
+// astTypeString formats a graphql-go type reference the same way Type.String
+// formats schema types.
+func astTypeString(typ ast.Type) string {
+	switch typ := typ.(type) {
+	case *ast.NonNull:
+		return astTypeString(typ.Type) + "!"
+	case *ast.List:
+		return "[" + astTypeString(typ.Type) + "]"
+	case *ast.Named:
+		return typ.Name.Value
+	default:
+		return ""
+	}
+}
+
+// coerceVariableUsages validates the variables used in a selection's
+// arguments and writes their coerced values into selection.UnparsedArgs.
+//
+// If strict is set, a variable must also be declared with the type of the
+// argument it is passed to, or with the GraphQL spec's name for its scalar,
+// such as Int for int64, and a nullable variable may only be passed to a
+// non-null argument if it has a default value.
+func coerceVariableUsages(field *Field, selection *Selection, strict bool) error {
+	for _, usage := range selection.variables {
+		location := variableLocationType(field, usage.path)
+		if location == nil {
+			// Unknown arguments are reported when parsing arguments.
+			continue
+		}
+
+		if strict && !variableUsageAllowed(usage, location) {
+			return NewClientError(`variable "$%s" of type "%s" cannot be used for argument "%s" of type "%s"`,
+				usage.name, astTypeString(usage.typ), formatVariablePath(selection.Name, usage.path), location)
+		}
+
+		value, err := coerceVariableValue(usage.name, "$"+usage.name, getArgumentValue(selection.UnparsedArgs, usage.path), location)
+		if err != nil {
+			return err
+		}
+		setArgumentValue(selection.UnparsedArgs, usage.path, value)
+	}
+	return nil
+}
+
+// variableUsageAllowed returns if usage may be passed to a location of type
+// location. A nullable variable with a default value may be used in a
+// non-null location.
+func variableUsageAllowed(usage *variableUsage, location Type) bool {
+	if nonNull, ok := location.(*NonNull); ok && usage.hasDefault {
+		if _, ok := usage.typ.(*ast.NonNull); !ok {
+			return variableTypeCompatible(usage.typ, nonNull.Type)
+		}
+	}
+	return variableTypeCompatible(usage.typ, location)
+}
+
+// variableTypeCompatible returns if a variable declared with typ may be used
+// in a location of type location.
+func variableTypeCompatible(typ ast.Type, location Type) bool {
+	if nonNull, ok := location.(*NonNull); ok {
+		typ, ok := typ.(*ast.NonNull)
+		return ok && variableTypeCompatible(typ.Type, nonNull.Type)
+	}
+	if nonNull, ok := typ.(*ast.NonNull); ok {
+		return variableTypeCompatible(nonNull.Type, location)
+	}
+
+	switch location := location.(type) {
+	case *List:
+		list, ok := typ.(*ast.List)
+		return ok && variableTypeCompatible(list.Type, location.Type)
+	default:
+		named, ok := typ.(*ast.Named)
+		if !ok {
+			return false
+		}
+		if named.Name.Value == location.String() {
+			return true
+		}
+		scalar, ok := location.(*Scalar)
+		return ok && specScalarAllows(named.Name.Value, scalar.Type)
+	}
+}
+
+// specScalarAllows returns if a variable declared with the GraphQL spec's
+// scalar name may be used for the Thunder scalar typ.
+func specScalarAllows(name string, typ string) bool {
+	switch name {
+	case "Int":
+		return integerScalars[typ]
+	case "Float":
+		return typ == "float32" || typ == "float64"
+	case "String":
+		return typ == "string"
+	case "Boolean":
+		return typ == "bool"
+	case "ID":
+		return typ == "string" || integerScalars[typ]
+	default:
+		return false
+	}
+}
+
+var integerScalars = map[string]bool{
+	"int":    true,
+	"int8":   true,
+	"int16":  true,
+	"int32":  true,
+	"int64":  true,
+	"uint":   true,
+	"uint8":  true,
+	"uint16": true,
+	"uint32": true,
+	"uint64": true,
+}
+
+// variableLocationType returns the type of the argument (or part of an
+// argument) at path, or nil if there is none.
+func variableLocationType(field *Field, path []interface{}) Type {
+	typ, ok := field.Args[path[0].(string)]
+	if !ok {
+		return nil
+	}
+
+	for _, key := range path[1:] {
+		if nonNull, ok := typ.(*NonNull); ok {
+			typ = nonNull.Type
+		}
+
+		switch current := typ.(type) {
+		case *InputObject:
+			name, ok := key.(string)
+			if !ok {
+				return nil
+			}
+			if typ, ok = current.InputFields[name]; !ok {
+				return nil
+			}
+		case *List:
+			if _, ok := key.(int); !ok {
+				return nil
+			}
+			typ = current.Type
+		default:
+			return nil
+		}
+	}
+	return typ
+}
+
+// coerceVariableValue checks that value is a valid input for typ, and
+// returns the value with single values in list positions wrapped in lists.
+func coerceVariableValue(name string, path string, value interface{}, typ Type) (interface{}, error) {
+	if nonNull, ok := typ.(*NonNull); ok {
+		if value == nil {
+			return nil, NewClientError(`variable "$%s" has invalid value at %s: expected non-null value of type "%s"`, name, path, typ)
+		}
+		return coerceVariableValue(name, path, value, nonNull.Type)
+	}
+
+	if value == nil {
+		return nil, nil
+	}
+
+	switch typ := typ.(type) {
+	case *Scalar:
+		if err := checkScalarValue(typ.Type, value); err != nil {
+			return nil, NewClientError(`variable "$%s" has invalid value at %s: %s`, name, path, err)
+		}
+		return value, nil
+
+	case *Enum:
+		asString, ok := value.(string)
+		if !ok {
+			return nil, NewClientError(`variable "$%s" has invalid value at %s: expected enum value of type "%s"`, name, path, typ.Type)
+		}
+		for _, enumValue := range typ.Values {
+			if enumValue == asString {
+				return value, nil
+			}
+		}
+		return nil, NewClientError(`variable "$%s" has invalid value at %s: unknown enum value "%s" for type "%s"`, name, path, asString, typ.Type)
+
+	case *InputObject:
+		asMap, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, NewClientError(`variable "$%s" has invalid value at %s: expected an object of type "%s"`, name, path, typ.Name)
+		}
+
+		for fieldName := range asMap {
+			if _, ok := typ.InputFields[fieldName]; !ok {
+				return nil, NewClientError(`variable "$%s" has invalid value at %s: unknown field "%s"`, name, path, fieldName)
+			}
+		}
+		if typ.OneOf && !IsOneOfValue(asMap) {
+			return nil, NewClientError(`variable "$%s" has invalid value at %s: exactly one field of one-of type "%s" must be set`, name, path, typ.Name)
+		}
+
+		fieldNames := make([]string, 0, len(typ.InputFields))
+		for fieldName := range typ.InputFields {
+			fieldNames = append(fieldNames, fieldName)
+		}
+		sort.Strings(fieldNames)
+
+		coerced := make(map[string]interface{}, len(asMap))
+		for _, fieldName := range fieldNames {
+			fieldValue, ok := asMap[fieldName]
+			fieldValue, err := coerceVariableValue(name, path+"."+fieldName, fieldValue, typ.InputFields[fieldName])
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				coerced[fieldName] = fieldValue
+			}
+		}
+		return coerced, nil
+
+	case *List:
+		asSlice, ok := value.([]interface{})
+		if !ok {
+			// A single value is coerced into a list containing only that value.
+			item, err := coerceVariableValue(name, path, value, typ.Type)
+			if err != nil {
+				return nil, err
+			}
+			return []interface{}{item}, nil
+		}
+
+		coerced := make([]interface{}, len(asSlice))
+		for i, item := range asSlice {
+			item, err := coerceVariableValue(name, fmt.Sprintf("%s[%d]", path, i), item, typ.Type)
+			if err != nil {
+				return nil, err
+			}
+			coerced[i] = item
+		}
+		return coerced, nil
+
+	default:
+		return value, nil
+	}
+}
+
+// integerScalarBounds are the ranges of the integer scalars.
+var integerScalarBounds = map[string][2]float64{
+	"int":    {math.MinInt64, math.MaxInt64},
+	"int8":   {math.MinInt8, math.MaxInt8},
+	"int16":  {math.MinInt16, math.MaxInt16},
+	"int32":  {math.MinInt32, math.MaxInt32},
+	"int64":  {math.MinInt64, math.MaxInt64},
+	"uint":   {0, math.MaxUint64},
+	"uint8":  {0, math.MaxUint8},
+	"uint16": {0, math.MaxUint16},
+	"uint32": {0, math.MaxUint32},
+	"uint64": {0, math.MaxUint64},
+}
+
+// checkScalarValue checks that value is a valid json input for the scalar
+// named typ. Values of unknown scalars are accepted as is.
+func checkScalarValue(typ string, value interface{}) error {
+	switch typ {
+	case "bool":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("expected a bool")
+		}
+	case "float32", "float64":
+		if _, ok := value.(float64); !ok {
+			return fmt.Errorf("expected a number")
+		}
+	case "string", "Time", "bytes":
+		if _, ok := value.(string); !ok {
+			return fmt.Errorf("expected a string")
+		}
+	default:
+		bounds, ok := integerScalarBounds[typ]
+		if !ok {
+			return nil
+		}
+		asFloat, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("expected a number")
+		}
+		if asFloat != math.Trunc(asFloat) {
+			return fmt.Errorf("expected an integer")
+		}
+		if asFloat < bounds[0] || asFloat > bounds[1] {
+			return fmt.Errorf("%v out of range for %s", asFloat, typ)
+		}
+	}
+	return nil
+}
+
+// formatVariablePath formats a path into a selection's arguments, such as
+// field(filter.ids[1]).
+func formatVariablePath(fieldName string, path []interface{}) string {
+	var b strings.Builder
+	b.WriteString(path[0].(string))
+	for _, key := range path[1:] {
+		switch key := key.(type) {
+		case string:
+			b.WriteString("." + key)
+		case int:
+			fmt.Fprintf(&b, "[%d]", key)
+		}
+	}
+	return fmt.Sprintf("%s(%s)", fieldName, b.String())
+}
+
+// getArgumentValue returns the value at path in args.
+func getArgumentValue(args map[string]interface{}, path []interface{}) interface{} {
+	var current interface{} = args
+	for _, key := range path {
+		switch container := current.(type) {
+		case map[string]interface{}:
+			current = container[key.(string)]
+		case []interface{}:
+			current = container[key.(int)]
+		default:
+			return nil
+		}
+	}
+	return current
+}
+
+// setArgumentValue replaces the value at path in args.
+func setArgumentValue(args map[string]interface{}, path []interface{}, value interface{}) {
+	var current interface{} = args
+	for i, key := range path {
+		last := i == len(path)-1
+		switch container := current.(type) {
+		case map[string]interface{}:
+			if last {
+				container[key.(string)] = value
+				return
+			}
+			current = container[key.(string)]
+		case []interface{}:
+			if last {
+				container[key.(int)] = value
+				return
+			}
+			current = container[key.(int)]
+		default:
+			return
+		}
+	}
+}
+
+// IsOneOfValue returns if exactly one field of a one-of input object value is
+// set, to a non-null value.
+func IsOneOfValue(value map[string]interface{}) bool {
+	if len(value) != 1 {
+		return false
+	}
+	for _, fieldValue := range value {
+		return fieldValue != nil
+	}
+	return false
+}
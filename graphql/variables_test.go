@@ -0,0 +1,227 @@
+package graphql_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/samsarahq/thunder/graphql"
+	"github.com/samsarahq/thunder/graphql/schemabuilder"
+	"github.com/samsarahq/thunder/internal"
+	"github.com/samsarahq/thunder/internal/testgraphql"
+)
+
+type variableColor int
+
+type variableInner struct {
+	Name string
+}
+
+type variableFilter struct {
+	Ids   []int64
+	Color *variableColor
+	Inner *variableInner
+}
+
+type variableLookup struct {
+	schemabuilder.OneOf
+	Id   *int64
+	Name *string
+}
+
+func makeVariableSchema() *graphql.Schema {
+	schema := schemabuilder.NewSchema()
+	schema.Enum(variableColor(0), map[string]variableColor{
+		"red":  0,
+		"blue": 1,
+	})
+
+	query := schema.Query()
+	query.FieldFunc("count", func(args struct{ Filter variableFilter }) int64 {
+		return int64(len(args.Filter.Ids))
+	})
+	query.FieldFunc("sum", func(args struct{ Values []int64 }) int64 {
+		var sum int64
+		for _, value := range args.Values {
+			sum += value
+		}
+		return sum
+	})
+	query.FieldFunc("name", func(args struct{ Name *string }) string {
+		if args.Name == nil {
+			return ""
+		}
+		return *args.Name
+	})
+	query.FieldFunc("lookup", func(args struct{ By variableLookup }) bool {
+		return args.By.Id != nil
+	})
+	return schema.MustBuild()
+}
+
+func TestVariableCoercion(t *testing.T) {
+	builtSchema := makeVariableSchema()
+
+	q, err := graphql.Parse(`
+		query Q($filter: variableFilter_InputObject, $single: [int64], $value: int64, $color: variableColor) {
+			count(filter: $filter)
+			single: sum(values: $single)
+			listed: sum(values: [$value, 2])
+			nested: count(filter: {ids: [1, 2, 3], color: $color})
+		}`, map[string]interface{}{
+		"filter": map[string]interface{}{"ids": []interface{}{float64(1), float64(2)}, "inner": map[string]interface{}{"name": "a"}},
+		"single": float64(5),
+		"value":  float64(5),
+		"color":  "blue",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := graphql.PrepareQuery(context.Background(), builtSchema.Query, q.SelectionSet); err != nil {
+		t.Fatal(err)
+	}
+
+	e := testgraphql.NewExecutorWrapper(t)
+	result, err := e.Execute(context.Background(), builtSchema.Query, nil, q)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if d := pretty.Compare(internal.AsJSON(result), internal.ParseJSON(`
+		{"count": 2, "single": 5, "listed": 7, "nested": 3}`)); d != "" {
+		t.Errorf("expected did not match result: %s", d)
+	}
+}
+
+func TestVariableCoercionErrors(t *testing.T) {
+	builtSchema := makeVariableSchema()
+
+	for _, tc := range []struct {
+		query string
+		vars  map[string]interface{}
+		err   string
+	}{
+		{
+			`query Q($value: int64!) { sum(values: [$value]) }`,
+			nil,
+			`variable "$value" of required type "int64!" was not provided`,
+		},
+		{
+			`query Q($values: [int64]) { sum(values: $values) }`,
+			map[string]interface{}{"values": []interface{}{float64(1), 1.5}},
+			`variable "$values" has invalid value at $values[1]: expected an integer`,
+		},
+		{
+			`query Q($values: [int64]) { sum(values: $values) }`,
+			map[string]interface{}{"values": []interface{}{float64(1), nil}},
+			`variable "$values" has invalid value at $values[1]: expected non-null value of type "int64!"`,
+		},
+		{
+			`query Q($filter: variableFilter_InputObject) { count(filter: $filter) }`,
+			map[string]interface{}{"filter": map[string]interface{}{"ids": []interface{}{}, "inner": map[string]interface{}{"name": float64(1)}}},
+			`variable "$filter" has invalid value at $filter.inner.name: expected a string`,
+		},
+		{
+			`query Q($filter: variableFilter_InputObject) { count(filter: $filter) }`,
+			map[string]interface{}{"filter": map[string]interface{}{"ids": []interface{}{}, "size": float64(1)}},
+			`variable "$filter" has invalid value at $filter: unknown field "size"`,
+		},
+		{
+			`query Q($color: variableColor) { count(filter: {ids: [], color: $color}) }`,
+			map[string]interface{}{"color": "green"},
+			`variable "$color" has invalid value at $color: unknown enum value "green" for type "variableColor"`,
+		},
+		{
+			`query Q($by: variableLookup_InputObject!) { lookup(by: $by) }`,
+			map[string]interface{}{"by": map[string]interface{}{"id": float64(1), "name": "a"}},
+			`variable "$by" has invalid value at $by: exactly one field of one-of type "variableLookup_InputObject" must be set`,
+		},
+	} {
+		q, err := graphql.Parse(tc.query, tc.vars)
+		if err == nil {
+			err = graphql.PrepareQuery(context.Background(), builtSchema.Query, q.SelectionSet)
+		}
+		if err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%s: expected error %q, received %v", tc.query, tc.err, err)
+		}
+		if _, ok := err.(graphql.ClientError); err != nil && !ok {
+			t.Errorf("%s: expected client error, received %T", tc.query, err)
+		}
+	}
+}
+
+func TestStrictVariables(t *testing.T) {
+	builtSchema := makeVariableSchema()
+
+	for _, tc := range []struct {
+		query string
+		vars  map[string]interface{}
+		err   string
+	}{
+		{`query Q($value: int64!) { sum(values: [$value]) }`, map[string]interface{}{"value": float64(1)}, ""},
+		{`query Q($values: [Int!]!) { sum(values: $values) }`, map[string]interface{}{"values": []interface{}{float64(1)}}, ""},
+		{`query Q($name: String) { name(name: $name) }`, nil, ""},
+		{`query Q($name: ID!) { name(name: $name) }`, map[string]interface{}{"name": "a"}, ""},
+		{`query Q($value: int64 = 1) { sum(values: [$value]) }`, nil, ""},
+		{`query Q($filter: variableFilter_InputObject!) { count(filter: $filter) }`, map[string]interface{}{"filter": map[string]interface{}{"ids": []interface{}{}}}, ""},
+		{
+			`query Q($value: string!) { sum(values: [$value]) }`,
+			map[string]interface{}{"value": "1"},
+			`variable "$value" of type "string!" cannot be used for argument "sum(values[0])" of type "int64!"`,
+		},
+		{
+			`query Q($name: int64) { name(name: $name) }`,
+			map[string]interface{}{"name": float64(1)},
+			`variable "$name" of type "int64" cannot be used for argument "name(name)" of type "string"`,
+		},
+		{
+			`query Q($name: Float) { name(name: $name) }`,
+			map[string]interface{}{"name": float64(1)},
+			`variable "$name" of type "Float" cannot be used for argument "name(name)" of type "string"`,
+		},
+		{
+			`query Q($value: int64) { sum(values: [$value]) }`,
+			map[string]interface{}{"value": float64(1)},
+			`variable "$value" of type "int64" cannot be used for argument "sum(values[0])" of type "int64!"`,
+		},
+		{
+			`query Q($values: [int64]!) { sum(values: $values) }`,
+			map[string]interface{}{"values": []interface{}{float64(1)}},
+			`variable "$values" of type "[int64]!" cannot be used for argument "sum(values)" of type "[int64!]!"`,
+		},
+	} {
+		q, err := graphql.Parse(tc.query, tc.vars)
+		if err == nil {
+			err = graphql.PrepareQuery(context.Background(), builtSchema.Query, q.SelectionSet, graphql.WithStrictVariables())
+		}
+		if tc.err == "" {
+			if err != nil {
+				t.Errorf("%s: expected no error, received %v", tc.query, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%s: expected error %q, received %v", tc.query, tc.err, err)
+		}
+		if _, ok := err.(graphql.ClientError); err != nil && !ok {
+			t.Errorf("%s: expected client error, received %T", tc.query, err)
+		}
+	}
+}
+
+func TestSchemaStrictVariables(t *testing.T) {
+	schema := schemabuilder.NewSchema()
+	schema.Query().FieldFunc("name", func(args struct{ Name string }) string { return args.Name })
+	schema.StrictVariables()
+	builtSchema := schema.MustBuild()
+	if !builtSchema.StrictVariables {
+		t.Fatal("expected StrictVariables to be set on the built schema")
+	}
+
+	q := graphql.MustParse(`query Q($name: int64) { name(name: $name) }`, map[string]interface{}{"name": float64(1)})
+	err := graphql.PrepareQuery(context.Background(), builtSchema.Query, q.SelectionSet, builtSchema.PrepareOptions()...)
+	if err == nil || !strings.Contains(err.Error(), `variable "$name" of type "int64" cannot be used`) {
+		t.Errorf("expected strict variable error, received %v", err)
+	}
+}
@@ -2,23 +2,43 @@ package graphql
 
 import (
 	"encoding/json"
+	"strconv"
 	"sync"
 )
 
-// errorRecorder is a concurrency-safe way where we can record the first error
-// we get from executing the graphql query.
+// errorRecorder is a concurrency-safe way where we can record the errors we
+// get from executing the graphql query, in the order they happened.
 type errorRecorder struct {
-	sync.Once
-	err error
+	mu   sync.Mutex
+	errs []error
 }
 
-func (e *errorRecorder) record(err error) {
+// record records err as the error of the failed output node o, and marks the
+// nearest node that can be null in place of o as failed.
+func (e *errorRecorder) record(o *outputNode, err error) {
 	if err == nil {
 		return
 	}
-	e.Do(func() {
-		e.err = err
-	})
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.errs = append(e.errs, err)
+
+	cur := o
+	for !cur.nullable && cur.parent != nil {
+		cur = cur.parent
+	}
+	cur.failed = true
+}
+
+// firstErr returns the first recorded error, or nil if there were none.
+func (e *errorRecorder) firstErr() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if len(e.errs) == 0 {
+		return nil
+	}
+	return e.errs[0]
 }
 
 type pathTracker struct {
@@ -38,22 +58,48 @@ func (p *pathTracker) getPath() []string {
 	return path
 }
 
+// getResponsePath returns the path to the current node in the response, as
+// reported to clients. List indices are ints, and the top-level tracker, which
+// is the operation, is not part of the response.
+func (p *pathTracker) getResponsePath() []interface{} {
+	path := make([]interface{}, 0)
+	for cur := p; cur != nil && cur.parent != nil; cur = cur.parent {
+		if cur.path == "" {
+			continue
+		}
+		if idx, err := strconv.Atoi(cur.path); err == nil {
+			path = append(path, idx)
+		} else {
+			path = append(path, cur.path)
+		}
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
 // newTopLevelOutputNode creates a top-level object writer, this should be
-// the object writer that starts the graphql query.
+// the object writer that starts the graphql query. The top-level node stands
+// for the whole response, which becomes null if a failure propagates up to it.
 func newTopLevelOutputNode(path string) *outputNode {
 	return &outputNode{
 		pathTracker: &pathTracker{path: path},
 		errRecorder: &errorRecorder{},
+		nullable:    true,
 	}
 }
 
 // newOutputNode creates an object writer as a part of a chain of objects.
 // It keeps track of the path and current parent so we can properly propagate
-// error information up the stack.
-func newOutputNode(parent *outputNode, path string) *outputNode {
+// error information up the stack. nullable is whether the node may be null,
+// and so whether failures of the node (or its descendants) stop at it.
+func newOutputNode(parent *outputNode, path string, nullable bool) *outputNode {
 	return &outputNode{
 		pathTracker: &pathTracker{parent: parent.pathTracker, path: path},
 		errRecorder: parent.errRecorder,
+		parent:      parent,
+		nullable:    nullable,
 	}
 }
 
@@ -61,6 +107,13 @@ type outputNode struct {
 	pathTracker *pathTracker
 	res         interface{}
 	errRecorder *errorRecorder
+
+	parent   *outputNode
+	nullable bool
+	// failed is set once the node, or a non-null descendant of it, failed, in
+	// which case the node is null in the response. failed is protected by
+	// errRecorder.mu.
+	failed bool
 }
 
 func (o *outputNode) MarshalJSON() ([]byte, error) {
@@ -74,7 +127,14 @@ func (o *outputNode) Fill(res interface{}) {
 func (o *outputNode) Fail(err error) {
 	path := o.getPath()
 	err = nestPathErrorMulti(path, err)
-	o.errRecorder.record(err)
+	setResponsePath(err, o.pathTracker.getResponsePath())
+	o.errRecorder.record(o, err)
+}
+
+// isNullable returns whether values of typ may be null.
+func isNullable(typ Type) bool {
+	_, ok := typ.(*NonNull)
+	return !ok
 }
 
 // getPath traverses the parent list to get the current execution path.
@@ -98,6 +158,9 @@ func outputNodeToJSON(src interface{}) interface{} {
 		}
 		return newList
 	case *outputNode:
+		if src.failed {
+			return nil
+		}
 		return outputNodeToJSON(src.res)
 	case []interface{}:
 		for idx := range src {
@@ -288,6 +288,29 @@ func Cache(ctx context.Context, key interface{}, f ComputeFunc) (interface{}, er
 	return child.value, nil
 }
 
+// Track runs f as a computation of its own, outside of the computation of
+// ctx, and calls onInvalidate once any dependency that f adds is invalidated.
+// Track lets values computed by f be cached beyond the computation of ctx,
+// such as across requests, and be dropped once they become stale.
+//
+// Track returns a release function, which must be called once the value is
+// no longer used to free the dependencies of f.
+func Track(ctx context.Context, f ComputeFunc, onInvalidate func()) (interface{}, func(), error) {
+	if ctx.Value(cacheKey{}) == nil {
+		ctx = context.WithValue(ctx, cacheKey{}, &cache{
+			computations: make(map[interface{}]*computation),
+			locker:       newLocker(),
+		})
+	}
+
+	c, err := run(ctx, f)
+	if err != nil {
+		return nil, nil, err
+	}
+	c.node.handleInvalidate(onInvalidate)
+	return c.value, func() { go c.node.release() }, nil
+}
+
 // Rerunner automatically reruns a computation whenever its dependencies
 // change.
 //
@@ -409,3 +409,30 @@ func TestRerunImmediately(t *testing.T) {
 	r.Invalidate()
 	run.Expect(t, "expected rerun")
 }
+
+// TestTrack tests that a tracked computation notifies when its dependencies
+// are invalidated, and frees them when released.
+func TestTrack(t *testing.T) {
+	dep := NewResource()
+	released := NewExpect()
+	dep.Cleanup(func() {
+		released.Trigger()
+	})
+
+	invalidated := NewExpect()
+	value, release, err := Track(context.Background(), func(ctx context.Context) (interface{}, error) {
+		AddDependency(ctx, dep, nil)
+		return 1, nil
+	}, func() {
+		invalidated.Trigger()
+	})
+	if err != nil || value != 1 {
+		t.Fatalf("expected 1, received %v, %v", value, err)
+	}
+
+	dep.Strobe()
+	invalidated.Expect(t, "expected invalidation")
+
+	release()
+	released.Expect(t, "expected release")
+}